@@ -0,0 +1,16 @@
+package main
+
+import (
+	"gitee.com/geekbang/basic-go/webook/internal/job"
+	"gitee.com/geekbang/basic-go/webook/internal/service"
+	"gitee.com/geekbang/basic-go/webook/pkg/cronx"
+	"github.com/gin-gonic/gin"
+)
+
+// App 除了 web 服务器，还有一些退出的时候要关闭的组件
+type App struct {
+	server        *gin.Engine
+	readCntBuffer *service.ReadCntBuffer
+	scheduler     *cronx.Scheduler
+	jobScheduler  *job.Scheduler
+}
@@ -1,7 +1,49 @@
 //go:build !k8s
 package config
 
+import "os"
+
 var Config =  config{
 	DB: DBConfig{DSN: "root:root@tcp(localhost:13316)/webook"},
 	Redis: RedisConfig{Addr: "localhost:6379" },
+	Session: SessionConfig{AllowWhenRedisDown: true},
+	JWT: JWTConfig{
+		Access: JWTKeySetConfig{
+			Current: "dev-access-1",
+			Keys: []JWTKeyConfig{
+				{Kid: "dev-access-1", Alg: "HS512", Secret: "jYe8vbdGFD7RRnIf8W7KArU2ehZJbbn8"},
+			},
+		},
+		Refresh: JWTKeySetConfig{
+			Current: "dev-refresh-1",
+			Keys: []JWTKeyConfig{
+				{Kid: "dev-refresh-1", Alg: "HS512", Secret: "Xp2sQ9vLk7TbW4nR8dZf3GhJm6YcA1uE"},
+			},
+		},
+		StateKey: "Qm3ZrT8wKc5NvY2hLp9XsB6dGf1JtA4e",
+	},
+	Email: EmailConfig{Local: true},
+	Verification: VerificationConfig{EmailRequiredPaths: []string{"/articles/publish"}},
+	OAuth2: OAuth2Config{
+		Providers: []OAuth2ProviderConfig{
+			// 本地起一个 Keycloak，realm 叫 webook
+			{
+				Name: "keycloak",
+				ClientID: "webook",
+				ClientSecret: "webook-dev-secret",
+				RedirectURL: "http://localhost:8080/oauth2/keycloak/callback",
+				Scopes: []string{"email"},
+				Issuer: "http://localhost:8180/realms/webook",
+			},
+		},
+	},
+	// 本地调试的时候从环境变量拿测试号的 app id 和 secret，变量名和 k8s 一样
+	Wechat: WechatConfig{
+		AppID: os.Getenv("WEBOOK_WECHAT_APP_ID"),
+		AppSecret: os.Getenv("WEBOOK_WECHAT_APP_SECRET"),
+		RedirectURL: "http://localhost:8080/oauth2/wechat/callback",
+		FrontendURL: "http://localhost:3000/oauth2/wechat/done",
+		TokenKey: "k9YJDqpjRg7rwaCdcXgOFJXDn4x08Ax6ZdXWoVTwPrg=",
+	},
+	TwoFactor: TwoFactorConfig{SecretKey: "Q5UI6PhOlR+h+XXKdxkgvIKX1mSOh4BrAeRbj4w3gVM="},
 }
\ No newline at end of file
@@ -1,7 +1,90 @@
 //go:build k8s
 package config
 
+import "os"
+
 var Config =  config{
 	DB: DBConfig{DSN: "root:root@tcp(webook-mysql:3308)/webook"},
 	Redis: RedisConfig{Addr: "webook-redis:6379" },
-}
\ No newline at end of file
+	Session: SessionConfig{AllowWhenRedisDown: false},
+	// 密钥从 Secret 挂载进来，其他服务从 /.well-known/jwks.json 拿公钥
+	JWT: JWTConfig{
+		Access: JWTKeySetConfig{
+			Current: os.Getenv("WEBOOK_JWT_ACCESS_KID"),
+			Keys: []JWTKeyConfig{
+				{
+					Kid:            os.Getenv("WEBOOK_JWT_ACCESS_KID"),
+					Alg:            "EdDSA",
+					PrivateKeyFile: "/etc/webook/jwt/access.pem",
+				},
+			},
+		},
+		Refresh: JWTKeySetConfig{
+			Current: os.Getenv("WEBOOK_JWT_REFRESH_KID"),
+			Keys: []JWTKeyConfig{
+				{
+					Kid:    os.Getenv("WEBOOK_JWT_REFRESH_KID"),
+					Alg:    "HS512",
+					Secret: os.Getenv("WEBOOK_JWT_REFRESH_SECRET"),
+				},
+			},
+		},
+		StateKey: os.Getenv("WEBOOK_JWT_STATE_KEY"),
+	},
+	Email: EmailConfig{
+		SMTPAddr: os.Getenv("WEBOOK_SMTP_ADDR"),
+		Username: os.Getenv("WEBOOK_SMTP_USERNAME"),
+		Password: os.Getenv("WEBOOK_SMTP_PASSWORD"),
+		From:     os.Getenv("WEBOOK_SMTP_FROM"),
+	},
+	Verification: VerificationConfig{EmailRequiredPaths: []string{"/articles/publish"}},
+	OAuth2: OAuth2Config{
+		Providers: []OAuth2ProviderConfig{
+			{
+				Name:         "github",
+				ClientID:     os.Getenv("WEBOOK_GITHUB_CLIENT_ID"),
+				ClientSecret: os.Getenv("WEBOOK_GITHUB_CLIENT_SECRET"),
+				RedirectURL:  "https://meoying.com/oauth2/github/callback",
+				Scopes:       []string{"read:user"},
+				AuthURL:      "https://github.com/login/oauth/authorize",
+				TokenURL:     "https://github.com/login/oauth/access_token",
+				UserInfoURL:  "https://api.github.com/user",
+				SubjectField: "id",
+			},
+			{
+				Name:         "google",
+				ClientID:     os.Getenv("WEBOOK_GOOGLE_CLIENT_ID"),
+				ClientSecret: os.Getenv("WEBOOK_GOOGLE_CLIENT_SECRET"),
+				RedirectURL:  "https://meoying.com/oauth2/google/callback",
+				Scopes:       []string{"email"},
+				Issuer:       "https://accounts.google.com",
+			},
+			{
+				Name:         "sso",
+				ClientID:     os.Getenv("WEBOOK_SSO_CLIENT_ID"),
+				ClientSecret: os.Getenv("WEBOOK_SSO_CLIENT_SECRET"),
+				RedirectURL:  "https://meoying.com/oauth2/sso/callback",
+				Scopes:       []string{"email"},
+				Issuer:       os.Getenv("WEBOOK_SSO_ISSUER"),
+			},
+		},
+	},
+	Wechat: WechatConfig{
+		AppID:       os.Getenv("WEBOOK_WECHAT_APP_ID"),
+		AppSecret:   os.Getenv("WEBOOK_WECHAT_APP_SECRET"),
+		RedirectURL: getEnv("WEBOOK_WECHAT_REDIRECT_URL", "https://meoying.com/oauth2/wechat/callback"),
+		FrontendURL: getEnv("WEBOOK_WECHAT_FRONTEND_URL", "https://meoying.com/oauth2/wechat/done"),
+		AuthURL:     os.Getenv("WEBOOK_WECHAT_AUTH_URL"),
+		APIBaseURL:  os.Getenv("WEBOOK_WECHAT_API_BASE_URL"),
+		TokenKey:   os.Getenv("WEBOOK_WECHAT_TOKEN_KEY"),
+	},
+	TwoFactor: TwoFactorConfig{SecretKey: os.Getenv("WEBOOK_TWO_FACTOR_SECRET_KEY")},
+}
+
+// getEnv 没有设置环境变量的时候用 def
+func getEnv(key string, def string) string {
+	if val, ok := os.LookupEnv(key); ok {
+		return val
+	}
+	return def
+}
@@ -4,6 +4,13 @@ package config
 type config struct{
 	DB DBConfig
 	Redis RedisConfig
+	Session SessionConfig
+	JWT JWTConfig
+	Email EmailConfig
+	Verification VerificationConfig
+	OAuth2 OAuth2Config
+	Wechat WechatConfig
+	TwoFactor TwoFactorConfig
 }
 
 type DBConfig struct{
@@ -12,4 +19,92 @@ type DBConfig struct{
 
 type RedisConfig struct{
 	Addr string
-}
\ No newline at end of file
+}
+
+type SessionConfig struct{
+	// AllowWhenRedisDown 查不到会话是否退出登录的时候，true 放行，false 拒绝
+	AllowWhenRedisDown bool
+}
+
+type JWTConfig struct{
+	Access JWTKeySetConfig
+	Refresh JWTKeySetConfig
+	// StateKey 微信和 OAuth2 登录的 state 用它签名，至少 32 个字节
+	StateKey string
+}
+
+// JWTKeySetConfig Current 用来签名，Keys 里面的都可以用来验签。
+// 轮换的时候先加新 key，再把 Current 切过去，旧 key 等 token 都过期了再删
+type JWTKeySetConfig struct{
+	Current string
+	Keys []JWTKeyConfig
+}
+
+type JWTKeyConfig struct{
+	Kid string
+	// Alg HS256、HS512、RS256、EdDSA
+	Alg string
+	// Secret HS* 算法用
+	Secret string
+	// PrivateKeyFile RS256、EdDSA 的私钥 PEM 文件，签名用
+	PrivateKeyFile string
+	// PublicKeyFile 只有公钥的 key 只能验签
+	PublicKeyFile string
+}
+
+// EmailConfig Local 为 true 的时候不发邮件，只打日志，里面有验证码，只有开发环境能这么配
+type EmailConfig struct{
+	Local bool
+	SMTPAddr string
+	Username string
+	Password string
+	From string
+}
+
+// VerificationConfig EmailRequiredPaths 里面的接口要求邮箱已经验证过，空的就不限制
+type VerificationConfig struct{
+	EmailRequiredPaths []string
+}
+
+// OAuth2Config 微信以外的第三方登录，ClientID 为空的不启用
+type OAuth2Config struct{
+	Providers []OAuth2ProviderConfig
+}
+
+// OAuth2ProviderConfig Issuer 不为空就是 OIDC，端点从 discovery 拿；
+// 否则要配 AuthURL、TokenURL、UserInfoURL。Name 会出现在路由里面
+type OAuth2ProviderConfig struct{
+	Name string
+	ClientID string
+	ClientSecret string
+	RedirectURL string
+	Scopes []string
+	Issuer string
+	AuthURL string
+	TokenURL string
+	UserInfoURL string
+	// SubjectField 默认 sub，GitHub 是 id
+	SubjectField string
+	EmailField string
+}
+
+type WechatConfig struct{
+	AppID string
+	AppSecret string
+	// RedirectURL 微信回调后端的地址，https://域名/oauth2/wechat/callback
+	RedirectURL string
+	// FrontendURL 回调处理完之后把浏览器重定向到这里，token 放在 # 后面。
+	// 为空就不重定向，直接返回 JSON
+	FrontendURL string
+	// AuthURL 为空就是 https://open.weixin.qq.com/connect/qrconnect
+	AuthURL string
+	// APIBaseURL 为空就是 https://api.weixin.qq.com，测试的时候指向假的服务
+	APIBaseURL string
+	// TokenKey base64 编码的 32 字节 AES key，加密存到数据库里面的微信 token
+	TokenKey string
+}
+
+type TwoFactorConfig struct{
+	// SecretKey base64 编码的 32 字节 AES key，加密存到数据库里面的 TOTP 密钥
+	SecretKey string
+}
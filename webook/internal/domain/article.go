@@ -0,0 +1,40 @@
+package domain
+
+import "time"
+
+type Article struct {
+	Id      int64
+	Title   string
+	Content string
+	Author  Author
+	Status  ArticleStatus
+	Ctime   time.Time
+	Utime   time.Time
+}
+
+type Author struct {
+	Id   int64
+	Name string
+}
+
+type ArticleStatus uint8
+
+const (
+	ArticleStatusUnknown ArticleStatus = iota
+	ArticleStatusUnpublished
+	ArticleStatusPublished
+	ArticleStatusPrivate
+)
+
+func (s ArticleStatus) ToUint8() uint8 {
+	return uint8(s)
+}
+
+// Abstract 列表页展示的摘要，取内容的前 128 个字符
+func (a Article) Abstract() string {
+	cs := []rune(a.Content)
+	if len(cs) <= 128 {
+		return a.Content
+	}
+	return string(cs[:128])
+}
@@ -1,5 +1,6 @@
 package domain
 
+import "time"
 
 type AsyncSms struct{
 	Id int64
@@ -7,4 +8,40 @@ type AsyncSms struct{
 	Args []string
 	Numbers []string
 	RetryMax int
-}
\ No newline at end of file
+
+	// 下面的字段只有管理后台查询的时候会填
+	RetryCnt int
+	Status   AsyncSmsStatus
+	Ctime    time.Time
+	Utime    time.Time
+}
+
+// AsyncSmsStatus 和 dao 里面存的值一致
+type AsyncSmsStatus uint8
+
+const (
+	AsyncSmsStatusWaiting AsyncSmsStatus = iota
+	AsyncSmsStatusFailed
+	AsyncSmsStatusSuccess
+)
+
+func (s AsyncSmsStatus) ToUint8() uint8 {
+	return uint8(s)
+}
+
+func (s AsyncSmsStatus) Valid() bool {
+	return s <= AsyncSmsStatusSuccess
+}
+
+func (s AsyncSmsStatus) String() string {
+	switch s {
+	case AsyncSmsStatusWaiting:
+		return "waiting"
+	case AsyncSmsStatusFailed:
+		return "failed"
+	case AsyncSmsStatusSuccess:
+		return "success"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,10 @@
+package domain
+
+// CodeChannel 验证码发到哪里，值会拼到缓存的 key 里面，
+// 所以同一个地址在不同通道上的发送频率是分开限制的
+type CodeChannel string
+
+const (
+	CodeChannelPhone CodeChannel = "phone"
+	CodeChannelEmail CodeChannel = "email"
+)
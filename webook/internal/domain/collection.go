@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// Collection 用户的收藏夹
+type Collection struct {
+	Id    int64
+	Uid   int64
+	Name  string
+	Ctime time.Time
+	Utime time.Time
+}
+
+// CollectionItem 收藏夹里面的一条收藏，Cid 为 0 是默认收藏夹
+type CollectionItem struct {
+	Biz   string
+	BizId int64
+	Cid   int64
+	Ctime time.Time
+}
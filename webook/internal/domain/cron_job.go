@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// CronJob 存在数据库里面的定时任务，各个节点抢占之后执行
+type CronJob struct {
+	Id   int64
+	Name string
+	// Executor 哪个执行器来执行
+	Executor string
+	// Cfg 执行器自己解析的配置
+	Cfg string
+	// Expression 秒级的 cron 表达式
+	Expression string
+	NextTime   time.Time
+	// Version 抢占的时候带上的版本号，续约和释放都要用它确认任务还在自己手上
+	Version int64
+
+	// CancelFunc 停止续约并且释放任务
+	CancelFunc func()
+}
+
+var cronParser = cron.NewParser(cron.Second | cron.Minute | cron.Hour |
+	cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// Next 根据 cron 表达式算出 t 之后的下一次执行时间，表达式不对返回零值
+func (j CronJob) Next(t time.Time) time.Time {
+	s, err := cronParser.Parse(j.Expression)
+	if err != nil {
+		return time.Time{}
+	}
+	return s.Next(t)
+}
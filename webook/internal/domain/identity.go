@@ -0,0 +1,16 @@
+package domain
+
+// IdentityProviderWechat 微信也存成一种第三方身份
+const IdentityProviderWechat = "wechat"
+
+// Identity 第三方登录的身份，一个用户每个平台最多绑一个
+type Identity struct {
+	// Provider 配置里面的平台名字，比如 github、google、wechat
+	Provider string
+	// Subject 平台上的用户 ID，OIDC 里面的 sub，微信的 openid
+	Subject string
+	// UnionId 只有微信有
+	UnionId string
+	// Email 平台确认过的邮箱，没有就是空
+	Email string
+}
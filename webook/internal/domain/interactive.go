@@ -0,0 +1,22 @@
+package domain
+
+// Interactive 某个资源的阅读、点赞和收藏情况
+type Interactive struct {
+	Biz   string
+	BizId int64
+
+	ReadCnt    int64
+	LikeCnt    int64
+	CollectCnt int64
+
+	// 当前用户是否点赞、收藏
+	Liked     bool
+	Collected bool
+}
+
+// ReadCntDelta 一段时间内某个资源累计增加的阅读数
+type ReadCntDelta struct {
+	Biz   string
+	BizId int64
+	Delta int64
+}
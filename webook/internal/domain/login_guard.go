@@ -0,0 +1,13 @@
+package domain
+
+import "time"
+
+// LoginGuardPolicy 一个限制对象（账号或者 IP）的防爆破策略：
+// 前 Free 次失败不限制，之后每次失败延迟翻倍，到 LockAt 次锁定
+type LoginGuardPolicy struct {
+	Free      int64
+	LockAt    int64
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	Lockout   time.Duration
+}
@@ -0,0 +1,43 @@
+package domain
+
+// Role 角色，权限挂在角色上面，用户通过角色拿到权限
+type Role string
+
+const (
+	RoleAdmin Role = "admin"
+)
+
+// Permission 权限，格式是 资源:动作
+type Permission string
+
+const (
+	PermissionUserRead   Permission = "user:read"
+	PermissionUserBan    Permission = "user:ban"
+	PermissionRoleManage Permission = "role:manage"
+	PermissionSMSRead    Permission = "sms:read"
+)
+
+// DefaultRolePermissions 建表的时候写进 role_permissions，之后以数据库为准
+var DefaultRolePermissions = map[Role][]Permission{
+	RoleAdmin: {
+		PermissionUserRead,
+		PermissionUserBan,
+		PermissionRoleManage,
+		PermissionSMSRead,
+	},
+}
+
+// Authz 用户的角色和权限。检查权限的时候现查，token 里面放一份给前端展示
+type Authz struct {
+	Roles       []Role
+	Permissions []Permission
+}
+
+func (a Authz) Has(p Permission) bool {
+	for _, perm := range a.Permissions {
+		if perm == p {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+// Session 一次登录就是一个会话，对应一对 access token 和 refresh token
+type Session struct {
+	Ssid      string
+	Uid       int64
+	UserAgent string
+	IP        string
+	// Method 登录方式
+	Method SessionMethod
+	Ctime  time.Time
+}
+
+type SessionMethod string
+
+const (
+	SessionMethodPassword SessionMethod = "password"
+	SessionMethodSMS      SessionMethod = "sms"
+	SessionMethodWechat   SessionMethod = "wechat"
+	SessionMethodOAuth2   SessionMethod = "oauth2"
+)
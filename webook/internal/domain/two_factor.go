@@ -0,0 +1,11 @@
+package domain
+
+// TwoFactor 用户的 TOTP 配置。Enabled 为 false 的时候是绑定到一半，
+// 还没有用第一个 code 确认过
+type TwoFactor struct {
+	Uid     int64
+	Secret  string
+	Enabled bool
+	// LastStep 最后一次用过的时间片，防止同一个 code 被重放
+	LastStep int64
+}
@@ -5,15 +5,38 @@ import "time"
 type User struct {
 	Id       int64
 	Email    string
+	// EmailVerified 邮箱注册之后要验证，改邮箱的时候验证码已经证明了新邮箱
+	EmailVerified bool
 	Password string
 
 	Nickname string
 	// YYYY-MM-DD
 	Birthday time.Time
 	AboutMe  string
+	// Avatar 头像地址，微信第一次登录的时候用微信头像
+	Avatar string
 
 	Phone string
 
+	// WechatInfo 从 Identities 里面拿出来的，方便用
 	WechatInfo 
+	// Identities 绑定的第三方身份，只有 FindById 会填
+	Identities []Identity
+
+	// BannedAt 被管理员封禁的时间，没有封禁是零值
+	BannedAt time.Time
+	Ctime    time.Time
+}
+
+func (u User) Banned() bool {
+	return !u.BannedAt.IsZero()
+}
+
+// UserQuery 管理后台搜索用户，Keyword 为空就是按 id 倒序列出全部
+type UserQuery struct {
+	// Keyword 匹配 id，或者邮箱、手机号、昵称的前缀
+	Keyword string
+	Offset  int
+	Limit   int
 }
 
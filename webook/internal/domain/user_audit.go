@@ -0,0 +1,34 @@
+package domain
+
+// UserAuditAction 敏感信息变更的类型
+type UserAuditAction string
+
+const (
+	UserAuditChangePassword UserAuditAction = "change_password"
+	UserAuditResetPassword  UserAuditAction = "reset_password"
+	UserAuditChangeEmail    UserAuditAction = "change_email"
+	UserAuditChangePhone    UserAuditAction = "change_phone"
+	UserAuditVerifyEmail    UserAuditAction = "verify_email"
+	UserAuditUnbindEmail    UserAuditAction = "unbind_email"
+	UserAuditUnbindPhone    UserAuditAction = "unbind_phone"
+	UserAuditBindWechat     UserAuditAction = "bind_wechat"
+	UserAuditUnbindWechat   UserAuditAction = "unbind_wechat"
+	// 其他第三方平台的绑定，Before 和 After 是 provider:subject
+	UserAuditBindIdentity   UserAuditAction = "bind_identity"
+	UserAuditUnbindIdentity UserAuditAction = "unbind_identity"
+	// 管理员的操作，Operator 是管理员
+	UserAuditBan        UserAuditAction = "ban"
+	UserAuditUnban      UserAuditAction = "unban"
+	UserAuditGrantRole  UserAuditAction = "grant_role"
+	UserAuditRevokeRole UserAuditAction = "revoke_role"
+)
+
+// UserAudit 一次敏感信息变更的记录，密码不记录前后值
+type UserAudit struct {
+	Uid    int64
+	Action UserAuditAction
+	Before string
+	After  string
+	// Operator 操作的人，用户自己操作的时候是 0
+	Operator int64
+}
@@ -1,6 +1,26 @@
 package domain
 
+import "time"
+
 type WechatInfo struct{
 	UnionId string
 	OpenId string
-}
\ No newline at end of file
+}
+
+// WechatToken 微信授权拿到的 token，access token 两个小时过期，refresh token 三十天
+type WechatToken struct {
+	Uid          int64
+	OpenId       string
+	AccessToken  string
+	RefreshToken string
+	// AccessExpireAt 快到了就用 refresh token 换新的
+	AccessExpireAt time.Time
+	// RefreshExpireAt 刷新不会延长，过期之后只能让用户重新授权
+	RefreshExpireAt time.Time
+}
+
+// WechatProfile /sns/userinfo 拿到的资料，第一次登录的时候用来初始化用户
+type WechatProfile struct {
+	Nickname string
+	Avatar   string
+}
@@ -22,31 +22,77 @@ func InitWebServer() *gin.Engine {
 		//third party
 		ioc.InitDB,
 		InitRedis,
+		ioc.InitLogger,
 		//ioc.InitBigCache,
 
 		//dao
 		dao.NewUserDao,
+		dao.NewArticleDAO,
+		dao.NewInteractiveDAO,
+		dao.NewCollectionDAO,
+		dao.NewTwoFactorDAO,
+		dao.NewIdentityDAO,
+		dao.NewWechatTokenDAO,
+		dao.NewRoleDAO,
+		dao.NewGORMAsyncSmsDAO,
 
 		//cache
 		cache.NewRedisCodeCache, 
 		//cache.NewBigCacheCodeCache,
 		cache.NewRedisUserCache,
+		cache.NewRedisArticleCache,
+		cache.NewRedisInteractiveCache,
+		cache.NewRedisRankingCache,
+		cache.NewRedisSessionCache,
+		cache.NewRedisTwoFactorCache,
+		cache.NewRedisLoginAttemptCache,
+		cache.NewRankingLocalCache,
 		
 
 		//repository
 		repository.NewCodeRepository,
 		repository.NewUserRepository,
+		ioc.InitWechatTokenRepository,
+		repository.NewArticleRepository,
+		repository.NewInteractiveRepository,
+		repository.NewCollectionRepository,
+		repository.NewRankingRepository,
+		repository.NewSessionRepository,
+		ioc.InitTwoFactorRepository,
+		repository.NewRoleRepository,
+		repository.NewAsyncSMSRepository,
+		repository.NewLoginAttemptRepository,
 
 		//service
 		ioc.InitSMSService,
+		ioc.InitEmailService,
 		ioc.InitWechatService,
 		service.NewUserService,
-		service.NewCodeService,
+		service.NewWechatAccountService,
+		ioc.InitCodeService,
+		service.NewArticleService,
+		ioc.InitReadCntBuffer,
+		service.NewInteractiveService,
+		service.NewCollectionService,
+		service.NewBatchRankingService,
+		service.NewSessionService,
+		service.NewTwoFactorService,
+		service.NewRBACService,
+		service.NewAsyncSmsService,
+		service.NewLoginGuardService,
 
 		//handler
 		web.NewUserHandler,
-		web.NewOAuth2WechatHandler,
+		ioc.InitOAuth2WechatHandler,
+		ioc.InitOAuth2Registry,
+		ioc.InitOAuth2Handler,
+		web.NewArticleHandler,
+		web.NewCollectionHandler,
+		web.NewSessionHandler,
+		web.NewTwoFactorHandler,
+		web.NewAdminHandler,
 
+		ioc.InitJWTHandler,
 		ioc.NewLimiter,
 		ioc.InitGinMiddlewares,
 		ioc.InitWebServer,
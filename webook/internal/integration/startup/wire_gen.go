@@ -22,19 +22,65 @@ import (
 func InitWebServer() *gin.Engine {
 	cmdable := InitRedis()
 	limiter := ioc.NewLimiter(cmdable)
-	v := ioc.InitGinMiddlewares(limiter)
+	sessionCache := cache.NewRedisSessionCache(cmdable)
+	sessionRepository := repository.NewSessionRepository(sessionCache)
+	sessionService := service.NewSessionService(sessionRepository)
 	db := ioc.InitDB()
 	userDao := dao.NewUserDao(db)
 	userCache := cache.NewRedisUserCache(cmdable)
-	userRepository := repository.NewUserRepository(userDao, userCache)
+	identityDAO := dao.NewIdentityDAO(db)
+	userRepository := repository.NewUserRepository(userDao, identityDAO, userCache)
 	userService := service.NewUserService(userRepository)
+	roleDAO := dao.NewRoleDAO(db)
+	roleRepository := repository.NewRoleRepository(roleDAO)
+	rbacService := service.NewRBACService(userRepository, roleRepository)
+	jwtHandler := ioc.InitJWTHandler(cmdable, sessionService, rbacService)
+	v := ioc.InitGinMiddlewares(limiter, jwtHandler, userService, rbacService)
 	codeCache := cache.NewRedisCodeCache(cmdable)
 	codeRepository := repository.NewCodeRepository(codeCache)
 	smsService := ioc.InitSMSService()
-	codeService := service.NewCodeService(codeRepository, smsService)
-	userHandler := web.NewUserHandler(userService, codeService)
+	emailService := ioc.InitEmailService()
+	codeService := ioc.InitCodeService(codeRepository, smsService, emailService)
+	twoFactorDAO := dao.NewTwoFactorDAO(db)
+	twoFactorCache := cache.NewRedisTwoFactorCache(cmdable)
+	twoFactorRepository := ioc.InitTwoFactorRepository(twoFactorDAO, twoFactorCache)
+	twoFactorService := service.NewTwoFactorService(twoFactorRepository)
+	loginAttemptCache := cache.NewRedisLoginAttemptCache(cmdable)
+	loginAttemptRepository := repository.NewLoginAttemptRepository(loginAttemptCache)
+	logger := ioc.InitLogger()
+	loginGuardService := service.NewLoginGuardService(loginAttemptRepository, logger)
+	userHandler := web.NewUserHandler(userService, codeService, twoFactorService, loginGuardService, jwtHandler)
 	wechatService := ioc.InitWechatService()
-	oAuth2WechatHandler := web.NewOAuth2WechatHandler(wechatService, userService)
-	engine := ioc.InitWebServer(v, userHandler, oAuth2WechatHandler)
+	wechatTokenDAO := dao.NewWechatTokenDAO(db)
+	wechatTokenRepository := ioc.InitWechatTokenRepository(wechatTokenDAO)
+	wechatAccountService := service.NewWechatAccountService(wechatService, userService, userRepository, wechatTokenRepository, logger)
+	oAuth2WechatHandler := ioc.InitOAuth2WechatHandler(wechatService, wechatAccountService, jwtHandler)
+	registry := ioc.InitOAuth2Registry()
+	oAuth2Handler := ioc.InitOAuth2Handler(registry, userService, jwtHandler)
+	articleDAO := dao.NewArticleDAO(db)
+	articleCache := cache.NewRedisArticleCache(cmdable)
+	articleRepository := repository.NewArticleRepository(articleDAO, articleCache)
+	articleService := service.NewArticleService(articleRepository)
+	interactiveDAO := dao.NewInteractiveDAO(db)
+	interactiveCache := cache.NewRedisInteractiveCache(cmdable)
+	interactiveRepository := repository.NewInteractiveRepository(interactiveDAO, interactiveCache)
+	readCntBuffer := ioc.InitReadCntBuffer(interactiveRepository, logger)
+	interactiveService := service.NewInteractiveService(interactiveRepository, readCntBuffer)
+	rankingCache := cache.NewRedisRankingCache(cmdable)
+	rankingLocalCache := cache.NewRankingLocalCache()
+	rankingRepository := repository.NewRankingRepository(rankingCache, rankingLocalCache)
+	rankingService := service.NewBatchRankingService(articleService, interactiveService, rankingRepository)
+	articleHandler := web.NewArticleHandler(articleService, interactiveService, rankingService, logger)
+	collectionDAO := dao.NewCollectionDAO(db)
+	collectionRepository := repository.NewCollectionRepository(collectionDAO, interactiveCache)
+	collectionService := service.NewCollectionService(collectionRepository)
+	collectionHandler := web.NewCollectionHandler(collectionService, articleService, logger)
+	sessionHandler := web.NewSessionHandler(sessionService, jwtHandler, logger)
+	twoFactorHandler := web.NewTwoFactorHandler(twoFactorService, userService, loginGuardService, jwtHandler, logger)
+	asyncSmsDAO := dao.NewGORMAsyncSmsDAO(db)
+	asyncSmsRepository := repository.NewAsyncSMSRepository(asyncSmsDAO)
+	asyncSmsService := service.NewAsyncSmsService(asyncSmsRepository)
+	adminHandler := web.NewAdminHandler(userService, rbacService, asyncSmsService, logger)
+	engine := ioc.InitWebServer(v, userHandler, oAuth2WechatHandler, articleHandler, collectionHandler, sessionHandler, twoFactorHandler, oAuth2Handler, adminHandler)
 	return engine
 }
@@ -0,0 +1,169 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"gitee.com/geekbang/basic-go/webook/config"
+	"gitee.com/geekbang/basic-go/webook/internal/integration/startup"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/dao"
+	"gitee.com/geekbang/basic-go/webook/ioc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const wechatFrontendURL = "http://localhost:3000/oauth2/wechat/done"
+
+// newFakeWechatServer 模拟微信开放平台，只认 code=good-code
+func newFakeWechatServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sns/oauth2/access_token", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("appid") != "it-app" || q.Get("secret") != "it-secret" || q.Get("code") != "good-code" {
+			_, _ = w.Write([]byte(`{"errcode":40029,"errmsg":"invalid code"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"access_token":"it-access","expires_in":7200,"refresh_token":"it-refresh",
+			"openid":"it-open-1","scope":"snsapi_login","unionid":"it-union-1"}`))
+	})
+	mux.HandleFunc("/sns/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"openid":"it-open-1","nickname":"微信小明",
+			"headimgurl":"https://thirdwx.qlogo.cn/it.png","unionid":"it-union-1"}`))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestOAuth2WechatHandler_Callback(t *testing.T) {
+	fake := newFakeWechatServer(t)
+	config.Config.Wechat.AppID = "it-app"
+	config.Config.Wechat.AppSecret = "it-secret"
+	config.Config.Wechat.AuthURL = fake.URL + "/connect/qrconnect"
+	config.Config.Wechat.APIBaseURL = fake.URL
+	config.Config.Wechat.FrontendURL = wechatFrontendURL
+
+	db := ioc.InitDB()
+	server := startup.InitWebServer()
+
+	testCases := []struct {
+		name string
+		// code 是微信回调带过来的
+		code string
+		// badState 为 true 的时候回调带的 state 和 cookie 对不上
+		badState bool
+		after    func(t *testing.T, fragment url.Values)
+
+		wantFragment url.Values
+	}{
+		{
+			name: "login success",
+			code: "good-code",
+			after: func(t *testing.T, fragment url.Values) {
+				accessToken := fragment.Get("access_token")
+				require.NotEmpty(t, accessToken)
+				assert.NotEmpty(t, fragment.Get("refresh_token"))
+
+				// 拿到的 access token 能直接用，昵称和头像是从微信资料里面来的
+				req, err := http.NewRequest(http.MethodGet, "/users/profile", nil)
+				require.NoError(t, err)
+				req.Header.Set("Authorization", "Bearer "+accessToken)
+				recorder := httptest.NewRecorder()
+				server.ServeHTTP(recorder, req)
+				require.Equal(t, http.StatusOK, recorder.Code)
+				var profile struct {
+					Nickname string
+					Avatar   string
+				}
+				require.NoError(t, json.NewDecoder(recorder.Body).Decode(&profile))
+				assert.Equal(t, "微信小明", profile.Nickname)
+				assert.Equal(t, "https://thirdwx.qlogo.cn/it.png", profile.Avatar)
+
+				var identity dao.UserIdentity
+				err = db.Where("provider = ? AND subject = ?", "wechat", "it-open-1").
+					First(&identity).Error
+				require.NoError(t, err)
+				var token dao.WechatToken
+				err = db.Where("uid = ?", identity.Uid).First(&token).Error
+				require.NoError(t, err)
+				// 落库的是密文
+				assert.NotEqual(t, "it-access", token.AccessToken)
+
+				db.Where("uid = ?", identity.Uid).Delete(&dao.WechatToken{})
+				db.Where("uid = ?", identity.Uid).Delete(&dao.UserIdentity{})
+				db.Where("id = ?", identity.Uid).Delete(&dao.User{})
+			},
+		},
+		{
+			name:     "bad state",
+			code:     "good-code",
+			badState: true,
+			after:    func(t *testing.T, fragment url.Values) {},
+			wantFragment: url.Values{
+				"code":  {"4"},
+				"error": {"illegal request"},
+			},
+		},
+		{
+			name:  "bad code",
+			code:  "bad-code",
+			after: func(t *testing.T, fragment url.Values) {},
+			wantFragment: url.Values{
+				"code":  {"4"},
+				"error": {"authorization code error"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// 先拿授权地址，state 在地址里面，签过名的 state 在 cookie 里面
+			req, err := http.NewRequest(http.MethodGet, "/oauth2/wechat/authurl", nil)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+			require.Equal(t, http.StatusOK, recorder.Code)
+			var res struct {
+				Code int
+				Data string
+			}
+			require.NoError(t, json.NewDecoder(recorder.Body).Decode(&res))
+			require.Equal(t, 0, res.Code)
+			require.True(t, strings.HasPrefix(res.Data, fake.URL+"/connect/qrconnect?"))
+			authURL, err := url.Parse(res.Data)
+			require.NoError(t, err)
+			state := authURL.Query().Get("state")
+			require.NotEmpty(t, state)
+			cookies := recorder.Result().Cookies()
+			require.NotEmpty(t, cookies)
+			if tc.badState {
+				state = "another-state"
+			}
+
+			req, err = http.NewRequest(http.MethodGet, "/oauth2/wechat/callback?"+url.Values{
+				"code":  {tc.code},
+				"state": {state},
+			}.Encode(), nil)
+			require.NoError(t, err)
+			for _, ck := range cookies {
+				req.AddCookie(ck)
+			}
+			recorder = httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+
+			require.Equal(t, http.StatusFound, recorder.Code)
+			location := recorder.Header().Get("Location")
+			require.True(t, strings.HasPrefix(location, wechatFrontendURL+"#"), location)
+			fragment, err := url.ParseQuery(strings.TrimPrefix(location, wechatFrontendURL+"#"))
+			require.NoError(t, err)
+			if tc.wantFragment != nil {
+				assert.Equal(t, tc.wantFragment, fragment)
+			}
+			tc.after(t, fragment)
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package job
+
+import (
+	"context"
+	"fmt"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+)
+
+// Executor 执行数据库里面抢占到的任务
+type Executor interface {
+	Name() string
+	Exec(ctx context.Context, j domain.CronJob) error
+}
+
+// LocalFuncExecutor 按照任务名字找到本地注册的方法执行
+type LocalFuncExecutor struct {
+	funcs map[string]func(ctx context.Context, j domain.CronJob) error
+}
+
+func NewLocalFuncExecutor() *LocalFuncExecutor {
+	return &LocalFuncExecutor{
+		funcs: make(map[string]func(ctx context.Context, j domain.CronJob) error),
+	}
+}
+
+func (e *LocalFuncExecutor) Name() string {
+	return "local"
+}
+
+func (e *LocalFuncExecutor) RegisterFunc(name string, fn func(ctx context.Context, j domain.CronJob) error) {
+	e.funcs[name] = fn
+}
+
+func (e *LocalFuncExecutor) Exec(ctx context.Context, j domain.CronJob) error {
+	fn, ok := e.funcs[j.Name]
+	if !ok {
+		return fmt.Errorf("no local func registered for job %s", j.Name)
+	}
+	return fn(ctx, j)
+}
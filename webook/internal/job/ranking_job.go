@@ -0,0 +1,26 @@
+package job
+
+import (
+	"context"
+
+	"gitee.com/geekbang/basic-go/webook/internal/service"
+)
+
+// RankingJob 定时计算热榜
+type RankingJob struct {
+	svc service.RankingService
+}
+
+func NewRankingJob(svc service.RankingService) *RankingJob {
+	return &RankingJob{
+		svc: svc,
+	}
+}
+
+func (r *RankingJob) Name() string {
+	return "ranking"
+}
+
+func (r *RankingJob) Run(ctx context.Context) error {
+	return r.svc.TopN(ctx)
+}
@@ -0,0 +1,93 @@
+package job
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/service"
+	"go.uber.org/zap"
+)
+
+// Scheduler 不停地从数据库抢占到期的任务来执行，同时执行的任务数量不超过 limit
+type Scheduler struct {
+	svc       service.CronJobService
+	l         *zap.Logger
+	executors map[string]Executor
+	// limit 控制同时执行的任务数量
+	limit chan struct{}
+	// idle 没有任务可以抢的时候歇一会
+	idle time.Duration
+}
+
+func NewScheduler(svc service.CronJobService, l *zap.Logger, limit int) *Scheduler {
+	return &Scheduler{
+		svc:       svc,
+		l:         l,
+		executors: make(map[string]Executor),
+		limit:     make(chan struct{}, limit),
+		idle:      time.Second,
+	}
+}
+
+func (s *Scheduler) RegisterExecutor(exec Executor) {
+	s.executors[exec.Name()] = exec
+}
+
+// Schedule 一直调度到 ctx 被取消，返回之前会等正在执行的任务结束
+func (s *Scheduler) Schedule(ctx context.Context) error {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case s.limit <- struct{}{}:
+		}
+
+		// 抢占本身的超时在 Preempt 里面控制，这里传的 ctx 要一直活到任务执行完
+		runCtx, j, err := s.svc.Preempt(ctx)
+		if err != nil {
+			<-s.limit
+			if err != service.ErrNoWaitingJob {
+				s.l.Error("preempt job failed", zap.Error(err))
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(s.idle):
+			}
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer func() {
+				j.CancelFunc()
+				<-s.limit
+				wg.Done()
+			}()
+			s.exec(runCtx, j)
+		}()
+	}
+}
+
+func (s *Scheduler) exec(ctx context.Context, j domain.CronJob) {
+	exec, ok := s.executors[j.Executor]
+	if !ok {
+		s.l.Error("unknown executor", zap.Int64("id", j.Id),
+			zap.String("name", j.Name), zap.String("executor", j.Executor))
+		return
+	}
+	start := time.Now()
+	s.l.Info("job start", zap.Int64("id", j.Id), zap.String("name", j.Name))
+	err := exec.Exec(ctx, j)
+	if err != nil {
+		s.l.Error("job failed", zap.Int64("id", j.Id), zap.String("name", j.Name),
+			zap.Duration("duration", time.Since(start)), zap.Error(err))
+		return
+	}
+	s.l.Info("job end", zap.Int64("id", j.Id), zap.String("name", j.Name),
+		zap.Duration("duration", time.Since(start)))
+}
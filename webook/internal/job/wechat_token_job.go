@@ -0,0 +1,26 @@
+package job
+
+import (
+	"context"
+
+	"gitee.com/geekbang/basic-go/webook/internal/service"
+)
+
+// WechatTokenJob 提前刷新快过期的微信 access token
+type WechatTokenJob struct {
+	svc service.WechatAccountService
+}
+
+func NewWechatTokenJob(svc service.WechatAccountService) *WechatTokenJob {
+	return &WechatTokenJob{
+		svc: svc,
+	}
+}
+
+func (w *WechatTokenJob) Name() string {
+	return "wechat_token"
+}
+
+func (w *WechatTokenJob) Run(ctx context.Context) error {
+	return w.svc.RefreshExpiring(ctx)
+}
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/cache"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/dao"
+	"github.com/ecodeclub/ekit/slice"
+)
+
+var (
+	ErrPossibleIncorrectAuthor = dao.ErrPossibleIncorrectAuthor
+	ErrArticleNotFound         = dao.ErrRecordNotFound
+)
+
+// firstPageSize 第一页缓存的条数，limit 不超过它的第一页请求都走缓存
+const firstPageSize = 100
+
+type ArticleRepository interface {
+	Create(ctx context.Context, art domain.Article) (int64, error)
+	Update(ctx context.Context, art domain.Article) error
+	// Sync 保存草稿并同步到线上库
+	Sync(ctx context.Context, art domain.Article) (int64, error)
+	SyncStatus(ctx context.Context, uid int64, id int64, status domain.ArticleStatus) error
+	GetById(ctx context.Context, id int64) (domain.Article, error)
+	// GetByAuthor 作者的文章列表，Content 只有摘要，走不走缓存都一样
+	GetByAuthor(ctx context.Context, uid int64, offset int, limit int) ([]domain.Article, error)
+	GetPublishedById(ctx context.Context, id int64) (domain.Article, error)
+	GetPublishedByIds(ctx context.Context, ids []int64) ([]domain.Article, error)
+	ListPub(ctx context.Context, start time.Time, offset int, limit int) ([]domain.Article, error)
+}
+
+type CachedArticleRepository struct {
+	dao   dao.ArticleDAO
+	cache cache.ArticleCache
+}
+
+func NewArticleRepository(dao dao.ArticleDAO, cache cache.ArticleCache) ArticleRepository {
+	return &CachedArticleRepository{
+		dao:   dao,
+		cache: cache,
+	}
+}
+
+func (repo *CachedArticleRepository) Create(ctx context.Context, art domain.Article) (int64, error) {
+	id, err := repo.dao.Insert(ctx, repo.toEntity(art))
+	if err == nil {
+		repo.delFirstPage(ctx, art.Author.Id)
+	}
+	return id, err
+}
+
+func (repo *CachedArticleRepository) Update(ctx context.Context, art domain.Article) error {
+	err := repo.dao.UpdateById(ctx, repo.toEntity(art))
+	if err == nil {
+		repo.delFirstPage(ctx, art.Author.Id)
+	}
+	return err
+}
+
+func (repo *CachedArticleRepository) Sync(ctx context.Context, art domain.Article) (int64, error) {
+	id, err := repo.dao.Sync(ctx, repo.toEntity(art))
+	if err == nil {
+		repo.delFirstPage(ctx, art.Author.Id)
+	}
+	return id, err
+}
+
+func (repo *CachedArticleRepository) SyncStatus(ctx context.Context, uid int64, id int64, status domain.ArticleStatus) error {
+	err := repo.dao.SyncStatus(ctx, uid, id, status.ToUint8())
+	if err == nil {
+		repo.delFirstPage(ctx, uid)
+	}
+	return err
+}
+
+func (repo *CachedArticleRepository) GetById(ctx context.Context, id int64) (domain.Article, error) {
+	art, err := repo.dao.GetById(ctx, id)
+	if err != nil {
+		return domain.Article{}, err
+	}
+	return repo.toDomain(art), nil
+}
+
+func (repo *CachedArticleRepository) GetByAuthor(ctx context.Context, uid int64, offset int, limit int) ([]domain.Article, error) {
+	// 只有第一页走缓存
+	if offset != 0 || limit > firstPageSize {
+		arts, err := repo.dao.GetByAuthor(ctx, uid, offset, limit)
+		if err != nil {
+			return nil, err
+		}
+		return slice.Map(arts, func(idx int, src dao.Article) domain.Article {
+			return repo.toAbstract(src)
+		}), nil
+	}
+
+	res, err := repo.cache.GetFirstPage(ctx, uid)
+	if err == nil {
+		return repo.truncate(res, limit), nil
+	}
+
+	arts, err := repo.dao.GetByAuthor(ctx, uid, 0, firstPageSize)
+	if err != nil {
+		return nil, err
+	}
+	res = slice.Map(arts, func(idx int, src dao.Article) domain.Article {
+		return repo.toAbstract(src)
+	})
+	err = repo.cache.SetFirstPage(ctx, uid, res)
+	if err != nil {
+		log.Println(err)
+	}
+	return repo.truncate(res, limit), nil
+}
+
+func (repo *CachedArticleRepository) GetPublishedById(ctx context.Context, id int64) (domain.Article, error) {
+	art, err := repo.dao.GetPubById(ctx, id)
+	if err != nil {
+		return domain.Article{}, err
+	}
+	return repo.toDomain(dao.Article(art)), nil
+}
+
+func (repo *CachedArticleRepository) GetPublishedByIds(ctx context.Context, ids []int64) ([]domain.Article, error) {
+	arts, err := repo.dao.GetPubByIds(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map(arts, func(idx int, src dao.PublishedArticle) domain.Article {
+		return repo.toDomain(dao.Article(src))
+	}), nil
+}
+
+func (repo *CachedArticleRepository) ListPub(ctx context.Context, start time.Time, offset int, limit int) ([]domain.Article, error) {
+	arts, err := repo.dao.ListPub(ctx, start.UnixMilli(),
+		domain.ArticleStatusPublished.ToUint8(), offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map(arts, func(idx int, src dao.PublishedArticle) domain.Article {
+		return repo.toDomain(dao.Article(src))
+	}), nil
+}
+
+func (repo *CachedArticleRepository) truncate(arts []domain.Article, limit int) []domain.Article {
+	if len(arts) > limit {
+		return arts[:limit]
+	}
+	return arts
+}
+
+func (repo *CachedArticleRepository) delFirstPage(ctx context.Context, uid int64) {
+	// 删除缓存失败也不影响保存的结果，等它过期
+	err := repo.cache.DelFirstPage(ctx, uid)
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+// toAbstract 列表用，内容换成摘要，和缓存里面的一样
+func (repo *CachedArticleRepository) toAbstract(art dao.Article) domain.Article {
+	res := repo.toDomain(art)
+	res.Content = res.Abstract()
+	return res
+}
+
+func (repo *CachedArticleRepository) toDomain(art dao.Article) domain.Article {
+	return domain.Article{
+		Id:      art.Id,
+		Title:   art.Title,
+		Content: art.Content,
+		Author: domain.Author{
+			Id: art.AuthorId,
+		},
+		Status: domain.ArticleStatus(art.Status),
+		Ctime:  time.UnixMilli(art.Ctime),
+		Utime:  time.UnixMilli(art.Utime),
+	}
+}
+
+func (repo *CachedArticleRepository) toEntity(art domain.Article) dao.Article {
+	return dao.Article{
+		Id:       art.Id,
+		Title:    art.Title,
+		Content:  art.Content,
+		AuthorId: art.Author.Id,
+		Status:   art.Status.ToUint8(),
+	}
+}
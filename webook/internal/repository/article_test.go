@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/cache"
+	cachemocks "gitee.com/geekbang/basic-go/webook/internal/repository/cache/mocks"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/dao"
+	daomocks "gitee.com/geekbang/basic-go/webook/internal/repository/dao/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCachedArticleRepository_GetByAuthor(t *testing.T) {
+	now := time.UnixMilli(time.Now().UnixMilli())
+	// 不管走不走缓存，列表里面都只有摘要
+	content := strings.Repeat("a", 200)
+	abstract := strings.Repeat("a", 128)
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) (dao.ArticleDAO, cache.ArticleCache)
+		uid      int64
+		offset   int
+		limit    int
+		wantArts []domain.Article
+		wantErr  error
+	}{
+		{
+			name: "first page hit cache",
+			mock: func(ctrl *gomock.Controller) (dao.ArticleDAO, cache.ArticleCache) {
+				d := daomocks.NewMockArticleDAO(ctrl)
+				c := cachemocks.NewMockArticleCache(ctrl)
+				c.EXPECT().GetFirstPage(gomock.Any(), int64(123)).Return([]domain.Article{
+					{Id: 3, Title: "title3"},
+					{Id: 2, Title: "title2"},
+					{Id: 1, Title: "title1"},
+				}, nil)
+				return d, c
+			},
+			uid:   123,
+			limit: 2,
+			wantArts: []domain.Article{
+				{Id: 3, Title: "title3"},
+				{Id: 2, Title: "title2"},
+			},
+		},
+		{
+			name: "first page miss cache",
+			mock: func(ctrl *gomock.Controller) (dao.ArticleDAO, cache.ArticleCache) {
+				d := daomocks.NewMockArticleDAO(ctrl)
+				c := cachemocks.NewMockArticleCache(ctrl)
+				c.EXPECT().GetFirstPage(gomock.Any(), int64(123)).Return(nil, cache.ErrKeyNotExist)
+				d.EXPECT().GetByAuthor(gomock.Any(), int64(123), 0, firstPageSize).
+					Return([]dao.Article{
+						{Id: 2, Title: "title2", Content: content, AuthorId: 123, Status: 1, Ctime: now.UnixMilli(), Utime: now.UnixMilli()},
+						{Id: 1, Title: "title1", AuthorId: 123, Status: 2, Ctime: now.UnixMilli(), Utime: now.UnixMilli()},
+					}, nil)
+				c.EXPECT().SetFirstPage(gomock.Any(), int64(123), []domain.Article{
+					{Id: 2, Title: "title2", Content: abstract, Author: domain.Author{Id: 123}, Status: domain.ArticleStatusUnpublished, Ctime: now, Utime: now},
+					{Id: 1, Title: "title1", Author: domain.Author{Id: 123}, Status: domain.ArticleStatusPublished, Ctime: now, Utime: now},
+				}).Return(nil)
+				return d, c
+			},
+			uid:   123,
+			limit: 10,
+			wantArts: []domain.Article{
+				{Id: 2, Title: "title2", Content: abstract, Author: domain.Author{Id: 123}, Status: domain.ArticleStatusUnpublished, Ctime: now, Utime: now},
+				{Id: 1, Title: "title1", Author: domain.Author{Id: 123}, Status: domain.ArticleStatusPublished, Ctime: now, Utime: now},
+			},
+		},
+		{
+			name: "other pages skip cache",
+			mock: func(ctrl *gomock.Controller) (dao.ArticleDAO, cache.ArticleCache) {
+				d := daomocks.NewMockArticleDAO(ctrl)
+				c := cachemocks.NewMockArticleCache(ctrl)
+				d.EXPECT().GetByAuthor(gomock.Any(), int64(123), 10, 10).
+					Return([]dao.Article{
+						{Id: 1, Title: "title1", Content: content, AuthorId: 123, Ctime: now.UnixMilli(), Utime: now.UnixMilli()},
+					}, nil)
+				return d, c
+			},
+			uid:    123,
+			offset: 10,
+			limit:  10,
+			wantArts: []domain.Article{
+				{Id: 1, Title: "title1", Content: abstract, Author: domain.Author{Id: 123}, Ctime: now, Utime: now},
+			},
+		},
+		{
+			name: "db error",
+			mock: func(ctrl *gomock.Controller) (dao.ArticleDAO, cache.ArticleCache) {
+				d := daomocks.NewMockArticleDAO(ctrl)
+				c := cachemocks.NewMockArticleCache(ctrl)
+				c.EXPECT().GetFirstPage(gomock.Any(), int64(123)).Return(nil, cache.ErrKeyNotExist)
+				d.EXPECT().GetByAuthor(gomock.Any(), int64(123), 0, firstPageSize).
+					Return(nil, errors.New("db error"))
+				return d, c
+			},
+			uid:     123,
+			limit:   10,
+			wantErr: errors.New("db error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			d, c := tc.mock(ctrl)
+			repo := NewArticleRepository(d, c)
+			arts, err := repo.GetByAuthor(context.Background(), tc.uid, tc.offset, tc.limit)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantArts, arts)
+		})
+	}
+}
+
+func TestCachedArticleRepository_Sync(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	d := daomocks.NewMockArticleDAO(ctrl)
+	c := cachemocks.NewMockArticleCache(ctrl)
+	d.EXPECT().Sync(gomock.Any(), dao.Article{
+		Id:       1,
+		Title:    "title",
+		AuthorId: 123,
+		Status:   domain.ArticleStatusPublished.ToUint8(),
+	}).Return(int64(1), nil)
+	// 发表之后第一页要失效
+	c.EXPECT().DelFirstPage(gomock.Any(), int64(123)).Return(nil)
+
+	repo := NewArticleRepository(d, c)
+	id, err := repo.Sync(context.Background(), domain.Article{
+		Id:     1,
+		Title:  "title",
+		Author: domain.Author{Id: 123},
+		Status: domain.ArticleStatusPublished,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), id)
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"gitee.com/geekbang/basic-go/webook/internal/domain"
 	"gitee.com/geekbang/basic-go/webook/internal/repository/dao"
@@ -16,6 +17,10 @@ type AsyncSmsRepository interface {
 	Add(ctx context.Context, s domain.AsyncSms) error
 	PreemptWaitingSMS(ctx context.Context) (domain.AsyncSms, error)
 	ReportScheduleResult(ctx context.Context, id int64, success bool) error
+	// ListByStatus 按 id 倒序
+	ListByStatus(ctx context.Context, status domain.AsyncSmsStatus, offset int, limit int) ([]domain.AsyncSms, error)
+	// CountByStatus 没有记录的状态也会返回 0
+	CountByStatus(ctx context.Context) (map[domain.AsyncSmsStatus]int64, error)
 }
 
 type asyncSmsRepository struct {
@@ -66,3 +71,42 @@ func (a *asyncSmsRepository) ReportScheduleResult(ctx context.Context, id int64,
 	}
 	return a.dao.MarkFailed(ctx, id)
 }
+
+func (a *asyncSmsRepository) ListByStatus(ctx context.Context, status domain.AsyncSmsStatus,
+	offset int, limit int) ([]domain.AsyncSms, error) {
+	list, err := a.dao.ListByStatus(ctx, status.ToUint8(), offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.AsyncSms, 0, len(list))
+	for _, as := range list {
+		res = append(res, domain.AsyncSms{
+			Id:       as.Id,
+			TplId:    as.Config.Val.TplId,
+			Numbers:  as.Config.Val.Numbers,
+			Args:     as.Config.Val.Args,
+			RetryMax: as.RetryMax,
+			RetryCnt: as.RetryCnt,
+			Status:   domain.AsyncSmsStatus(as.Status),
+			Ctime:    time.UnixMilli(as.Ctime),
+			Utime:    time.UnixMilli(as.Utime),
+		})
+	}
+	return res, nil
+}
+
+func (a *asyncSmsRepository) CountByStatus(ctx context.Context) (map[domain.AsyncSmsStatus]int64, error) {
+	cnts, err := a.dao.CountByStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := map[domain.AsyncSmsStatus]int64{
+		domain.AsyncSmsStatusWaiting: 0,
+		domain.AsyncSmsStatusFailed:  0,
+		domain.AsyncSmsStatusSuccess: 0,
+	}
+	for status, cnt := range cnts {
+		res[domain.AsyncSmsStatus(status)] = cnt
+	}
+	return res, nil
+}
@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+type ArticleCache interface {
+	// GetFirstPage 作者文章列表的第一页，只缓存摘要
+	GetFirstPage(ctx context.Context, uid int64) ([]domain.Article, error)
+	SetFirstPage(ctx context.Context, uid int64, arts []domain.Article) error
+	DelFirstPage(ctx context.Context, uid int64) error
+}
+
+type RedisArticleCache struct {
+	cmd        redis.Cmdable
+	expiration time.Duration
+}
+
+func NewRedisArticleCache(cmd redis.Cmdable) ArticleCache {
+	return &RedisArticleCache{
+		cmd:        cmd,
+		expiration: time.Minute * 10,
+	}
+}
+
+func (c *RedisArticleCache) GetFirstPage(ctx context.Context, uid int64) ([]domain.Article, error) {
+	data, err := c.cmd.Get(ctx, c.firstPageKey(uid)).Bytes()
+	if err != nil {
+		return nil, err
+	}
+	var arts []domain.Article
+	err = json.Unmarshal(data, &arts)
+	return arts, err
+}
+
+func (c *RedisArticleCache) SetFirstPage(ctx context.Context, uid int64, arts []domain.Article) error {
+	page := make([]domain.Article, len(arts))
+	for i, art := range arts {
+		art.Content = art.Abstract()
+		page[i] = art
+	}
+	data, err := json.Marshal(page)
+	if err != nil {
+		return err
+	}
+	return c.cmd.Set(ctx, c.firstPageKey(uid), data, c.expiration).Err()
+}
+
+func (c *RedisArticleCache) DelFirstPage(ctx context.Context, uid int64) error {
+	return c.cmd.Del(ctx, c.firstPageKey(uid)).Err()
+}
+
+func (c *RedisArticleCache) firstPageKey(uid int64) string {
+	return fmt.Sprintf("article:first_page:%d", uid)
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"time"
 
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
 	"github.com/allegro/bigcache/v3"
 	"github.com/redis/go-redis/v9"
 )
@@ -22,9 +23,9 @@ var (
 )
 
 type CodeCache interface {
-	Set(ctx context.Context, biz, phone, code string) error
-	Verify(ctx context.Context, biz, phone, code string) (bool, error)
-	key(biz, phone string) string
+	Set(ctx context.Context, channel domain.CodeChannel, biz, target, code string) error
+	Verify(ctx context.Context, channel domain.CodeChannel, biz, target, code string) (bool, error)
+	key(channel domain.CodeChannel, biz, target string) string
 }
 
 type RedisCodeCache struct {
@@ -41,8 +42,8 @@ func NewRedisCodeCache(cmd redis.Cmdable) CodeCache {
 	}
 }
 
-func (c *RedisCodeCache) Set(ctx context.Context, biz, phone, code string) error {
-	res, err := c.cmd.Eval(ctx, luaSetCode, []string{c.key(biz, phone)}, code).Int()
+func (c *RedisCodeCache) Set(ctx context.Context, channel domain.CodeChannel, biz, target, code string) error {
+	res, err := c.cmd.Eval(ctx, luaSetCode, []string{c.key(channel, biz, target)}, code).Int()
 	//log.Println("set error:",err)
 	if err != nil {
 		return err
@@ -60,8 +61,8 @@ func (c *RedisCodeCache) Set(ctx context.Context, biz, phone, code string) error
 
 }
 
-func (c *RedisCodeCache) Verify(ctx context.Context, biz, phone, code string) (bool, error) {
-	res, err := c.cmd.Eval(ctx, luaVerifyCode, []string{c.key(biz, phone)}, code).Int()
+func (c *RedisCodeCache) Verify(ctx context.Context, channel domain.CodeChannel, biz, target, code string) (bool, error) {
+	res, err := c.cmd.Eval(ctx, luaVerifyCode, []string{c.key(channel, biz, target)}, code).Int()
 	if err != nil {
 		return false, err
 	}
@@ -78,8 +79,8 @@ func (c *RedisCodeCache) Verify(ctx context.Context, biz, phone, code string) (b
 
 }
 
-func (c *RedisCodeCache) key(biz, phone string) string {
-	return fmt.Sprintf("phone_code:%s:%s", biz, phone)
+func (c *RedisCodeCache) key(channel domain.CodeChannel, biz, target string) string {
+	return fmt.Sprintf("%s_code:%s:%s", channel, biz, target)
 }
 
 func NewBigCacheCodeCache(cache *bigcache.BigCache) CodeCache {
@@ -88,8 +89,8 @@ func NewBigCacheCodeCache(cache *bigcache.BigCache) CodeCache {
 	}
 }
 
-func (c *BigCacheCodeCache) Set(ctx context.Context, biz, phone, code string) error {
-	key := c.key(biz, phone)
+func (c *BigCacheCodeCache) Set(ctx context.Context, channel domain.CodeChannel, biz, target, code string) error {
+	key := c.key(channel, biz, target)
 	val, err := c.cache.Get(key)
 	if err == nil {
 		// 获取存储时间戳并解析
@@ -108,8 +109,8 @@ func (c *BigCacheCodeCache) Set(ctx context.Context, biz, phone, code string) er
 	return c.cache.Set(key, data)
 }
 
-func (c *BigCacheCodeCache) Verify(ctx context.Context, biz, phone, code string) (bool, error) {
-	key := c.key(biz, phone)
+func (c *BigCacheCodeCache) Verify(ctx context.Context, channel domain.CodeChannel, biz, target, code string) (bool, error) {
+	key := c.key(channel, biz, target)
 	val, err := c.cache.Get(key)
 	if err != nil {
 		return false, err
@@ -125,6 +126,6 @@ func (c *BigCacheCodeCache) Verify(ctx context.Context, biz, phone, code string)
 	return true, nil
 }
 
-func (c *BigCacheCodeCache) key(biz, phone string) string {
-	return fmt.Sprintf("phone_code:%s:%s", biz, phone)
+func (c *BigCacheCodeCache) key(channel domain.CodeChannel, biz, target string) string {
+	return fmt.Sprintf("%s_code:%s:%s", channel, biz, target)
 }
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 )
@@ -112,7 +113,7 @@ func TestRedisCodeCache_Set_e2e(t *testing.T) {
 			tc.before(t)
 			defer tc.after(t)
 			c := NewRedisCodeCache(rdb)
-			err := c.Set(tc.ctx, tc.biz, tc.phone, tc.code)
+			err := c.Set(tc.ctx, domain.CodeChannelPhone, tc.biz, tc.phone, tc.code)
 			assert.Equal(t, tc.wantErr, err)
 
 		})
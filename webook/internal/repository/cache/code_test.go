@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"testing"
 
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
 	"gitee.com/geekbang/basic-go/webook/internal/repository/cache/redismocks"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
@@ -106,7 +107,7 @@ func TestRedisCodeCache_Set(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 			c := NewRedisCodeCache(tt.mock(ctrl))
-			err := c.Set(tt.args.ctx, tt.args.biz, tt.args.phone, tt.args.code)
+			err := c.Set(tt.args.ctx, domain.CodeChannelPhone, tt.args.biz, tt.args.phone, tt.args.code)
 			assert.Equal(t, tt.wantErr, err)
 
 		})
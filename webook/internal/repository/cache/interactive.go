@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"strconv"
+	"time"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	//go:embed lua/incr_cnt.lua
+	luaIncrCnt string
+)
+
+const (
+	fieldReadCnt    = "read_cnt"
+	fieldLikeCnt    = "like_cnt"
+	fieldCollectCnt = "collect_cnt"
+)
+
+type InteractiveCache interface {
+	AddReadCntIfPresent(ctx context.Context, biz string, bizId int64, delta int64) error
+	IncrLikeCntIfPresent(ctx context.Context, biz string, bizId int64) error
+	DecrLikeCntIfPresent(ctx context.Context, biz string, bizId int64) error
+	IncrCollectCntIfPresent(ctx context.Context, biz string, bizId int64) error
+	DecrCollectCntIfPresent(ctx context.Context, biz string, bizId int64) error
+	Get(ctx context.Context, biz string, bizId int64) (domain.Interactive, error)
+	Set(ctx context.Context, biz string, bizId int64, intr domain.Interactive) error
+}
+
+type RedisInteractiveCache struct {
+	cmd        redis.Cmdable
+	expiration time.Duration
+}
+
+func NewRedisInteractiveCache(cmd redis.Cmdable) InteractiveCache {
+	return &RedisInteractiveCache{
+		cmd:        cmd,
+		expiration: time.Minute * 15,
+	}
+}
+
+func (c *RedisInteractiveCache) AddReadCntIfPresent(ctx context.Context, biz string, bizId int64, delta int64) error {
+	return c.cmd.Eval(ctx, luaIncrCnt, []string{c.key(biz, bizId)}, fieldReadCnt, delta).Err()
+}
+
+func (c *RedisInteractiveCache) IncrLikeCntIfPresent(ctx context.Context, biz string, bizId int64) error {
+	return c.incr(ctx, biz, bizId, fieldLikeCnt, 1)
+}
+
+func (c *RedisInteractiveCache) DecrLikeCntIfPresent(ctx context.Context, biz string, bizId int64) error {
+	return c.incr(ctx, biz, bizId, fieldLikeCnt, -1)
+}
+
+func (c *RedisInteractiveCache) IncrCollectCntIfPresent(ctx context.Context, biz string, bizId int64) error {
+	return c.incr(ctx, biz, bizId, fieldCollectCnt, 1)
+}
+
+func (c *RedisInteractiveCache) DecrCollectCntIfPresent(ctx context.Context, biz string, bizId int64) error {
+	return c.incr(ctx, biz, bizId, fieldCollectCnt, -1)
+}
+
+func (c *RedisInteractiveCache) incr(ctx context.Context, biz string, bizId int64, field string, delta int) error {
+	return c.cmd.Eval(ctx, luaIncrCnt, []string{c.key(biz, bizId)}, field, delta).Err()
+}
+
+func (c *RedisInteractiveCache) Get(ctx context.Context, biz string, bizId int64) (domain.Interactive, error) {
+	res, err := c.cmd.HGetAll(ctx, c.key(biz, bizId)).Result()
+	if err != nil {
+		return domain.Interactive{}, err
+	}
+	if len(res) == 0 {
+		return domain.Interactive{}, ErrKeyNotExist
+	}
+	// 字段缺失的时候就是 0，不需要处理错误
+	readCnt, _ := strconv.ParseInt(res[fieldReadCnt], 10, 64)
+	likeCnt, _ := strconv.ParseInt(res[fieldLikeCnt], 10, 64)
+	collectCnt, _ := strconv.ParseInt(res[fieldCollectCnt], 10, 64)
+	return domain.Interactive{
+		Biz:        biz,
+		BizId:      bizId,
+		ReadCnt:    readCnt,
+		LikeCnt:    likeCnt,
+		CollectCnt: collectCnt,
+	}, nil
+}
+
+func (c *RedisInteractiveCache) Set(ctx context.Context, biz string, bizId int64, intr domain.Interactive) error {
+	key := c.key(biz, bizId)
+	err := c.cmd.HSet(ctx, key,
+		fieldReadCnt, intr.ReadCnt,
+		fieldLikeCnt, intr.LikeCnt,
+		fieldCollectCnt, intr.CollectCnt).Err()
+	if err != nil {
+		return err
+	}
+	return c.cmd.Expire(ctx, key, c.expiration).Err()
+}
+
+func (c *RedisInteractiveCache) key(biz string, bizId int64) string {
+	return fmt.Sprintf("interactive:%s:%d", biz, bizId)
+}
@@ -0,0 +1,84 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/cache/redismocks"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRedisInteractiveCache_Get(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) redis.Cmdable
+		wantIntr domain.Interactive
+		wantErr  error
+	}{
+		{
+			name: "hit",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				res := redis.NewMapStringStringResult(map[string]string{
+					"read_cnt":    "10",
+					"like_cnt":    "3",
+					"collect_cnt": "1",
+				}, nil)
+				cmd.EXPECT().HGetAll(gomock.Any(), "interactive:article:1").Return(res)
+				return cmd
+			},
+			wantIntr: domain.Interactive{
+				Biz:        "article",
+				BizId:      1,
+				ReadCnt:    10,
+				LikeCnt:    3,
+				CollectCnt: 1,
+			},
+		},
+		{
+			name: "miss",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				res := redis.NewMapStringStringResult(map[string]string{}, nil)
+				cmd.EXPECT().HGetAll(gomock.Any(), "interactive:article:1").Return(res)
+				return cmd
+			},
+			wantErr: ErrKeyNotExist,
+		},
+		{
+			name: "redis error",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				res := redis.NewMapStringStringResult(nil, errors.New("redis error"))
+				cmd.EXPECT().HGetAll(gomock.Any(), "interactive:article:1").Return(res)
+				return cmd
+			},
+			wantErr: errors.New("redis error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			c := NewRedisInteractiveCache(tc.mock(ctrl))
+			intr, err := c.Get(context.Background(), "article", 1)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantIntr, intr)
+		})
+	}
+}
+
+func TestRedisInteractiveCache_IncrLikeCntIfPresent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cmd := redismocks.NewMockCmdable(ctrl)
+	cmd.EXPECT().Eval(gomock.Any(), luaIncrCnt, []string{"interactive:article:1"}, []any{"like_cnt", 1}).
+		Return(redis.NewCmdResult(int64(1), nil))
+	c := NewRedisInteractiveCache(cmd)
+	err := c.IncrLikeCntIfPresent(context.Background(), "article", 1)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"time"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	//go:embed lua/reserve_login_attempt.lua
+	luaReserveLoginAttempt string
+	//go:embed lua/release_login_attempt.lua
+	luaReleaseLoginAttempt string
+)
+
+// LoginAttemptCache 登录失败计数。subject 是被限制的对象，比如 account:邮箱、ip:地址
+type LoginAttemptCache interface {
+	// Reserve 检查 subjects 有没有被延迟或者锁定，没有的话每个 subject 先记一次失败，
+	// 返回记完之后的次数，和 policies 一一对应。
+	// 被限制了 counts 为 nil，返回还要等多久，以及是不是锁定
+	Reserve(ctx context.Context, subjects []string, policies []domain.LoginGuardPolicy,
+		window time.Duration) (counts []int64, wait time.Duration, locked bool, err error)
+	// Release 撤回一次 Reserve 记的失败
+	Release(ctx context.Context, subject string) error
+	// Reset 登录成功之后清掉失败次数、延迟和锁定
+	Reset(ctx context.Context, subject string) error
+}
+
+type RedisLoginAttemptCache struct {
+	cmd redis.Cmdable
+}
+
+func NewRedisLoginAttemptCache(cmd redis.Cmdable) LoginAttemptCache {
+	return &RedisLoginAttemptCache{
+		cmd: cmd,
+	}
+}
+
+func (c *RedisLoginAttemptCache) Reserve(ctx context.Context, subjects []string, policies []domain.LoginGuardPolicy,
+	window time.Duration) ([]int64, time.Duration, bool, error) {
+	if len(subjects) != len(policies) {
+		return nil, 0, false, errors.New("subjects 和 policies 数量不一致")
+	}
+	keys := make([]string, 0, len(subjects)*3)
+	args := make([]any, 0, len(subjects)*5+1)
+	args = append(args, window.Milliseconds())
+	for i, subject := range subjects {
+		p := policies[i]
+		keys = append(keys, c.failureKey(subject), c.delayKey(subject), c.lockKey(subject))
+		args = append(args, p.Free, p.LockAt, p.BaseDelay.Milliseconds(),
+			p.MaxDelay.Milliseconds(), p.Lockout.Milliseconds())
+	}
+	// 前两个是等待的毫秒数和是否锁定，后面是每个 subject 的次数
+	res, err := c.cmd.Eval(ctx, luaReserveLoginAttempt, keys, args...).Int64Slice()
+	if err != nil {
+		return nil, 0, false, err
+	}
+	if len(res) < 2 {
+		return nil, 0, false, errors.New("unexpected reserve result")
+	}
+	if res[0] > 0 {
+		return nil, time.Duration(res[0]) * time.Millisecond, res[1] == 1, nil
+	}
+	return res[2:], 0, false, nil
+}
+
+func (c *RedisLoginAttemptCache) Release(ctx context.Context, subject string) error {
+	return c.cmd.Eval(ctx, luaReleaseLoginAttempt, []string{c.failureKey(subject)}).Err()
+}
+
+func (c *RedisLoginAttemptCache) Reset(ctx context.Context, subject string) error {
+	return c.cmd.Del(ctx, c.failureKey(subject), c.delayKey(subject), c.lockKey(subject)).Err()
+}
+
+func (c *RedisLoginAttemptCache) failureKey(subject string) string {
+	return fmt.Sprintf("users:login:fail:%s", subject)
+}
+
+func (c *RedisLoginAttemptCache) delayKey(subject string) string {
+	return fmt.Sprintf("users:login:delay:%s", subject)
+}
+
+func (c *RedisLoginAttemptCache) lockKey(subject string) string {
+	return fmt.Sprintf("users:login:lock:%s", subject)
+}
@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/cache/redismocks"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRedisLoginAttemptCache_Reserve(t *testing.T) {
+	policy := domain.LoginGuardPolicy{
+		Free:      3,
+		LockAt:    10,
+		BaseDelay: time.Second,
+		MaxDelay:  time.Second * 30,
+		Lockout:   time.Minute * 15,
+	}
+	keys := []string{
+		"users:login:fail:account:a", "users:login:delay:account:a", "users:login:lock:account:a",
+		"users:login:fail:ip:b", "users:login:delay:ip:b", "users:login:lock:ip:b",
+	}
+	args := []any{int64(900000),
+		int64(3), int64(10), int64(1000), int64(30000), int64(900000),
+		int64(3), int64(10), int64(1000), int64(30000), int64(900000),
+	}
+	testCases := []struct {
+		name       string
+		mock       func(ctrl *gomock.Controller) redis.Cmdable
+		wantCounts []int64
+		wantWait   time.Duration
+		wantLocked bool
+		wantErr    error
+	}{
+		{
+			name: "reserved",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				res := redis.NewCmd(context.Background())
+				res.SetVal([]any{int64(0), int64(0), int64(4), int64(12)})
+				cmd.EXPECT().Eval(gomock.Any(), luaReserveLoginAttempt, keys, args...).Return(res)
+				return cmd
+			},
+			wantCounts: []int64{4, 12},
+		},
+		{
+			name: "locked",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				res := redis.NewCmd(context.Background())
+				res.SetVal([]any{int64(60000), int64(1)})
+				cmd.EXPECT().Eval(gomock.Any(), luaReserveLoginAttempt, keys, args...).Return(res)
+				return cmd
+			},
+			wantWait:   time.Minute,
+			wantLocked: true,
+		},
+		{
+			name: "redis error",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				res := redis.NewCmd(context.Background())
+				res.SetErr(errors.New("redis error"))
+				cmd.EXPECT().Eval(gomock.Any(), luaReserveLoginAttempt, keys, args...).Return(res)
+				return cmd
+			},
+			wantErr: errors.New("redis error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			c := NewRedisLoginAttemptCache(tc.mock(ctrl))
+			counts, wait, locked, err := c.Reserve(context.Background(), []string{"account:a", "ip:b"},
+				[]domain.LoginGuardPolicy{policy, policy}, time.Minute*15)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantCounts, counts)
+			assert.Equal(t, tc.wantWait, wait)
+			assert.Equal(t, tc.wantLocked, locked)
+		})
+	}
+}
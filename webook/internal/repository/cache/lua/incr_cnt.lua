@@ -0,0 +1,15 @@
+-- interactive:biz:biz_id
+local key = KEYS[1]
+-- read_cnt, like_cnt 或者 collect_cnt
+local cntKey = ARGV[1]
+-- +1 或者 -1
+local delta = tonumber(ARGV[2])
+
+local exists = redis.call("exists", key)
+if exists == 1 then
+    -- 缓存存在才修改，不存在的话等下一次读的时候从数据库加载
+    redis.call("hincrby", key, cntKey, delta)
+    return 1
+else
+    return 0
+end
@@ -0,0 +1,6 @@
+-- 撤回一次预占的失败，不能减成负数
+local cnt = tonumber(redis.call("get", KEYS[1]) or "0")
+if cnt > 0 then
+    redis.call("decr", KEYS[1])
+end
+return 0
@@ -0,0 +1,43 @@
+-- 每个限制对象三个 key：失败次数、延迟、锁定。
+-- 先检查有没有被延迟或者锁定，没有的话每个对象先记一次失败，登录成功之后再撤回。
+-- 检查和计数放在一个脚本里面，并发的尝试也只能一个一个过
+local n = #KEYS / 3
+local window = tonumber(ARGV[1])
+
+local wait = 0
+local locked = 0
+for i = 0, n - 1 do
+    local lockTTL = redis.call("pttl", KEYS[i * 3 + 3])
+    if lockTTL > 0 then
+        locked = 1
+        wait = math.max(wait, lockTTL)
+    end
+    wait = math.max(wait, redis.call("pttl", KEYS[i * 3 + 2]))
+end
+if wait > 0 then
+    return {wait, locked}
+end
+
+local res = {0, 0}
+for i = 0, n - 1 do
+    local failKey = KEYS[i * 3 + 1]
+    -- 每个对象五个参数：free, lockAt, baseDelay, maxDelay, lockout，时间都是毫秒
+    local free = tonumber(ARGV[i * 5 + 2])
+    local lockAt = tonumber(ARGV[i * 5 + 3])
+    local baseDelay = tonumber(ARGV[i * 5 + 4])
+    local maxDelay = tonumber(ARGV[i * 5 + 5])
+    local lockout = tonumber(ARGV[i * 5 + 6])
+
+    local cnt = redis.call("incr", failKey)
+    if cnt == 1 then
+        redis.call("pexpire", failKey, window)
+    end
+    if cnt >= lockAt then
+        redis.call("set", KEYS[i * 3 + 3], 1, "px", lockout)
+    elseif cnt > free then
+        local delay = math.min(baseDelay * 2 ^ (cnt - free - 1), maxDelay)
+        redis.call("set", KEYS[i * 3 + 2], 1, "px", math.floor(delay))
+    end
+    res[#res + 1] = cnt
+end
+return res
@@ -0,0 +1,15 @@
+-- 取挑战对应的 uid，每取一次算一次尝试
+local key = KEYS[1]
+local maxAttempts = tonumber(ARGV[1])
+
+local uid = redis.call("hget", key, "uid")
+if not uid then
+    -- 不存在或者过期了
+    return -1
+end
+local attempts = redis.call("hincrby", key, "attempts", 1)
+if attempts > maxAttempts then
+    redis.call("del", key)
+    return -1
+end
+return tonumber(uid)
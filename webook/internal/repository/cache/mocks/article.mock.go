@@ -0,0 +1,84 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./webook/internal/repository/cache/article.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./webook/internal/repository/cache/article.go -package=cachemocks -destination=./webook/internal/repository/cache/mocks/article.mock.go
+//
+
+// Package cachemocks is a generated GoMock package.
+package cachemocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "gitee.com/geekbang/basic-go/webook/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockArticleCache is a mock of ArticleCache interface.
+type MockArticleCache struct {
+	ctrl     *gomock.Controller
+	recorder *MockArticleCacheMockRecorder
+}
+
+// MockArticleCacheMockRecorder is the mock recorder for MockArticleCache.
+type MockArticleCacheMockRecorder struct {
+	mock *MockArticleCache
+}
+
+// NewMockArticleCache creates a new mock instance.
+func NewMockArticleCache(ctrl *gomock.Controller) *MockArticleCache {
+	mock := &MockArticleCache{ctrl: ctrl}
+	mock.recorder = &MockArticleCacheMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockArticleCache) EXPECT() *MockArticleCacheMockRecorder {
+	return m.recorder
+}
+
+// DelFirstPage mocks base method.
+func (m *MockArticleCache) DelFirstPage(ctx context.Context, uid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DelFirstPage", ctx, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DelFirstPage indicates an expected call of DelFirstPage.
+func (mr *MockArticleCacheMockRecorder) DelFirstPage(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DelFirstPage", reflect.TypeOf((*MockArticleCache)(nil).DelFirstPage), ctx, uid)
+}
+
+// GetFirstPage mocks base method.
+func (m *MockArticleCache) GetFirstPage(ctx context.Context, uid int64) ([]domain.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFirstPage", ctx, uid)
+	ret0, _ := ret[0].([]domain.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFirstPage indicates an expected call of GetFirstPage.
+func (mr *MockArticleCacheMockRecorder) GetFirstPage(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFirstPage", reflect.TypeOf((*MockArticleCache)(nil).GetFirstPage), ctx, uid)
+}
+
+// SetFirstPage mocks base method.
+func (m *MockArticleCache) SetFirstPage(ctx context.Context, uid int64, arts []domain.Article) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetFirstPage", ctx, uid, arts)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetFirstPage indicates an expected call of SetFirstPage.
+func (mr *MockArticleCacheMockRecorder) SetFirstPage(ctx, uid, arts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetFirstPage", reflect.TypeOf((*MockArticleCache)(nil).SetFirstPage), ctx, uid, arts)
+}
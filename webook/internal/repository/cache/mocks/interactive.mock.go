@@ -0,0 +1,140 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./webook/internal/repository/cache/interactive.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./webook/internal/repository/cache/interactive.go -package=cachemocks -destination=./webook/internal/repository/cache/mocks/interactive.mock.go
+//
+
+// Package cachemocks is a generated GoMock package.
+package cachemocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "gitee.com/geekbang/basic-go/webook/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockInteractiveCache is a mock of InteractiveCache interface.
+type MockInteractiveCache struct {
+	ctrl     *gomock.Controller
+	recorder *MockInteractiveCacheMockRecorder
+}
+
+// MockInteractiveCacheMockRecorder is the mock recorder for MockInteractiveCache.
+type MockInteractiveCacheMockRecorder struct {
+	mock *MockInteractiveCache
+}
+
+// NewMockInteractiveCache creates a new mock instance.
+func NewMockInteractiveCache(ctrl *gomock.Controller) *MockInteractiveCache {
+	mock := &MockInteractiveCache{ctrl: ctrl}
+	mock.recorder = &MockInteractiveCacheMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockInteractiveCache) EXPECT() *MockInteractiveCacheMockRecorder {
+	return m.recorder
+}
+
+// AddReadCntIfPresent mocks base method.
+func (m *MockInteractiveCache) AddReadCntIfPresent(ctx context.Context, biz string, bizId, delta int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddReadCntIfPresent", ctx, biz, bizId, delta)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddReadCntIfPresent indicates an expected call of AddReadCntIfPresent.
+func (mr *MockInteractiveCacheMockRecorder) AddReadCntIfPresent(ctx, biz, bizId, delta any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddReadCntIfPresent", reflect.TypeOf((*MockInteractiveCache)(nil).AddReadCntIfPresent), ctx, biz, bizId, delta)
+}
+
+// DecrCollectCntIfPresent mocks base method.
+func (m *MockInteractiveCache) DecrCollectCntIfPresent(ctx context.Context, biz string, bizId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecrCollectCntIfPresent", ctx, biz, bizId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DecrCollectCntIfPresent indicates an expected call of DecrCollectCntIfPresent.
+func (mr *MockInteractiveCacheMockRecorder) DecrCollectCntIfPresent(ctx, biz, bizId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecrCollectCntIfPresent", reflect.TypeOf((*MockInteractiveCache)(nil).DecrCollectCntIfPresent), ctx, biz, bizId)
+}
+
+// DecrLikeCntIfPresent mocks base method.
+func (m *MockInteractiveCache) DecrLikeCntIfPresent(ctx context.Context, biz string, bizId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecrLikeCntIfPresent", ctx, biz, bizId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DecrLikeCntIfPresent indicates an expected call of DecrLikeCntIfPresent.
+func (mr *MockInteractiveCacheMockRecorder) DecrLikeCntIfPresent(ctx, biz, bizId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecrLikeCntIfPresent", reflect.TypeOf((*MockInteractiveCache)(nil).DecrLikeCntIfPresent), ctx, biz, bizId)
+}
+
+// Get mocks base method.
+func (m *MockInteractiveCache) Get(ctx context.Context, biz string, bizId int64) (domain.Interactive, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, biz, bizId)
+	ret0, _ := ret[0].(domain.Interactive)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockInteractiveCacheMockRecorder) Get(ctx, biz, bizId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockInteractiveCache)(nil).Get), ctx, biz, bizId)
+}
+
+// IncrCollectCntIfPresent mocks base method.
+func (m *MockInteractiveCache) IncrCollectCntIfPresent(ctx context.Context, biz string, bizId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrCollectCntIfPresent", ctx, biz, bizId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncrCollectCntIfPresent indicates an expected call of IncrCollectCntIfPresent.
+func (mr *MockInteractiveCacheMockRecorder) IncrCollectCntIfPresent(ctx, biz, bizId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrCollectCntIfPresent", reflect.TypeOf((*MockInteractiveCache)(nil).IncrCollectCntIfPresent), ctx, biz, bizId)
+}
+
+// IncrLikeCntIfPresent mocks base method.
+func (m *MockInteractiveCache) IncrLikeCntIfPresent(ctx context.Context, biz string, bizId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrLikeCntIfPresent", ctx, biz, bizId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncrLikeCntIfPresent indicates an expected call of IncrLikeCntIfPresent.
+func (mr *MockInteractiveCacheMockRecorder) IncrLikeCntIfPresent(ctx, biz, bizId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrLikeCntIfPresent", reflect.TypeOf((*MockInteractiveCache)(nil).IncrLikeCntIfPresent), ctx, biz, bizId)
+}
+
+// Set mocks base method.
+func (m *MockInteractiveCache) Set(ctx context.Context, biz string, bizId int64, intr domain.Interactive) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Set", ctx, biz, bizId, intr)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Set indicates an expected call of Set.
+func (mr *MockInteractiveCacheMockRecorder) Set(ctx, biz, bizId, intr any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockInteractiveCache)(nil).Set), ctx, biz, bizId, intr)
+}
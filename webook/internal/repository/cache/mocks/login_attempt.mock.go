@@ -0,0 +1,87 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./webook/internal/repository/cache/login_attempt.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./webook/internal/repository/cache/login_attempt.go -package=cachemocks -destination=./webook/internal/repository/cache/mocks/login_attempt.mock.go
+//
+
+// Package cachemocks is a generated GoMock package.
+package cachemocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	domain "gitee.com/geekbang/basic-go/webook/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockLoginAttemptCache is a mock of LoginAttemptCache interface.
+type MockLoginAttemptCache struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoginAttemptCacheMockRecorder
+}
+
+// MockLoginAttemptCacheMockRecorder is the mock recorder for MockLoginAttemptCache.
+type MockLoginAttemptCacheMockRecorder struct {
+	mock *MockLoginAttemptCache
+}
+
+// NewMockLoginAttemptCache creates a new mock instance.
+func NewMockLoginAttemptCache(ctrl *gomock.Controller) *MockLoginAttemptCache {
+	mock := &MockLoginAttemptCache{ctrl: ctrl}
+	mock.recorder = &MockLoginAttemptCacheMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLoginAttemptCache) EXPECT() *MockLoginAttemptCacheMockRecorder {
+	return m.recorder
+}
+
+// Release mocks base method.
+func (m *MockLoginAttemptCache) Release(ctx context.Context, subject string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Release", ctx, subject)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Release indicates an expected call of Release.
+func (mr *MockLoginAttemptCacheMockRecorder) Release(ctx, subject any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Release", reflect.TypeOf((*MockLoginAttemptCache)(nil).Release), ctx, subject)
+}
+
+// Reserve mocks base method.
+func (m *MockLoginAttemptCache) Reserve(ctx context.Context, subjects []string, policies []domain.LoginGuardPolicy, window time.Duration) ([]int64, time.Duration, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reserve", ctx, subjects, policies, window)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(time.Duration)
+	ret2, _ := ret[2].(bool)
+	ret3, _ := ret[3].(error)
+	return ret0, ret1, ret2, ret3
+}
+
+// Reserve indicates an expected call of Reserve.
+func (mr *MockLoginAttemptCacheMockRecorder) Reserve(ctx, subjects, policies, window any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reserve", reflect.TypeOf((*MockLoginAttemptCache)(nil).Reserve), ctx, subjects, policies, window)
+}
+
+// Reset mocks base method.
+func (m *MockLoginAttemptCache) Reset(ctx context.Context, subject string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reset", ctx, subject)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reset indicates an expected call of Reset.
+func (mr *MockLoginAttemptCacheMockRecorder) Reset(ctx, subject any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*MockLoginAttemptCache)(nil).Reset), ctx, subject)
+}
@@ -0,0 +1,85 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./webook/internal/repository/cache/session.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./webook/internal/repository/cache/session.go -package=cachemocks -destination=./webook/internal/repository/cache/mocks/session.mock.go
+//
+
+// Package cachemocks is a generated GoMock package.
+package cachemocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "gitee.com/geekbang/basic-go/webook/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockSessionCache is a mock of SessionCache interface.
+type MockSessionCache struct {
+	ctrl     *gomock.Controller
+	recorder *MockSessionCacheMockRecorder
+}
+
+// MockSessionCacheMockRecorder is the mock recorder for MockSessionCache.
+type MockSessionCacheMockRecorder struct {
+	mock *MockSessionCache
+}
+
+// NewMockSessionCache creates a new mock instance.
+func NewMockSessionCache(ctrl *gomock.Controller) *MockSessionCache {
+	mock := &MockSessionCache{ctrl: ctrl}
+	mock.recorder = &MockSessionCacheMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSessionCache) EXPECT() *MockSessionCacheMockRecorder {
+	return m.recorder
+}
+
+// Add mocks base method.
+func (m *MockSessionCache) Add(ctx context.Context, s domain.Session) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Add", ctx, s)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Add indicates an expected call of Add.
+func (mr *MockSessionCacheMockRecorder) Add(ctx, s any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockSessionCache)(nil).Add), ctx, s)
+}
+
+// Del mocks base method.
+func (m *MockSessionCache) Del(ctx context.Context, uid int64, ssid string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Del", ctx, uid, ssid)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Del indicates an expected call of Del.
+func (mr *MockSessionCacheMockRecorder) Del(ctx, uid, ssid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Del", reflect.TypeOf((*MockSessionCache)(nil).Del), ctx, uid, ssid)
+}
+
+// List mocks base method.
+func (m *MockSessionCache) List(ctx context.Context, uid int64) ([]domain.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, uid)
+	ret0, _ := ret[0].([]domain.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockSessionCacheMockRecorder) List(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockSessionCache)(nil).List), ctx, uid)
+}
@@ -0,0 +1,98 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./webook/internal/repository/cache/two_factor.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./webook/internal/repository/cache/two_factor.go -package=cachemocks -destination=./webook/internal/repository/cache/mocks/two_factor.mock.go
+//
+
+// Package cachemocks is a generated GoMock package.
+package cachemocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockTwoFactorCache is a mock of TwoFactorCache interface.
+type MockTwoFactorCache struct {
+	ctrl     *gomock.Controller
+	recorder *MockTwoFactorCacheMockRecorder
+}
+
+// MockTwoFactorCacheMockRecorder is the mock recorder for MockTwoFactorCache.
+type MockTwoFactorCacheMockRecorder struct {
+	mock *MockTwoFactorCache
+}
+
+// NewMockTwoFactorCache creates a new mock instance.
+func NewMockTwoFactorCache(ctrl *gomock.Controller) *MockTwoFactorCache {
+	mock := &MockTwoFactorCache{ctrl: ctrl}
+	mock.recorder = &MockTwoFactorCacheMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTwoFactorCache) EXPECT() *MockTwoFactorCacheMockRecorder {
+	return m.recorder
+}
+
+// DelChallenge mocks base method.
+func (m *MockTwoFactorCache) DelChallenge(ctx context.Context, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DelChallenge", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DelChallenge indicates an expected call of DelChallenge.
+func (mr *MockTwoFactorCacheMockRecorder) DelChallenge(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DelChallenge", reflect.TypeOf((*MockTwoFactorCache)(nil).DelChallenge), ctx, token)
+}
+
+// GetChallenge mocks base method.
+func (m *MockTwoFactorCache) GetChallenge(ctx context.Context, token string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChallenge", ctx, token)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChallenge indicates an expected call of GetChallenge.
+func (mr *MockTwoFactorCacheMockRecorder) GetChallenge(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChallenge", reflect.TypeOf((*MockTwoFactorCache)(nil).GetChallenge), ctx, token)
+}
+
+// PeekChallenge mocks base method.
+func (m *MockTwoFactorCache) PeekChallenge(ctx context.Context, token string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PeekChallenge", ctx, token)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PeekChallenge indicates an expected call of PeekChallenge.
+func (mr *MockTwoFactorCacheMockRecorder) PeekChallenge(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PeekChallenge", reflect.TypeOf((*MockTwoFactorCache)(nil).PeekChallenge), ctx, token)
+}
+
+// SetChallenge mocks base method.
+func (m *MockTwoFactorCache) SetChallenge(ctx context.Context, token string, uid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetChallenge", ctx, token, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetChallenge indicates an expected call of SetChallenge.
+func (mr *MockTwoFactorCacheMockRecorder) SetChallenge(ctx, token, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetChallenge", reflect.TypeOf((*MockTwoFactorCache)(nil).SetChallenge), ctx, token, uid)
+}
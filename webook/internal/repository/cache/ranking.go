@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+var ErrRankingLocalMiss = errors.New("local ranking cache miss")
+
+type RankingCache interface {
+	Set(ctx context.Context, arts []domain.Article) error
+	Get(ctx context.Context) ([]domain.Article, error)
+}
+
+type RedisRankingCache struct {
+	cmd        redis.Cmdable
+	key        string
+	expiration time.Duration
+}
+
+func NewRedisRankingCache(cmd redis.Cmdable) RankingCache {
+	return &RedisRankingCache{
+		cmd: cmd,
+		key: "ranking:top_n",
+		// 比计算的周期长一点，任务失败一两次也还有数据
+		expiration: time.Minute * 10,
+	}
+}
+
+func (c *RedisRankingCache) Set(ctx context.Context, arts []domain.Article) error {
+	data, err := json.Marshal(toRankingItems(arts))
+	if err != nil {
+		return err
+	}
+	return c.cmd.Set(ctx, c.key, data, c.expiration).Err()
+}
+
+func (c *RedisRankingCache) Get(ctx context.Context) ([]domain.Article, error) {
+	data, err := c.cmd.Get(ctx, c.key).Bytes()
+	if err != nil {
+		return nil, err
+	}
+	var arts []domain.Article
+	err = json.Unmarshal(data, &arts)
+	return arts, err
+}
+
+// RankingLocalCache 本地缓存一份热榜，Redis 崩溃的时候用它兜底
+type RankingLocalCache struct {
+	mu         sync.RWMutex
+	topN       []domain.Article
+	ddl        time.Time
+	expiration time.Duration
+}
+
+func NewRankingLocalCache() *RankingLocalCache {
+	return &RankingLocalCache{
+		expiration: time.Minute * 10,
+	}
+}
+
+func (c *RankingLocalCache) Set(ctx context.Context, arts []domain.Article) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.topN = toRankingItems(arts)
+	c.ddl = time.Now().Add(c.expiration)
+	return nil
+}
+
+func (c *RankingLocalCache) Get(ctx context.Context) ([]domain.Article, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if len(c.topN) == 0 || c.ddl.Before(time.Now()) {
+		return nil, ErrRankingLocalMiss
+	}
+	return c.topN, nil
+}
+
+// ForceGet 不管有没有过期都返回
+func (c *RankingLocalCache) ForceGet(ctx context.Context) ([]domain.Article, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if len(c.topN) == 0 {
+		return nil, ErrRankingLocalMiss
+	}
+	return c.topN, nil
+}
+
+// toRankingItems 热榜只展示摘要
+func toRankingItems(arts []domain.Article) []domain.Article {
+	res := make([]domain.Article, len(arts))
+	for i, art := range arts {
+		art.Content = art.Abstract()
+		res[i] = art
+	}
+	return res
+}
@@ -0,0 +1,34 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRankingLocalCache(t *testing.T) {
+	c := NewRankingLocalCache()
+	ctx := context.Background()
+
+	_, err := c.Get(ctx)
+	assert.Equal(t, ErrRankingLocalMiss, err)
+	_, err = c.ForceGet(ctx)
+	assert.Equal(t, ErrRankingLocalMiss, err)
+
+	err = c.Set(ctx, []domain.Article{{Id: 1, Content: "content"}})
+	assert.NoError(t, err)
+	arts, err := c.Get(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.Article{{Id: 1, Content: "content"}}, arts)
+
+	// 过期之后 Get 拿不到，ForceGet 还能拿到
+	c.ddl = time.Now().Add(-time.Second)
+	_, err = c.Get(ctx)
+	assert.Equal(t, ErrRankingLocalMiss, err)
+	arts, err = c.ForceGet(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.Article{{Id: 1, Content: "content"}}, arts)
+}
@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+type SessionCache interface {
+	Add(ctx context.Context, s domain.Session) error
+	// List 只返回还没有过期的会话
+	List(ctx context.Context, uid int64) ([]domain.Session, error)
+	// Del 会话不属于 uid 的时候返回 false
+	Del(ctx context.Context, uid int64, ssid string) (bool, error)
+}
+
+// RedisSessionCache 一个用户的会话放在一个 hash 里面，field 是 ssid
+type RedisSessionCache struct {
+	cmd redis.Cmdable
+	// expiration 和 refresh token 的有效期一致
+	expiration time.Duration
+}
+
+func NewRedisSessionCache(cmd redis.Cmdable) SessionCache {
+	return &RedisSessionCache{
+		cmd:        cmd,
+		expiration: time.Hour * 24 * 7,
+	}
+}
+
+func (c *RedisSessionCache) Add(ctx context.Context, s domain.Session) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	key := c.key(s.Uid)
+	pipe := c.cmd.TxPipeline()
+	pipe.HSet(ctx, key, s.Ssid, data)
+	// 每次登录都延长，最后一次登录的会话过期之前整个 hash 都不会过期
+	pipe.Expire(ctx, key, c.expiration)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (c *RedisSessionCache) List(ctx context.Context, uid int64) ([]domain.Session, error) {
+	data, err := c.cmd.HGetAll(ctx, c.key(uid)).Result()
+	if err != nil {
+		return nil, err
+	}
+	ddl := time.Now().Add(-c.expiration)
+	res := make([]domain.Session, 0, len(data))
+	for _, val := range data {
+		var s domain.Session
+		if err = json.Unmarshal([]byte(val), &s); err != nil {
+			return nil, err
+		}
+		if s.Ctime.Before(ddl) {
+			continue
+		}
+		res = append(res, s)
+	}
+	return res, nil
+}
+
+func (c *RedisSessionCache) Del(ctx context.Context, uid int64, ssid string) (bool, error) {
+	cnt, err := c.cmd.HDel(ctx, c.key(uid), ssid).Result()
+	return cnt > 0, err
+}
+
+func (c *RedisSessionCache) key(uid int64) string {
+	return fmt.Sprintf("users:sessions:%d", uid)
+}
@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	//go:embed lua/two_factor_challenge.lua
+	luaTwoFactorChallenge string
+	// ErrChallengeNotFound 挑战不存在、过期了，或者尝试次数用完了
+	ErrChallengeNotFound = errors.New("two factor challenge not found")
+)
+
+// TwoFactorCache 密码验证通过之后发一个挑战，凭挑战和第二个因子换真正的 token
+type TwoFactorCache interface {
+	SetChallenge(ctx context.Context, token string, uid int64) error
+	// GetChallenge 每调用一次算一次尝试，用完之后挑战作废
+	GetChallenge(ctx context.Context, token string) (int64, error)
+	// PeekChallenge 只看挑战对应的 uid，不算尝试
+	PeekChallenge(ctx context.Context, token string) (int64, error)
+	DelChallenge(ctx context.Context, token string) error
+}
+
+type RedisTwoFactorCache struct {
+	cmd         redis.Cmdable
+	expiration  time.Duration
+	maxAttempts int
+}
+
+func NewRedisTwoFactorCache(cmd redis.Cmdable) TwoFactorCache {
+	return &RedisTwoFactorCache{
+		cmd:         cmd,
+		expiration:  time.Minute * 5,
+		maxAttempts: 5,
+	}
+}
+
+func (c *RedisTwoFactorCache) SetChallenge(ctx context.Context, token string, uid int64) error {
+	key := c.key(token)
+	pipe := c.cmd.TxPipeline()
+	pipe.HSet(ctx, key, "uid", uid, "attempts", 0)
+	pipe.Expire(ctx, key, c.expiration)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (c *RedisTwoFactorCache) GetChallenge(ctx context.Context, token string) (int64, error) {
+	uid, err := c.cmd.Eval(ctx, luaTwoFactorChallenge, []string{c.key(token)}, c.maxAttempts).Int64()
+	if err != nil {
+		return 0, err
+	}
+	if uid < 0 {
+		return 0, ErrChallengeNotFound
+	}
+	return uid, nil
+}
+
+func (c *RedisTwoFactorCache) PeekChallenge(ctx context.Context, token string) (int64, error) {
+	uid, err := c.cmd.HGet(ctx, c.key(token), "uid").Int64()
+	if err == redis.Nil {
+		return 0, ErrChallengeNotFound
+	}
+	return uid, err
+}
+
+func (c *RedisTwoFactorCache) DelChallenge(ctx context.Context, token string) error {
+	return c.cmd.Del(ctx, c.key(token)).Err()
+}
+
+func (c *RedisTwoFactorCache) key(token string) string {
+	return fmt.Sprintf("users:2fa:challenge:%s", token)
+}
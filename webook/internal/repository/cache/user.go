@@ -15,6 +15,7 @@ var ErrKeyNotExist = redis.Nil
 type UserCache interface{
 	Get(ctx context.Context, uid int64) (domain.User, error)
 	Set(ctx context.Context, du domain.User) error
+	Del(ctx context.Context, uid int64) error
 	Key(uid int64) string
 }
 
@@ -55,6 +56,10 @@ func  (c *RedisUserCache) Set(ctx context.Context, du domain.User) error{
 
 } 
 
+func (c *RedisUserCache) Del(ctx context.Context, uid int64) error {
+	return c.cmd.Del(ctx, c.Key(uid)).Err()
+}
+
 func  (c *RedisUserCache) Key(uid int64) string{
 	return fmt.Sprintf("user:info:%d", uid)
 }
\ No newline at end of file
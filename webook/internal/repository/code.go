@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
 	"gitee.com/geekbang/basic-go/webook/internal/repository/cache"
 )
 
@@ -14,8 +15,8 @@ var (
 //TO DO
 // interface
 type CodeRepository interface{
-	Set(ctx context.Context, biz, phone, code string) error
-	Verify(ctx context.Context, biz, phone, code string) (bool, error)
+	Set(ctx context.Context, channel domain.CodeChannel, biz, target, code string) error
+	Verify(ctx context.Context, channel domain.CodeChannel, biz, target, code string) (bool, error)
 }
 
 type CachedCodeRepository struct {
@@ -28,11 +29,11 @@ func NewCodeRepository(c cache.CodeCache) CodeRepository {
 	}
 }
 
-func (c *CachedCodeRepository) Set(ctx context.Context, biz, phone, code string) error {
-	return c.cache.Set(ctx, biz, phone, code)
+func (c *CachedCodeRepository) Set(ctx context.Context, channel domain.CodeChannel, biz, target, code string) error {
+	return c.cache.Set(ctx, channel, biz, target, code)
 
 }
 
-func (c *CachedCodeRepository) Verify(ctx context.Context, biz, phone, code string) (bool, error) {
-	return c.cache.Verify(ctx, biz, phone, code)
+func (c *CachedCodeRepository) Verify(ctx context.Context, channel domain.CodeChannel, biz, target, code string) (bool, error) {
+	return c.cache.Verify(ctx, channel, biz, target, code)
 }
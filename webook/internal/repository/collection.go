@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/cache"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/dao"
+	"github.com/ecodeclub/ekit/slice"
+)
+
+var ErrCollectionNotFound = dao.ErrCollectionNotFound
+
+type CollectionRepository interface {
+	Create(ctx context.Context, c domain.Collection) (int64, error)
+	Rename(ctx context.Context, uid int64, id int64, name string) error
+	Delete(ctx context.Context, uid int64, id int64) error
+	FindById(ctx context.Context, id int64) (domain.Collection, error)
+	FindByUid(ctx context.Context, uid int64) ([]domain.Collection, error)
+	FindItems(ctx context.Context, uid int64, cid int64, offset int, limit int) ([]domain.CollectionItem, error)
+	RemoveItem(ctx context.Context, uid int64, biz string, bizId int64) error
+}
+
+type CachedCollectionRepository struct {
+	dao       dao.CollectionDAO
+	intrCache cache.InteractiveCache
+}
+
+func NewCollectionRepository(dao dao.CollectionDAO, intrCache cache.InteractiveCache) CollectionRepository {
+	return &CachedCollectionRepository{
+		dao:       dao,
+		intrCache: intrCache,
+	}
+}
+
+func (repo *CachedCollectionRepository) Create(ctx context.Context, c domain.Collection) (int64, error) {
+	return repo.dao.Insert(ctx, dao.Collection{
+		Uid:  c.Uid,
+		Name: c.Name,
+	})
+}
+
+func (repo *CachedCollectionRepository) Rename(ctx context.Context, uid int64, id int64, name string) error {
+	return repo.dao.UpdateName(ctx, uid, id, name)
+}
+
+func (repo *CachedCollectionRepository) Delete(ctx context.Context, uid int64, id int64) error {
+	items, err := repo.dao.Delete(ctx, uid, id)
+	if err != nil {
+		return err
+	}
+	// 收藏夹里面的每一篇文章收藏数都要减一
+	for _, item := range items {
+		err = repo.intrCache.DecrCollectCntIfPresent(ctx, item.Biz, item.BizId)
+		if err != nil {
+			log.Println(err)
+		}
+	}
+	return nil
+}
+
+func (repo *CachedCollectionRepository) FindById(ctx context.Context, id int64) (domain.Collection, error) {
+	c, err := repo.dao.FindById(ctx, id)
+	if err == dao.ErrRecordNotFound {
+		return domain.Collection{}, ErrCollectionNotFound
+	}
+	if err != nil {
+		return domain.Collection{}, err
+	}
+	return repo.toDomain(c), nil
+}
+
+func (repo *CachedCollectionRepository) FindByUid(ctx context.Context, uid int64) ([]domain.Collection, error) {
+	cs, err := repo.dao.FindByUid(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map(cs, func(idx int, src dao.Collection) domain.Collection {
+		return repo.toDomain(src)
+	}), nil
+}
+
+func (repo *CachedCollectionRepository) FindItems(ctx context.Context, uid int64, cid int64, offset int, limit int) ([]domain.CollectionItem, error) {
+	items, err := repo.dao.FindItems(ctx, uid, cid, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map(items, func(idx int, src dao.UserCollectionBiz) domain.CollectionItem {
+		return domain.CollectionItem{
+			Biz:   src.Biz,
+			BizId: src.BizId,
+			Cid:   src.Cid,
+			Ctime: time.UnixMilli(src.Ctime),
+		}
+	}), nil
+}
+
+func (repo *CachedCollectionRepository) RemoveItem(ctx context.Context, uid int64, biz string, bizId int64) error {
+	changed, err := repo.dao.DeleteItem(ctx, uid, biz, bizId)
+	if err != nil || !changed {
+		return err
+	}
+	return repo.intrCache.DecrCollectCntIfPresent(ctx, biz, bizId)
+}
+
+func (repo *CachedCollectionRepository) toDomain(c dao.Collection) domain.Collection {
+	return domain.Collection{
+		Id:    c.Id,
+		Uid:   c.Uid,
+		Name:  c.Name,
+		Ctime: time.UnixMilli(c.Ctime),
+		Utime: time.UnixMilli(c.Utime),
+	}
+}
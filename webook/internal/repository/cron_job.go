@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/dao"
+)
+
+var (
+	ErrNoWaitingJob = dao.ErrNoWaitingJob
+	ErrJobPreempted = dao.ErrJobPreempted
+	ErrDuplicateJob = dao.ErrDuplicateJob
+)
+
+type CronJobRepository interface {
+	Create(ctx context.Context, j domain.CronJob) (int64, error)
+	Preempt(ctx context.Context, expiration time.Duration) (domain.CronJob, error)
+	UpdateUtime(ctx context.Context, id int64, version int64) error
+	Release(ctx context.Context, id int64, version int64, nextTime time.Time) error
+}
+
+type PreemptCronJobRepository struct {
+	dao dao.CronJobDAO
+}
+
+func NewCronJobRepository(dao dao.CronJobDAO) CronJobRepository {
+	return &PreemptCronJobRepository{
+		dao: dao,
+	}
+}
+
+func (repo *PreemptCronJobRepository) Create(ctx context.Context, j domain.CronJob) (int64, error) {
+	return repo.dao.Insert(ctx, dao.CronJob{
+		Name:       j.Name,
+		Executor:   j.Executor,
+		Cfg:        j.Cfg,
+		Expression: j.Expression,
+		NextTime:   j.NextTime.UnixMilli(),
+	})
+}
+
+func (repo *PreemptCronJobRepository) Preempt(ctx context.Context, expiration time.Duration) (domain.CronJob, error) {
+	j, err := repo.dao.Preempt(ctx, expiration)
+	if err != nil {
+		return domain.CronJob{}, err
+	}
+	return domain.CronJob{
+		Id:         j.Id,
+		Name:       j.Name,
+		Executor:   j.Executor,
+		Cfg:        j.Cfg,
+		Expression: j.Expression,
+		NextTime:   time.UnixMilli(j.NextTime),
+		Version:    j.Version,
+	}, nil
+}
+
+func (repo *PreemptCronJobRepository) UpdateUtime(ctx context.Context, id int64, version int64) error {
+	return repo.dao.UpdateUtime(ctx, id, version)
+}
+
+func (repo *PreemptCronJobRepository) Release(ctx context.Context, id int64, version int64, nextTime time.Time) error {
+	return repo.dao.Release(ctx, id, version, nextTime.UnixMilli())
+}
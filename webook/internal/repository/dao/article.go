@@ -0,0 +1,173 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrPossibleIncorrectAuthor = errors.New("article not found or author mismatch")
+
+type ArticleDAO interface {
+	Insert(ctx context.Context, art Article) (int64, error)
+	UpdateById(ctx context.Context, art Article) error
+	// Sync 保存制作库，并且同步到线上库
+	Sync(ctx context.Context, art Article) (int64, error)
+	// SyncStatus 同时修改制作库和线上库的状态
+	SyncStatus(ctx context.Context, uid int64, id int64, status uint8) error
+	GetById(ctx context.Context, id int64) (Article, error)
+	GetByAuthor(ctx context.Context, uid int64, offset int, limit int) ([]Article, error)
+	GetPubById(ctx context.Context, id int64) (PublishedArticle, error)
+	GetPubByIds(ctx context.Context, ids []int64) ([]PublishedArticle, error)
+	// ListPub 按照更新时间倒序分批查询线上库，只查 start 之前更新的
+	ListPub(ctx context.Context, start int64, status uint8, offset int, limit int) ([]PublishedArticle, error)
+}
+
+// Article 制作库，作者编辑的都是这张表
+type Article struct {
+	Id      int64  `gorm:"primaryKey,autoIncrement"`
+	Title   string `gorm:"type=varchar(4096)"`
+	Content string `gorm:"type=BLOB"`
+	// 按照作者查询文章列表，所以要建索引
+	AuthorId int64 `gorm:"index"`
+	Status   uint8
+	Ctime    int64
+	Utime    int64
+}
+
+// PublishedArticle 线上库，读者只能看到这张表，id 和制作库保持一致
+type PublishedArticle Article
+
+type GORMArticleDAO struct {
+	db *gorm.DB
+}
+
+func NewArticleDAO(db *gorm.DB) ArticleDAO {
+	return &GORMArticleDAO{
+		db: db,
+	}
+}
+
+func (dao *GORMArticleDAO) Insert(ctx context.Context, art Article) (int64, error) {
+	now := time.Now().UnixMilli()
+	art.Ctime = now
+	art.Utime = now
+	err := dao.db.WithContext(ctx).Create(&art).Error
+	return art.Id, err
+}
+
+func (dao *GORMArticleDAO) UpdateById(ctx context.Context, art Article) error {
+	now := time.Now().UnixMilli()
+	// author_id 放在 where 里面，防止修改别人的文章
+	res := dao.db.WithContext(ctx).Model(&Article{}).
+		Where("id = ? AND author_id = ?", art.Id, art.AuthorId).
+		Updates(map[string]any{
+			"title":   art.Title,
+			"content": art.Content,
+			"status":  art.Status,
+			"utime":   now,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrPossibleIncorrectAuthor
+	}
+	return nil
+}
+
+func (dao *GORMArticleDAO) Sync(ctx context.Context, art Article) (int64, error) {
+	id := art.Id
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		txDAO := NewArticleDAO(tx)
+		if id > 0 {
+			err = txDAO.UpdateById(ctx, art)
+		} else {
+			id, err = txDAO.Insert(ctx, art)
+		}
+		if err != nil {
+			return err
+		}
+		art.Id = id
+		now := time.Now().UnixMilli()
+		pubArt := PublishedArticle(art)
+		pubArt.Ctime = now
+		pubArt.Utime = now
+		// 第一次发表是 insert，之后都是 update
+		return tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "id"}},
+			DoUpdates: clause.Assignments(map[string]any{
+				"title":   pubArt.Title,
+				"content": pubArt.Content,
+				"status":  pubArt.Status,
+				"utime":   now,
+			}),
+		}).Create(&pubArt).Error
+	})
+	return id, err
+}
+
+func (dao *GORMArticleDAO) SyncStatus(ctx context.Context, uid int64, id int64, status uint8) error {
+	now := time.Now().UnixMilli()
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&Article{}).
+			Where("id = ? AND author_id = ?", id, uid).
+			Updates(map[string]any{
+				"status": status,
+				"utime":  now,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrPossibleIncorrectAuthor
+		}
+		return tx.Model(&PublishedArticle{}).
+			Where("id = ?", id).
+			Updates(map[string]any{
+				"status": status,
+				"utime":  now,
+			}).Error
+	})
+}
+
+func (dao *GORMArticleDAO) GetById(ctx context.Context, id int64) (Article, error) {
+	var res Article
+	err := dao.db.WithContext(ctx).Where("id = ?", id).First(&res).Error
+	return res, err
+}
+
+func (dao *GORMArticleDAO) GetByAuthor(ctx context.Context, uid int64, offset int, limit int) ([]Article, error) {
+	var res []Article
+	err := dao.db.WithContext(ctx).Where("author_id = ?", uid).
+		Order("utime DESC").
+		Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMArticleDAO) GetPubById(ctx context.Context, id int64) (PublishedArticle, error) {
+	var res PublishedArticle
+	err := dao.db.WithContext(ctx).Where("id = ?", id).First(&res).Error
+	return res, err
+}
+
+func (dao *GORMArticleDAO) GetPubByIds(ctx context.Context, ids []int64) ([]PublishedArticle, error) {
+	var res []PublishedArticle
+	err := dao.db.WithContext(ctx).Where("id IN ?", ids).Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMArticleDAO) ListPub(ctx context.Context, start int64, status uint8, offset int, limit int) ([]PublishedArticle, error) {
+	var res []PublishedArticle
+	err := dao.db.WithContext(ctx).
+		Where("utime < ? AND status = ?", start, status).
+		Order("utime DESC").
+		Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, err
+}
@@ -0,0 +1,181 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestGORMArticleDAO_Sync(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(t *testing.T) *sql.DB
+		art     Article
+		wantId  int64
+		wantErr error
+	}{
+		{
+			name: "new article synced",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO `articles` .*").
+					WillReturnResult(sqlmock.NewResult(3, 1))
+				mock.ExpectExec("INSERT INTO `published_articles` .*ON DUPLICATE KEY UPDATE.*").
+					WillReturnResult(sqlmock.NewResult(3, 1))
+				mock.ExpectCommit()
+				return db
+			},
+			art: Article{
+				Title:    "my title",
+				AuthorId: 123,
+			},
+			wantId: 3,
+		},
+		{
+			name: "existing article synced",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `articles` .*").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO `published_articles` .*ON DUPLICATE KEY UPDATE.*").
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectCommit()
+				return db
+			},
+			art: Article{
+				Id:       3,
+				Title:    "my title",
+				AuthorId: 123,
+			},
+			wantId: 3,
+		},
+		{
+			name: "not the author",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `articles` .*").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+				return db
+			},
+			art: Article{
+				Id:       3,
+				AuthorId: 456,
+			},
+			wantId:  3,
+			wantErr: ErrPossibleIncorrectAuthor,
+		},
+		{
+			name: "sync to published table failed",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `articles` .*").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO `published_articles` .*").
+					WillReturnError(errors.New("db error"))
+				mock.ExpectRollback()
+				return db
+			},
+			art: Article{
+				Id:       3,
+				AuthorId: 123,
+			},
+			wantId:  3,
+			wantErr: errors.New("db error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sqlDB := tc.mock(t)
+			db, err := gorm.Open(mysql.New(
+				mysql.Config{
+					Conn:                      sqlDB,
+					SkipInitializeWithVersion: true,
+				}),
+				&gorm.Config{
+					DisableAutomaticPing:   true,
+					SkipDefaultTransaction: true,
+				})
+			require.NoError(t, err)
+			dao := NewArticleDAO(db)
+			id, err := dao.Sync(context.Background(), tc.art)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantId, id)
+		})
+	}
+}
+
+func TestGORMArticleDAO_SyncStatus(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(t *testing.T) *sql.DB
+		uid     int64
+		id      int64
+		wantErr error
+	}{
+		{
+			name: "both tables updated",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `articles` .*").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE `published_articles` .*").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				return db
+			},
+			uid: 123,
+			id:  1,
+		},
+		{
+			name: "not the author",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `articles` .*").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+				return db
+			},
+			uid:     456,
+			id:      1,
+			wantErr: ErrPossibleIncorrectAuthor,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sqlDB := tc.mock(t)
+			db, err := gorm.Open(mysql.New(
+				mysql.Config{
+					Conn:                      sqlDB,
+					SkipInitializeWithVersion: true,
+				}),
+				&gorm.Config{
+					DisableAutomaticPing:   true,
+					SkipDefaultTransaction: true,
+				})
+			require.NoError(t, err)
+			dao := NewArticleDAO(db)
+			err = dao.SyncStatus(context.Background(), tc.uid, tc.id, 3)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
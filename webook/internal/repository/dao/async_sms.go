@@ -34,6 +34,10 @@ type AsyncSmsDAO interface {
 	GetWaitingSMS(ctx context.Context) (AsyncSms, error)
 	MarkSuccess(ctx context.Context, id int64) error
 	MarkFailed(ctx context.Context, id int64) error
+	// ListByStatus 管理后台用，按 id 倒序
+	ListByStatus(ctx context.Context, status uint8, offset int, limit int) ([]AsyncSms, error)
+	// CountByStatus key 是状态，没有记录的状态不会出现
+	CountByStatus(ctx context.Context) (map[uint8]int64, error)
 }
 
 const (
@@ -53,7 +57,9 @@ func NewGORMAsyncSmsDAO(db *gorm.DB) AsyncSmsDAO {
 }
 
 func (g *GORMAsyncSmsDAO) Insert(ctx context.Context, s AsyncSms) error {
-	return g.db.Create(&s).Error
+	// utime 不设置，不然要等一分钟才会被抢占
+	s.Ctime = time.Now().UnixMilli()
+	return g.db.WithContext(ctx).Create(&s).Error
 }
 
 func (g *GORMAsyncSmsDAO) GetWaitingSMS(ctx context.Context) (AsyncSms, error) {
@@ -102,3 +108,30 @@ func (g *GORMAsyncSmsDAO) MarkFailed(ctx context.Context, id int64) error {
 			"status": asyncStatusFailed,
 		}).Error
 }
+
+func (g *GORMAsyncSmsDAO) ListByStatus(ctx context.Context, status uint8,
+	offset int, limit int) ([]AsyncSms, error) {
+	var res []AsyncSms
+	err := g.db.WithContext(ctx).Where("status = ?", status).
+		Order("id DESC").Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (g *GORMAsyncSmsDAO) CountByStatus(ctx context.Context) (map[uint8]int64, error) {
+	var rows []struct {
+		Status uint8
+		Cnt    int64
+	}
+	err := g.db.WithContext(ctx).Model(&AsyncSms{}).
+		Select("status, COUNT(*) AS cnt").Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[uint8]int64, len(rows))
+	for _, r := range rows {
+		res[r.Status] = r.Cnt
+	}
+	return res, nil
+}
@@ -0,0 +1,147 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrCollectionNotFound = errors.New("collection not found or owner mismatch")
+
+type CollectionDAO interface {
+	Insert(ctx context.Context, c Collection) (int64, error)
+	UpdateName(ctx context.Context, uid int64, id int64, name string) error
+	// Delete 删除收藏夹以及里面的收藏，返回被删除的收藏，计数在同一个事务里面减掉
+	Delete(ctx context.Context, uid int64, id int64) ([]UserCollectionBiz, error)
+	FindById(ctx context.Context, id int64) (Collection, error)
+	FindByUid(ctx context.Context, uid int64) ([]Collection, error)
+	FindItems(ctx context.Context, uid int64, cid int64, offset int, limit int) ([]UserCollectionBiz, error)
+	// DeleteItem 取消收藏，没有收藏过返回 false
+	DeleteItem(ctx context.Context, uid int64, biz string, bizId int64) (bool, error)
+}
+
+type Collection struct {
+	Id    int64  `gorm:"primaryKey,autoIncrement"`
+	Uid   int64  `gorm:"index"`
+	Name  string `gorm:"type:varchar(256)"`
+	Ctime int64
+	Utime int64
+}
+
+type GORMCollectionDAO struct {
+	db *gorm.DB
+}
+
+func NewCollectionDAO(db *gorm.DB) CollectionDAO {
+	return &GORMCollectionDAO{
+		db: db,
+	}
+}
+
+func (dao *GORMCollectionDAO) Insert(ctx context.Context, c Collection) (int64, error) {
+	now := time.Now().UnixMilli()
+	c.Ctime = now
+	c.Utime = now
+	err := dao.db.WithContext(ctx).Create(&c).Error
+	return c.Id, err
+}
+
+func (dao *GORMCollectionDAO) UpdateName(ctx context.Context, uid int64, id int64, name string) error {
+	res := dao.db.WithContext(ctx).Model(&Collection{}).
+		Where("id = ? AND uid = ?", id, uid).
+		Updates(map[string]any{
+			"name":  name,
+			"utime": time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrCollectionNotFound
+	}
+	return nil
+}
+
+func (dao *GORMCollectionDAO) Delete(ctx context.Context, uid int64, id int64) ([]UserCollectionBiz, error) {
+	var items []UserCollectionBiz
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("id = ? AND uid = ?", id, uid).Delete(&Collection{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrCollectionNotFound
+		}
+		err := tx.Where("uid = ? AND cid = ?", uid, id).Find(&items).Error
+		if err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return nil
+		}
+		err = tx.Where("uid = ? AND cid = ?", uid, id).Delete(&UserCollectionBiz{}).Error
+		if err != nil {
+			return err
+		}
+		now := time.Now().UnixMilli()
+		for _, item := range items {
+			err = decrCollectCnt(tx, item.Biz, item.BizId, now)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return items, err
+}
+
+func (dao *GORMCollectionDAO) FindById(ctx context.Context, id int64) (Collection, error) {
+	var res Collection
+	err := dao.db.WithContext(ctx).Where("id = ?", id).First(&res).Error
+	return res, err
+}
+
+func (dao *GORMCollectionDAO) FindByUid(ctx context.Context, uid int64) ([]Collection, error) {
+	var res []Collection
+	err := dao.db.WithContext(ctx).Where("uid = ?", uid).
+		Order("id ASC").Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMCollectionDAO) FindItems(ctx context.Context, uid int64, cid int64, offset int, limit int) ([]UserCollectionBiz, error) {
+	var res []UserCollectionBiz
+	err := dao.db.WithContext(ctx).
+		Where("uid = ? AND cid = ?", uid, cid).
+		Order("ctime DESC").
+		Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMCollectionDAO) DeleteItem(ctx context.Context, uid int64, biz string, bizId int64) (bool, error) {
+	changed := false
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("uid = ? AND biz = ? AND biz_id = ?", uid, biz, bizId).
+			Delete(&UserCollectionBiz{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		changed = true
+		return decrCollectCnt(tx, biz, bizId, time.Now().UnixMilli())
+	})
+	return changed, err
+}
+
+func decrCollectCnt(tx *gorm.DB, biz string, bizId int64, now int64) error {
+	return tx.Model(&Interactive{}).
+		Where("biz = ? AND biz_id = ? AND collect_cnt > 0", biz, bizId).
+		Updates(map[string]any{
+			"collect_cnt": gorm.Expr("`collect_cnt` - 1"),
+			"utime":       now,
+		}).Error
+}
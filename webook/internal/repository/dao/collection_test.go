@@ -0,0 +1,84 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestGORMCollectionDAO_Delete(t *testing.T) {
+	testCases := []struct {
+		name      string
+		mock      func(t *testing.T) *sql.DB
+		wantItems []UserCollectionBiz
+		wantErr   error
+	}{
+		{
+			name: "items removed and counts decremented",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM `collections` .*").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				rows := sqlmock.NewRows([]string{"id", "uid", "biz_id", "biz", "cid"}).
+					AddRow(1, 123, 10, "article", 1).
+					AddRow(2, 123, 11, "article", 1)
+				mock.ExpectQuery("SELECT \\* FROM `user_collection_bizs` .*").
+					WillReturnRows(rows)
+				mock.ExpectExec("DELETE FROM `user_collection_bizs` .*").
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec("UPDATE `interactives` SET `collect_cnt`=`collect_cnt` - 1.*").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE `interactives` SET `collect_cnt`=`collect_cnt` - 1.*").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				return db
+			},
+			wantItems: []UserCollectionBiz{
+				{Id: 1, Uid: 123, BizId: 10, Biz: "article", Cid: 1},
+				{Id: 2, Uid: 123, BizId: 11, Biz: "article", Cid: 1},
+			},
+		},
+		{
+			name: "not the owner",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM `collections` .*").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+				return db
+			},
+			wantErr: ErrCollectionNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sqlDB := tc.mock(t)
+			db, err := gorm.Open(mysql.New(
+				mysql.Config{
+					Conn:                      sqlDB,
+					SkipInitializeWithVersion: true,
+				}),
+				&gorm.Config{
+					DisableAutomaticPing:   true,
+					SkipDefaultTransaction: true,
+				})
+			require.NoError(t, err)
+			dao := NewCollectionDAO(db)
+			items, err := dao.Delete(context.Background(), 123, 1)
+			assert.Equal(t, tc.wantErr, err)
+			if tc.wantErr == nil {
+				assert.Equal(t, tc.wantItems, items)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNoWaitingJob = errors.New("no job to preempt")
+	// ErrJobPreempted 任务已经被别的节点抢走了，通常是因为续约不及时
+	ErrJobPreempted = errors.New("job preempted by others")
+	ErrDuplicateJob = errors.New("job name already exists")
+)
+
+type CronJobDAO interface {
+	// Insert 新任务是等待状态，名字重复返回 ErrDuplicateJob
+	Insert(ctx context.Context, j CronJob) (int64, error)
+	// Preempt 抢占一个到期的任务，或者一个续约超时的任务
+	Preempt(ctx context.Context, expiration time.Duration) (CronJob, error)
+	// UpdateUtime 续约，version 对不上说明任务被别人抢走了
+	UpdateUtime(ctx context.Context, id int64, version int64) error
+	// Release 释放任务并且设置下一次执行时间
+	Release(ctx context.Context, id int64, version int64, nextTime int64) error
+}
+
+// CronJob 数据库里面的定时任务。
+// status 是 running 但是 utime 太久没有更新的，说明抢占的节点挂了，可以再次抢占。
+type CronJob struct {
+	Id         int64  `gorm:"primaryKey,autoIncrement"`
+	Name       string `gorm:"type:varchar(128);unique"`
+	Executor   string
+	Cfg        string
+	Expression string
+	// Version 乐观锁，每次抢占加一
+	Version int64
+	// 抢占的时候按照 status + next_time 查
+	NextTime int64 `gorm:"index:idx_status_next_time"`
+	Status   uint8 `gorm:"index:idx_status_next_time"`
+	Ctime    int64
+	Utime    int64
+}
+
+const (
+	cronJobStatusWaiting uint8 = iota
+	cronJobStatusRunning
+	// cronJobStatusPaused 暂停的任务不会被抢占
+	cronJobStatusPaused
+)
+
+type GORMCronJobDAO struct {
+	db *gorm.DB
+}
+
+func NewCronJobDAO(db *gorm.DB) CronJobDAO {
+	return &GORMCronJobDAO{
+		db: db,
+	}
+}
+
+func (dao *GORMCronJobDAO) Insert(ctx context.Context, j CronJob) (int64, error) {
+	now := time.Now().UnixMilli()
+	j.Status = cronJobStatusWaiting
+	j.Ctime = now
+	j.Utime = now
+	err := dao.db.WithContext(ctx).Create(&j).Error
+	if isDuplicate(err) {
+		return 0, ErrDuplicateJob
+	}
+	return j.Id, err
+}
+
+func (dao *GORMCronJobDAO) Preempt(ctx context.Context, expiration time.Duration) (CronJob, error) {
+	db := dao.db.WithContext(ctx)
+	for {
+		now := time.Now().UnixMilli()
+		var j CronJob
+		err := db.Where("(status = ? AND next_time <= ?) OR (status = ? AND utime < ?)",
+			cronJobStatusWaiting, now,
+			cronJobStatusRunning, now-expiration.Milliseconds()).
+			First(&j).Error
+		if err == gorm.ErrRecordNotFound {
+			return CronJob{}, ErrNoWaitingJob
+		}
+		if err != nil {
+			return CronJob{}, err
+		}
+		// 不用 SELECT FOR UPDATE，用版本号做乐观锁，
+		// 两个节点同时查到同一个任务的时候，只有一个能更新成功
+		res := db.Model(&CronJob{}).
+			Where("id = ? AND version = ?", j.Id, j.Version).
+			Updates(map[string]any{
+				"status":  cronJobStatusRunning,
+				"version": j.Version + 1,
+				"utime":   now,
+			})
+		if res.Error != nil {
+			return CronJob{}, res.Error
+		}
+		if res.RowsAffected == 0 {
+			// 被别人抢了，再找下一个
+			continue
+		}
+		j.Status = cronJobStatusRunning
+		j.Version++
+		j.Utime = now
+		return j, nil
+	}
+}
+
+func (dao *GORMCronJobDAO) UpdateUtime(ctx context.Context, id int64, version int64) error {
+	res := dao.db.WithContext(ctx).Model(&CronJob{}).
+		Where("id = ? AND version = ? AND status = ?", id, version, cronJobStatusRunning).
+		Update("utime", time.Now().UnixMilli())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrJobPreempted
+	}
+	return nil
+}
+
+func (dao *GORMCronJobDAO) Release(ctx context.Context, id int64, version int64, nextTime int64) error {
+	res := dao.db.WithContext(ctx).Model(&CronJob{}).
+		Where("id = ? AND version = ? AND status = ?", id, version, cronJobStatusRunning).
+		Updates(map[string]any{
+			"status":    cronJobStatusWaiting,
+			"next_time": nextTime,
+			"utime":     time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrJobPreempted
+	}
+	return nil
+}
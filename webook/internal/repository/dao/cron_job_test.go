@@ -0,0 +1,148 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	mysqlDriver "github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestGORMCronJobDAO_Preempt(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(t *testing.T) *sql.DB
+		wantJob CronJob
+		wantErr error
+	}{
+		{
+			name: "preempted",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				rows := sqlmock.NewRows([]string{"id", "name", "version", "status"}).
+					AddRow(1, "ranking", 3, 0)
+				mock.ExpectQuery("SELECT \\* FROM `cron_jobs` .*").WillReturnRows(rows)
+				mock.ExpectExec("UPDATE `cron_jobs` SET .* WHERE id = \\? AND version = \\?").
+					WithArgs(1, sqlmock.AnyArg(), 4, 1, 3).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				return db
+			},
+			wantJob: CronJob{Id: 1, Name: "ranking", Version: 4, Status: cronJobStatusRunning},
+		},
+		{
+			name: "lost the race, preempt next one",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectQuery("SELECT \\* FROM `cron_jobs` .*").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "version", "status"}).
+						AddRow(1, "ranking", 3, 0))
+				// 别的节点先改了版本号
+				mock.ExpectExec("UPDATE `cron_jobs` SET .*").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery("SELECT \\* FROM `cron_jobs` .*").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "version", "status"}).
+						AddRow(2, "cleanup", 7, 1))
+				mock.ExpectExec("UPDATE `cron_jobs` SET .*").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				return db
+			},
+			wantJob: CronJob{Id: 2, Name: "cleanup", Version: 8, Status: cronJobStatusRunning},
+		},
+		{
+			name: "no job",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectQuery("SELECT \\* FROM `cron_jobs` .*").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				return db
+			},
+			wantErr: ErrNoWaitingJob,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sqlDB := tc.mock(t)
+			db, err := gorm.Open(mysql.New(
+				mysql.Config{
+					Conn:                      sqlDB,
+					SkipInitializeWithVersion: true,
+				}),
+				&gorm.Config{
+					DisableAutomaticPing:   true,
+					SkipDefaultTransaction: true,
+				})
+			require.NoError(t, err)
+			dao := NewCronJobDAO(db)
+			j, err := dao.Preempt(context.Background(), time.Minute)
+			assert.Equal(t, tc.wantErr, err)
+			if tc.wantErr == nil {
+				j.Utime = 0
+				assert.Equal(t, tc.wantJob, j)
+			}
+		})
+	}
+}
+
+func TestGORMCronJobDAO_Insert(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(t *testing.T) *sql.DB
+		wantId  int64
+		wantErr error
+	}{
+		{
+			name: "inserted",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectExec("INSERT INTO `cron_jobs` .*").
+					WillReturnResult(sqlmock.NewResult(3, 1))
+				return db
+			},
+			wantId: 3,
+		},
+		{
+			name: "duplicate name",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectExec("INSERT INTO `cron_jobs` .*").
+					WillReturnError(&mysqlDriver.MySQLError{Number: 1062})
+				return db
+			},
+			wantErr: ErrDuplicateJob,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sqlDB := tc.mock(t)
+			db, err := gorm.Open(mysql.New(
+				mysql.Config{
+					Conn:                      sqlDB,
+					SkipInitializeWithVersion: true,
+				}),
+				&gorm.Config{
+					DisableAutomaticPing:   true,
+					SkipDefaultTransaction: true,
+				})
+			require.NoError(t, err)
+			dao := NewCronJobDAO(db)
+			id, err := dao.Insert(context.Background(), CronJob{
+				Name:       "cleanup",
+				Executor:   "local",
+				Expression: "0 0 * * * ?",
+			})
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantId, id)
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+// ErrDuplicateIdentity 第三方账号已经绑定了别的用户
+var ErrDuplicateIdentity = errors.New("第三方账号冲突")
+
+type IdentityDAO interface {
+	FindByProvider(ctx context.Context, provider string, subject string) (UserIdentity, error)
+	FindByUid(ctx context.Context, uid int64) ([]UserIdentity, error)
+	// InsertWithUser 第三方第一次登录，同一个事务里面创建用户和身份
+	InsertWithUser(ctx context.Context, u User, identity UserIdentity) (int64, error)
+	// Bind 覆盖这个用户在同一个平台上原来的身份
+	Bind(ctx context.Context, identity UserIdentity, audit UserAuditLog) error
+	Unbind(ctx context.Context, uid int64, provider string, audit UserAuditLog) error
+}
+
+// UserIdentity 第三方登录的身份，替代 User 上面的微信字段
+type UserIdentity struct {
+	Id       int64  `gorm:"primaryKey,autoIncrement"`
+	Uid      int64  `gorm:"uniqueIndex:uid_provider"`
+	Provider string `gorm:"type:varchar(64);uniqueIndex:provider_subject;uniqueIndex:uid_provider"`
+	Subject  string `gorm:"type:varchar(255);uniqueIndex:provider_subject"`
+	UnionId  string `gorm:"type:varchar(255)"`
+	Email    string `gorm:"type:varchar(255)"`
+	Ctime    int64
+	Utime    int64
+}
+
+type GORMIdentityDAO struct {
+	db *gorm.DB
+}
+
+func NewIdentityDAO(db *gorm.DB) IdentityDAO {
+	return &GORMIdentityDAO{
+		db: db,
+	}
+}
+
+func (dao *GORMIdentityDAO) FindByProvider(ctx context.Context,
+	provider string, subject string) (UserIdentity, error) {
+	var res UserIdentity
+	err := dao.db.WithContext(ctx).
+		Where("provider = ? AND subject = ?", provider, subject).
+		First(&res).Error
+	return res, err
+}
+
+func (dao *GORMIdentityDAO) FindByUid(ctx context.Context, uid int64) ([]UserIdentity, error) {
+	var res []UserIdentity
+	err := dao.db.WithContext(ctx).Where("uid = ?", uid).
+		Order("id").Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMIdentityDAO) InsertWithUser(ctx context.Context,
+	u User, identity UserIdentity) (int64, error) {
+	now := time.Now().UnixMilli()
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		u.CreateAt = now
+		u.UpdateAt = now
+		if err := tx.Create(&u).Error; err != nil {
+			return err
+		}
+		identity.Uid = u.Id
+		identity.Ctime = now
+		identity.Utime = now
+		return tx.Create(&identity).Error
+	})
+	if isDuplicate(err) {
+		return 0, ErrDuplicateIdentity
+	}
+	return u.Id, err
+}
+
+func (dao *GORMIdentityDAO) Bind(ctx context.Context,
+	identity UserIdentity, audit UserAuditLog) error {
+	now := time.Now().UnixMilli()
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("uid = ? AND provider = ?", identity.Uid, identity.Provider).
+			Delete(&UserIdentity{}).Error
+		if err != nil {
+			return err
+		}
+		identity.Ctime = now
+		identity.Utime = now
+		if err = tx.Create(&identity).Error; err != nil {
+			return err
+		}
+		audit.Uid = identity.Uid
+		audit.Ctime = now
+		return tx.Create(&audit).Error
+	})
+	if isDuplicate(err) {
+		return ErrDuplicateIdentity
+	}
+	return err
+}
+
+func (dao *GORMIdentityDAO) Unbind(ctx context.Context, uid int64,
+	provider string, audit UserAuditLog) error {
+	now := time.Now().UnixMilli()
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("uid = ? AND provider = ?", uid, provider).
+			Delete(&UserIdentity{}).Error
+		if err != nil {
+			return err
+		}
+		audit.Uid = uid
+		audit.Ctime = now
+		return tx.Create(&audit).Error
+	})
+}
+
+func isDuplicate(err error) bool {
+	var me *mysql.MySQLError
+	const duplicateErr uint16 = 1062
+	return errors.As(err, &me) && me.Number == duplicateErr
+}
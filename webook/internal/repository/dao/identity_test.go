@@ -0,0 +1,76 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	mysqlDriver "github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestGORMIdentityDAO_Bind(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(t *testing.T) *sql.DB
+		wantErr error
+	}{
+		{
+			name: "replace old identity",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM `user_identities` WHERE .*").
+					WithArgs(int64(123), "github").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO `user_identities` .*").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO `user_audit_logs` .*").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+				return db
+			},
+		},
+		{
+			name: "bound to another user",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM `user_identities` WHERE .*").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT INTO `user_identities` .*").
+					WillReturnError(&mysqlDriver.MySQLError{Number: 1062})
+				mock.ExpectRollback()
+				return db
+			},
+			wantErr: ErrDuplicateIdentity,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sqlDB := tc.mock(t)
+			db, err := gorm.Open(mysql.New(
+				mysql.Config{
+					Conn:                      sqlDB,
+					SkipInitializeWithVersion: true,
+				}),
+				&gorm.Config{
+					DisableAutomaticPing:   true,
+					SkipDefaultTransaction: true,
+				})
+			assert.NoError(t, err)
+			dao := NewIdentityDAO(db)
+			err = dao.Bind(context.Background(), UserIdentity{
+				Uid:      123,
+				Provider: "github",
+				Subject:  "1234",
+			}, UserAuditLog{Action: "bind_identity", After: "github:1234"})
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
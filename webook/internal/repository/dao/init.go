@@ -3,5 +3,24 @@ package dao
 import "gorm.io/gorm"
 
 func InitTables(db *gorm.DB) error {
-	return db.AutoMigrate(&User{})
+	err := db.AutoMigrate(&User{}, &Article{}, &PublishedArticle{},
+		&Interactive{}, &UserLikeBiz{}, &UserCollectionBiz{}, &Collection{},
+		&CronJob{}, &UserAuditLog{}, &UserTwoFactor{}, &UserRecoveryCode{},
+		&UserIdentity{}, &WechatToken{}, &AsyncSms{}, &UserRole{}, &RolePermission{})
+	if err != nil {
+		return err
+	}
+	return migrateWechatIdentities(db)
+}
+
+// migrateWechatIdentities 把 users 上面老的微信字段搬到 user_identities，
+// 可以重复执行。确认没问题之后再手动删掉那两列
+func migrateWechatIdentities(db *gorm.DB) error {
+	if !db.Migrator().HasColumn(&User{}, "wechat_open_id") {
+		return nil
+	}
+	return db.Exec("INSERT IGNORE INTO user_identities " +
+		"(uid, provider, subject, union_id, email, ctime, utime) " +
+		"SELECT id, 'wechat', wechat_open_id, IFNULL(wechat_union_id, ''), '', create_at, update_at " +
+		"FROM users WHERE wechat_open_id IS NOT NULL").Error
 }
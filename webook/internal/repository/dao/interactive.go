@@ -0,0 +1,282 @@
+package dao
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type InteractiveDAO interface {
+	// BatchIncrReadCnt 一条 UPDATE 语句更新一批阅读计数
+	BatchIncrReadCnt(ctx context.Context, deltas []ReadCntDelta) error
+	// InsertLikeInfo 点赞，返回值表示点赞状态是否发生了变化，重复点赞返回 false
+	InsertLikeInfo(ctx context.Context, biz string, bizId int64, uid int64) (bool, error)
+	// DeleteLikeInfo 取消点赞，没有点赞过返回 false
+	DeleteLikeInfo(ctx context.Context, biz string, bizId int64, uid int64) (bool, error)
+	// InsertCollectionBiz 收藏，已经收藏过的话移动到新的收藏夹并返回 false
+	InsertCollectionBiz(ctx context.Context, cb UserCollectionBiz) (bool, error)
+	GetLikeInfo(ctx context.Context, biz string, bizId int64, uid int64) (UserLikeBiz, error)
+	GetCollectionInfo(ctx context.Context, biz string, bizId int64, uid int64) (UserCollectionBiz, error)
+	Get(ctx context.Context, biz string, bizId int64) (Interactive, error)
+	GetByIds(ctx context.Context, biz string, ids []int64) ([]Interactive, error)
+}
+
+// Interactive 计数表，biz + biz_id 唯一
+type Interactive struct {
+	Id         int64  `gorm:"primaryKey,autoIncrement"`
+	BizId      int64  `gorm:"uniqueIndex:biz_type_id"`
+	Biz        string `gorm:"type:varchar(128);uniqueIndex:biz_type_id"`
+	ReadCnt    int64
+	LikeCnt    int64
+	CollectCnt int64
+	Ctime      int64
+	Utime      int64
+}
+
+// UserLikeBiz 用户点赞记录，取消点赞是软删除
+type UserLikeBiz struct {
+	Id     int64  `gorm:"primaryKey,autoIncrement"`
+	Uid    int64  `gorm:"uniqueIndex:uid_biz_type_id"`
+	BizId  int64  `gorm:"uniqueIndex:uid_biz_type_id"`
+	Biz    string `gorm:"type:varchar(128);uniqueIndex:uid_biz_type_id"`
+	Status uint8
+	Ctime  int64
+	Utime  int64
+}
+
+// UserCollectionBiz 用户收藏记录，Cid 是收藏夹
+type UserCollectionBiz struct {
+	Id    int64  `gorm:"primaryKey,autoIncrement"`
+	Uid   int64  `gorm:"uniqueIndex:uid_biz_type_id"`
+	BizId int64  `gorm:"uniqueIndex:uid_biz_type_id"`
+	Biz   string `gorm:"type:varchar(128);uniqueIndex:uid_biz_type_id"`
+	Cid   int64  `gorm:"index"`
+	Ctime int64
+	Utime int64
+}
+
+type ReadCntDelta struct {
+	Biz   string
+	BizId int64
+	Delta int64
+}
+
+const (
+	likeStatusInvalid uint8 = iota
+	likeStatusValid
+)
+
+type GORMInteractiveDAO struct {
+	db *gorm.DB
+}
+
+func NewInteractiveDAO(db *gorm.DB) InteractiveDAO {
+	return &GORMInteractiveDAO{
+		db: db,
+	}
+}
+
+func (dao *GORMInteractiveDAO) BatchIncrReadCnt(ctx context.Context, deltas []ReadCntDelta) error {
+	if len(deltas) == 0 {
+		return nil
+	}
+	now := time.Now().UnixMilli()
+	rows := make([]Interactive, 0, len(deltas))
+	keys := make([][]any, 0, len(deltas))
+	var sb strings.Builder
+	args := make([]any, 0, len(deltas)*3)
+	sb.WriteString("`read_cnt` + CASE")
+	for _, d := range deltas {
+		rows = append(rows, Interactive{
+			Biz:   d.Biz,
+			BizId: d.BizId,
+			Ctime: now,
+			Utime: now,
+		})
+		keys = append(keys, []any{d.Biz, d.BizId})
+		sb.WriteString(" WHEN `biz` = ? AND `biz_id` = ? THEN ?")
+		args = append(args, d.Biz, d.BizId, d.Delta)
+	}
+	sb.WriteString(" ELSE 0 END")
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 先把还没有计数的行插进去，后面的 UPDATE 才能命中
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&Interactive{}).
+			Where("(`biz`, `biz_id`) IN ?", keys).
+			Updates(map[string]any{
+				"read_cnt": gorm.Expr(sb.String(), args...),
+				"utime":    now,
+			}).Error
+	})
+}
+
+func (dao *GORMInteractiveDAO) InsertLikeInfo(ctx context.Context, biz string, bizId int64, uid int64) (bool, error) {
+	now := time.Now().UnixMilli()
+	changed := false
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 用 upsert，第一次点赞同时来两个请求也不会撞唯一索引。
+		// utime 要写在 status 前面，算它的时候 status 还是旧的值。
+		// 影响行数：插入是 1，取消过又点赞是 2，已经点赞过了两列都不变是 0
+		res := tx.Clauses(clause.OnConflict{
+			DoUpdates: clause.Set{
+				{
+					Column: clause.Column{Name: "utime"},
+					Value:  gorm.Expr("IF(`status` = ?, `utime`, ?)", likeStatusValid, now),
+				},
+				{
+					Column: clause.Column{Name: "status"},
+					Value:  likeStatusValid,
+				},
+			},
+		}).Create(&UserLikeBiz{
+			Uid:    uid,
+			BizId:  bizId,
+			Biz:    biz,
+			Status: likeStatusValid,
+			Ctime:  now,
+			Utime:  now,
+		})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		changed = true
+		return tx.Clauses(clause.OnConflict{
+			DoUpdates: clause.Assignments(map[string]any{
+				"like_cnt": gorm.Expr("`like_cnt` + 1"),
+				"utime":    now,
+			}),
+		}).Create(&Interactive{
+			Biz:     biz,
+			BizId:   bizId,
+			LikeCnt: 1,
+			Ctime:   now,
+			Utime:   now,
+		}).Error
+	})
+	return changed, err
+}
+
+func (dao *GORMInteractiveDAO) DeleteLikeInfo(ctx context.Context, biz string, bizId int64, uid int64) (bool, error) {
+	now := time.Now().UnixMilli()
+	changed := false
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&UserLikeBiz{}).
+			Where("uid = ? AND biz_id = ? AND biz = ? AND status = ?",
+				uid, bizId, biz, likeStatusValid).
+			Updates(map[string]any{
+				"status": likeStatusInvalid,
+				"utime":  now,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			// 没有点赞过，或者已经取消了
+			return nil
+		}
+		changed = true
+		return tx.Model(&Interactive{}).
+			Where("biz = ? AND biz_id = ?", biz, bizId).
+			Updates(map[string]any{
+				"like_cnt": gorm.Expr("`like_cnt` - 1"),
+				"utime":    now,
+			}).Error
+	})
+	return changed, err
+}
+
+func (dao *GORMInteractiveDAO) InsertCollectionBiz(ctx context.Context, cb UserCollectionBiz) (bool, error) {
+	now := time.Now().UnixMilli()
+	cb.Ctime = now
+	cb.Utime = now
+	changed := false
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if cb.Cid > 0 {
+			// 只能收藏到自己的收藏夹。锁住收藏夹，删除收藏夹要等这边提交了才能继续，
+			// 不会留下指向已经删掉的收藏夹的收藏
+			var c Collection
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("id = ? AND uid = ?", cb.Cid, cb.Uid).
+				First(&c).Error
+			if err == gorm.ErrRecordNotFound {
+				return ErrCollectionNotFound
+			}
+			if err != nil {
+				return err
+			}
+		}
+		err := tx.Create(&cb).Error
+		if me, ok := err.(*mysql.MySQLError); ok {
+			const duplicateErr uint16 = 1062
+			if me.Number == duplicateErr {
+				// 已经收藏过了，换一个收藏夹，计数不变
+				return tx.Model(&UserCollectionBiz{}).
+					Where("uid = ? AND biz = ? AND biz_id = ?", cb.Uid, cb.Biz, cb.BizId).
+					Updates(map[string]any{
+						"cid":   cb.Cid,
+						"utime": now,
+					}).Error
+			}
+		}
+		if err != nil {
+			return err
+		}
+		changed = true
+		return tx.Clauses(clause.OnConflict{
+			DoUpdates: clause.Assignments(map[string]any{
+				"collect_cnt": gorm.Expr("`collect_cnt` + 1"),
+				"utime":       now,
+			}),
+		}).Create(&Interactive{
+			Biz:        cb.Biz,
+			BizId:      cb.BizId,
+			CollectCnt: 1,
+			Ctime:      now,
+			Utime:      now,
+		}).Error
+	})
+	return changed, err
+}
+
+func (dao *GORMInteractiveDAO) GetLikeInfo(ctx context.Context, biz string, bizId int64, uid int64) (UserLikeBiz, error) {
+	var res UserLikeBiz
+	err := dao.db.WithContext(ctx).
+		Where("uid = ? AND biz_id = ? AND biz = ? AND status = ?",
+			uid, bizId, biz, likeStatusValid).
+		First(&res).Error
+	return res, err
+}
+
+func (dao *GORMInteractiveDAO) GetCollectionInfo(ctx context.Context, biz string, bizId int64, uid int64) (UserCollectionBiz, error) {
+	var res UserCollectionBiz
+	err := dao.db.WithContext(ctx).
+		Where("uid = ? AND biz_id = ? AND biz = ?", uid, bizId, biz).
+		First(&res).Error
+	return res, err
+}
+
+func (dao *GORMInteractiveDAO) Get(ctx context.Context, biz string, bizId int64) (Interactive, error) {
+	var res Interactive
+	err := dao.db.WithContext(ctx).
+		Where("biz = ? AND biz_id = ?", biz, bizId).
+		First(&res).Error
+	return res, err
+}
+
+func (dao *GORMInteractiveDAO) GetByIds(ctx context.Context, biz string, ids []int64) ([]Interactive, error) {
+	var res []Interactive
+	err := dao.db.WithContext(ctx).
+		Where("biz = ? AND biz_id IN ?", biz, ids).
+		Find(&res).Error
+	return res, err
+}
@@ -0,0 +1,229 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestGORMInteractiveDAO_BatchIncrReadCnt(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(t *testing.T) *sql.DB
+		deltas  []ReadCntDelta
+		wantErr error
+	}{
+		{
+			name: "one update for the whole batch",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO `interactives` .*ON DUPLICATE KEY UPDATE `id`=`id`").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("UPDATE `interactives` SET `read_cnt`=`read_cnt` \\+ CASE "+
+					"WHEN `biz` = \\? AND `biz_id` = \\? THEN \\? WHEN `biz` = \\? AND `biz_id` = \\? THEN \\? ELSE 0 END.*"+
+					"WHERE \\(`biz`, `biz_id`\\) IN \\(\\(\\?,\\?\\),\\(\\?,\\?\\)\\)").
+					WithArgs("article", int64(1), int64(3), "article", int64(2), int64(5),
+						sqlmock.AnyArg(), "article", int64(1), "article", int64(2)).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectCommit()
+				return db
+			},
+			deltas: []ReadCntDelta{
+				{Biz: "article", BizId: 1, Delta: 3},
+				{Biz: "article", BizId: 2, Delta: 5},
+			},
+		},
+		{
+			name: "update failed",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO `interactives` .*").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("UPDATE `interactives` .*").
+					WillReturnError(errors.New("db error"))
+				mock.ExpectRollback()
+				return db
+			},
+			deltas: []ReadCntDelta{
+				{Biz: "article", BizId: 1, Delta: 3},
+			},
+			wantErr: errors.New("db error"),
+		},
+		{
+			name: "empty batch",
+			mock: func(t *testing.T) *sql.DB {
+				db, _, err := sqlmock.New()
+				require.NoError(t, err)
+				return db
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sqlDB := tc.mock(t)
+			db, err := gorm.Open(mysql.New(
+				mysql.Config{
+					Conn:                      sqlDB,
+					SkipInitializeWithVersion: true,
+				}),
+				&gorm.Config{
+					DisableAutomaticPing:   true,
+					SkipDefaultTransaction: true,
+				})
+			require.NoError(t, err)
+			dao := NewInteractiveDAO(db)
+			err = dao.BatchIncrReadCnt(context.Background(), tc.deltas)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestGORMInteractiveDAO_InsertLikeInfo(t *testing.T) {
+	testCases := []struct {
+		name        string
+		mock        func(t *testing.T) *sql.DB
+		wantChanged bool
+		wantErr     error
+	}{
+		{
+			name: "first like",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO `user_like_bizs` .*ON DUPLICATE KEY UPDATE " +
+					"`utime`=IF\\(`status` = \\?, `utime`, \\?\\),`status`=\\?").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO `interactives` .*ON DUPLICATE KEY UPDATE.*`like_cnt`=`like_cnt` \\+ 1").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+				return db
+			},
+			wantChanged: true,
+		},
+		{
+			// 两个请求同时点赞，后到的那个命中唯一索引，什么都没改
+			name: "already liked",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO `user_like_bizs` .*ON DUPLICATE KEY UPDATE").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+				return db
+			},
+		},
+		{
+			name: "liked again after cancel",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO `user_like_bizs` .*ON DUPLICATE KEY UPDATE").
+					WillReturnResult(sqlmock.NewResult(1, 2))
+				mock.ExpectExec("INSERT INTO `interactives` .*").
+					WillReturnResult(sqlmock.NewResult(1, 2))
+				mock.ExpectCommit()
+				return db
+			},
+			wantChanged: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sqlDB := tc.mock(t)
+			db, err := gorm.Open(mysql.New(
+				mysql.Config{
+					Conn:                      sqlDB,
+					SkipInitializeWithVersion: true,
+				}),
+				&gorm.Config{
+					DisableAutomaticPing:   true,
+					SkipDefaultTransaction: true,
+				})
+			require.NoError(t, err)
+			dao := NewInteractiveDAO(db)
+			changed, err := dao.InsertLikeInfo(context.Background(), "article", 1, 123)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantChanged, changed)
+		})
+	}
+}
+
+func TestGORMInteractiveDAO_InsertCollectionBiz(t *testing.T) {
+	testCases := []struct {
+		name        string
+		mock        func(t *testing.T) *sql.DB
+		wantChanged bool
+		wantErr     error
+	}{
+		{
+			name: "collected",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				// 收藏夹在同一个事务里面锁住
+				mock.ExpectQuery("SELECT \\* FROM `collections` WHERE id = \\? AND uid = \\?.* FOR UPDATE").
+					WithArgs(int64(2), int64(123), 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "uid"}).AddRow(2, 123))
+				mock.ExpectExec("INSERT INTO `user_collection_bizs` .*").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO `interactives` .*`collect_cnt`=`collect_cnt` \\+ 1").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+				return db
+			},
+			wantChanged: true,
+		},
+		{
+			name: "not my collection",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT \\* FROM `collections` .* FOR UPDATE").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "uid"}))
+				mock.ExpectRollback()
+				return db
+			},
+			wantErr: ErrCollectionNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sqlDB := tc.mock(t)
+			db, err := gorm.Open(mysql.New(
+				mysql.Config{
+					Conn:                      sqlDB,
+					SkipInitializeWithVersion: true,
+				}),
+				&gorm.Config{
+					DisableAutomaticPing:   true,
+					SkipDefaultTransaction: true,
+				})
+			require.NoError(t, err)
+			dao := NewInteractiveDAO(db)
+			changed, err := dao.InsertCollectionBiz(context.Background(), UserCollectionBiz{
+				Uid:   123,
+				Biz:   "article",
+				BizId: 1,
+				Cid:   2,
+			})
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantChanged, changed)
+		})
+	}
+}
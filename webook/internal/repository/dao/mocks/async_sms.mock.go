@@ -1,9 +1,9 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: ./async_sms.go
+// Source: ./webook/internal/repository/dao/async_sms.go
 //
 // Generated by this command:
 //
-//	mockgen -source=./async_sms.go -package=daomocks -destination=mocks/async_sms.mock.go AsyncSmsDAO
+//	mockgen -source=./webook/internal/repository/dao/async_sms.go -package=daomocks -destination=./webook/internal/repository/dao/mocks/async_sms.mock.go
 //
 
 // Package daomocks is a generated GoMock package.
@@ -40,6 +40,21 @@ func (m *MockAsyncSmsDAO) EXPECT() *MockAsyncSmsDAOMockRecorder {
 	return m.recorder
 }
 
+// CountByStatus mocks base method.
+func (m *MockAsyncSmsDAO) CountByStatus(ctx context.Context) (map[uint8]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountByStatus", ctx)
+	ret0, _ := ret[0].(map[uint8]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountByStatus indicates an expected call of CountByStatus.
+func (mr *MockAsyncSmsDAOMockRecorder) CountByStatus(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountByStatus", reflect.TypeOf((*MockAsyncSmsDAO)(nil).CountByStatus), ctx)
+}
+
 // GetWaitingSMS mocks base method.
 func (m *MockAsyncSmsDAO) GetWaitingSMS(ctx context.Context) (dao.AsyncSms, error) {
 	m.ctrl.T.Helper()
@@ -69,6 +84,21 @@ func (mr *MockAsyncSmsDAOMockRecorder) Insert(ctx, s any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockAsyncSmsDAO)(nil).Insert), ctx, s)
 }
 
+// ListByStatus mocks base method.
+func (m *MockAsyncSmsDAO) ListByStatus(ctx context.Context, status uint8, offset, limit int) ([]dao.AsyncSms, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByStatus", ctx, status, offset, limit)
+	ret0, _ := ret[0].([]dao.AsyncSms)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByStatus indicates an expected call of ListByStatus.
+func (mr *MockAsyncSmsDAOMockRecorder) ListByStatus(ctx, status, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByStatus", reflect.TypeOf((*MockAsyncSmsDAO)(nil).ListByStatus), ctx, status, offset, limit)
+}
+
 // MarkFailed mocks base method.
 func (m *MockAsyncSmsDAO) MarkFailed(ctx context.Context, id int64) error {
 	m.ctrl.T.Helper()
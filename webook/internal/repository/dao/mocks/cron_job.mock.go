@@ -0,0 +1,100 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./webook/internal/repository/dao/cron_job.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./webook/internal/repository/dao/cron_job.go -package=daomocks -destination=./webook/internal/repository/dao/mocks/cron_job.mock.go
+//
+
+// Package daomocks is a generated GoMock package.
+package daomocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	dao "gitee.com/geekbang/basic-go/webook/internal/repository/dao"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockCronJobDAO is a mock of CronJobDAO interface.
+type MockCronJobDAO struct {
+	ctrl     *gomock.Controller
+	recorder *MockCronJobDAOMockRecorder
+}
+
+// MockCronJobDAOMockRecorder is the mock recorder for MockCronJobDAO.
+type MockCronJobDAOMockRecorder struct {
+	mock *MockCronJobDAO
+}
+
+// NewMockCronJobDAO creates a new mock instance.
+func NewMockCronJobDAO(ctrl *gomock.Controller) *MockCronJobDAO {
+	mock := &MockCronJobDAO{ctrl: ctrl}
+	mock.recorder = &MockCronJobDAOMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCronJobDAO) EXPECT() *MockCronJobDAOMockRecorder {
+	return m.recorder
+}
+
+// Insert mocks base method.
+func (m *MockCronJobDAO) Insert(ctx context.Context, j dao.CronJob) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", ctx, j)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockCronJobDAOMockRecorder) Insert(ctx, j any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockCronJobDAO)(nil).Insert), ctx, j)
+}
+
+// Preempt mocks base method.
+func (m *MockCronJobDAO) Preempt(ctx context.Context, expiration time.Duration) (dao.CronJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Preempt", ctx, expiration)
+	ret0, _ := ret[0].(dao.CronJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Preempt indicates an expected call of Preempt.
+func (mr *MockCronJobDAOMockRecorder) Preempt(ctx, expiration any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Preempt", reflect.TypeOf((*MockCronJobDAO)(nil).Preempt), ctx, expiration)
+}
+
+// Release mocks base method.
+func (m *MockCronJobDAO) Release(ctx context.Context, id, version, nextTime int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Release", ctx, id, version, nextTime)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Release indicates an expected call of Release.
+func (mr *MockCronJobDAOMockRecorder) Release(ctx, id, version, nextTime any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Release", reflect.TypeOf((*MockCronJobDAO)(nil).Release), ctx, id, version, nextTime)
+}
+
+// UpdateUtime mocks base method.
+func (m *MockCronJobDAO) UpdateUtime(ctx context.Context, id, version int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUtime", ctx, id, version)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUtime indicates an expected call of UpdateUtime.
+func (mr *MockCronJobDAOMockRecorder) UpdateUtime(ctx, id, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUtime", reflect.TypeOf((*MockCronJobDAO)(nil).UpdateUtime), ctx, id, version)
+}
@@ -0,0 +1,114 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./webook/internal/repository/dao/identity.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./webook/internal/repository/dao/identity.go -package=daomocks -destination=./webook/internal/repository/dao/mocks/identity.mock.go
+//
+
+// Package daomocks is a generated GoMock package.
+package daomocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	dao "gitee.com/geekbang/basic-go/webook/internal/repository/dao"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIdentityDAO is a mock of IdentityDAO interface.
+type MockIdentityDAO struct {
+	ctrl     *gomock.Controller
+	recorder *MockIdentityDAOMockRecorder
+}
+
+// MockIdentityDAOMockRecorder is the mock recorder for MockIdentityDAO.
+type MockIdentityDAOMockRecorder struct {
+	mock *MockIdentityDAO
+}
+
+// NewMockIdentityDAO creates a new mock instance.
+func NewMockIdentityDAO(ctrl *gomock.Controller) *MockIdentityDAO {
+	mock := &MockIdentityDAO{ctrl: ctrl}
+	mock.recorder = &MockIdentityDAOMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIdentityDAO) EXPECT() *MockIdentityDAOMockRecorder {
+	return m.recorder
+}
+
+// Bind mocks base method.
+func (m *MockIdentityDAO) Bind(ctx context.Context, identity dao.UserIdentity, audit dao.UserAuditLog) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Bind", ctx, identity, audit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Bind indicates an expected call of Bind.
+func (mr *MockIdentityDAOMockRecorder) Bind(ctx, identity, audit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Bind", reflect.TypeOf((*MockIdentityDAO)(nil).Bind), ctx, identity, audit)
+}
+
+// FindByProvider mocks base method.
+func (m *MockIdentityDAO) FindByProvider(ctx context.Context, provider, subject string) (dao.UserIdentity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByProvider", ctx, provider, subject)
+	ret0, _ := ret[0].(dao.UserIdentity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByProvider indicates an expected call of FindByProvider.
+func (mr *MockIdentityDAOMockRecorder) FindByProvider(ctx, provider, subject any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByProvider", reflect.TypeOf((*MockIdentityDAO)(nil).FindByProvider), ctx, provider, subject)
+}
+
+// FindByUid mocks base method.
+func (m *MockIdentityDAO) FindByUid(ctx context.Context, uid int64) ([]dao.UserIdentity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByUid", ctx, uid)
+	ret0, _ := ret[0].([]dao.UserIdentity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByUid indicates an expected call of FindByUid.
+func (mr *MockIdentityDAOMockRecorder) FindByUid(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByUid", reflect.TypeOf((*MockIdentityDAO)(nil).FindByUid), ctx, uid)
+}
+
+// InsertWithUser mocks base method.
+func (m *MockIdentityDAO) InsertWithUser(ctx context.Context, u dao.User, identity dao.UserIdentity) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertWithUser", ctx, u, identity)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertWithUser indicates an expected call of InsertWithUser.
+func (mr *MockIdentityDAOMockRecorder) InsertWithUser(ctx, u, identity any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertWithUser", reflect.TypeOf((*MockIdentityDAO)(nil).InsertWithUser), ctx, u, identity)
+}
+
+// Unbind mocks base method.
+func (m *MockIdentityDAO) Unbind(ctx context.Context, uid int64, provider string, audit dao.UserAuditLog) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unbind", ctx, uid, provider, audit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unbind indicates an expected call of Unbind.
+func (mr *MockIdentityDAOMockRecorder) Unbind(ctx, uid, provider, audit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unbind", reflect.TypeOf((*MockIdentityDAO)(nil).Unbind), ctx, uid, provider, audit)
+}
@@ -0,0 +1,99 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./webook/internal/repository/dao/role.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./webook/internal/repository/dao/role.go -package=daomocks -destination=./webook/internal/repository/dao/mocks/role.mock.go
+//
+
+// Package daomocks is a generated GoMock package.
+package daomocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	dao "gitee.com/geekbang/basic-go/webook/internal/repository/dao"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockRoleDAO is a mock of RoleDAO interface.
+type MockRoleDAO struct {
+	ctrl     *gomock.Controller
+	recorder *MockRoleDAOMockRecorder
+}
+
+// MockRoleDAOMockRecorder is the mock recorder for MockRoleDAO.
+type MockRoleDAOMockRecorder struct {
+	mock *MockRoleDAO
+}
+
+// NewMockRoleDAO creates a new mock instance.
+func NewMockRoleDAO(ctrl *gomock.Controller) *MockRoleDAO {
+	mock := &MockRoleDAO{ctrl: ctrl}
+	mock.recorder = &MockRoleDAOMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRoleDAO) EXPECT() *MockRoleDAOMockRecorder {
+	return m.recorder
+}
+
+// DeleteUserRole mocks base method.
+func (m *MockRoleDAO) DeleteUserRole(ctx context.Context, uid int64, role string, audit dao.UserAuditLog) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserRole", ctx, uid, role, audit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserRole indicates an expected call of DeleteUserRole.
+func (mr *MockRoleDAOMockRecorder) DeleteUserRole(ctx, uid, role, audit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserRole", reflect.TypeOf((*MockRoleDAO)(nil).DeleteUserRole), ctx, uid, role, audit)
+}
+
+// FindPermissions mocks base method.
+func (m *MockRoleDAO) FindPermissions(ctx context.Context, roles []string) ([]dao.RolePermission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPermissions", ctx, roles)
+	ret0, _ := ret[0].([]dao.RolePermission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPermissions indicates an expected call of FindPermissions.
+func (mr *MockRoleDAOMockRecorder) FindPermissions(ctx, roles any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPermissions", reflect.TypeOf((*MockRoleDAO)(nil).FindPermissions), ctx, roles)
+}
+
+// FindRolesByUid mocks base method.
+func (m *MockRoleDAO) FindRolesByUid(ctx context.Context, uid int64) ([]dao.UserRole, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindRolesByUid", ctx, uid)
+	ret0, _ := ret[0].([]dao.UserRole)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindRolesByUid indicates an expected call of FindRolesByUid.
+func (mr *MockRoleDAOMockRecorder) FindRolesByUid(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRolesByUid", reflect.TypeOf((*MockRoleDAO)(nil).FindRolesByUid), ctx, uid)
+}
+
+// InsertUserRole mocks base method.
+func (m *MockRoleDAO) InsertUserRole(ctx context.Context, r dao.UserRole, audit dao.UserAuditLog) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertUserRole", ctx, r, audit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertUserRole indicates an expected call of InsertUserRole.
+func (mr *MockRoleDAOMockRecorder) InsertUserRole(ctx, r, audit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertUserRole", reflect.TypeOf((*MockRoleDAO)(nil).InsertUserRole), ctx, r, audit)
+}
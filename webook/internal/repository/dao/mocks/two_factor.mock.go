@@ -0,0 +1,112 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./webook/internal/repository/dao/two_factor.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./webook/internal/repository/dao/two_factor.go -package=daomocks -destination=./webook/internal/repository/dao/mocks/two_factor.mock.go
+//
+
+// Package daomocks is a generated GoMock package.
+package daomocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	dao "gitee.com/geekbang/basic-go/webook/internal/repository/dao"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockTwoFactorDAO is a mock of TwoFactorDAO interface.
+type MockTwoFactorDAO struct {
+	ctrl     *gomock.Controller
+	recorder *MockTwoFactorDAOMockRecorder
+}
+
+// MockTwoFactorDAOMockRecorder is the mock recorder for MockTwoFactorDAO.
+type MockTwoFactorDAOMockRecorder struct {
+	mock *MockTwoFactorDAO
+}
+
+// NewMockTwoFactorDAO creates a new mock instance.
+func NewMockTwoFactorDAO(ctrl *gomock.Controller) *MockTwoFactorDAO {
+	mock := &MockTwoFactorDAO{ctrl: ctrl}
+	mock.recorder = &MockTwoFactorDAOMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTwoFactorDAO) EXPECT() *MockTwoFactorDAOMockRecorder {
+	return m.recorder
+}
+
+// Enable mocks base method.
+func (m *MockTwoFactorDAO) Enable(ctx context.Context, uid, step int64, recoveryCodes []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enable", ctx, uid, step, recoveryCodes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Enable indicates an expected call of Enable.
+func (mr *MockTwoFactorDAOMockRecorder) Enable(ctx, uid, step, recoveryCodes any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enable", reflect.TypeOf((*MockTwoFactorDAO)(nil).Enable), ctx, uid, step, recoveryCodes)
+}
+
+// FindByUid mocks base method.
+func (m *MockTwoFactorDAO) FindByUid(ctx context.Context, uid int64) (dao.UserTwoFactor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByUid", ctx, uid)
+	ret0, _ := ret[0].(dao.UserTwoFactor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByUid indicates an expected call of FindByUid.
+func (mr *MockTwoFactorDAOMockRecorder) FindByUid(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByUid", reflect.TypeOf((*MockTwoFactorDAO)(nil).FindByUid), ctx, uid)
+}
+
+// Upsert mocks base method.
+func (m *MockTwoFactorDAO) Upsert(ctx context.Context, tf dao.UserTwoFactor) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upsert", ctx, tf)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Upsert indicates an expected call of Upsert.
+func (mr *MockTwoFactorDAOMockRecorder) Upsert(ctx, tf any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upsert", reflect.TypeOf((*MockTwoFactorDAO)(nil).Upsert), ctx, tf)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockTwoFactorDAO) UseRecoveryCode(ctx context.Context, uid int64, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", ctx, uid, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockTwoFactorDAOMockRecorder) UseRecoveryCode(ctx, uid, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockTwoFactorDAO)(nil).UseRecoveryCode), ctx, uid, code)
+}
+
+// UseStep mocks base method.
+func (m *MockTwoFactorDAO) UseStep(ctx context.Context, uid, step int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseStep", ctx, uid, step)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseStep indicates an expected call of UseStep.
+func (mr *MockTwoFactorDAOMockRecorder) UseStep(ctx, uid, step any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseStep", reflect.TypeOf((*MockTwoFactorDAO)(nil).UseStep), ctx, uid, step)
+}
@@ -85,21 +85,6 @@ func (mr *MockUserDaoMockRecorder) FindByPhone(ctx, phone any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByPhone", reflect.TypeOf((*MockUserDao)(nil).FindByPhone), ctx, phone)
 }
 
-// FindByWechat mocks base method.
-func (m *MockUserDao) FindByWechat(ctx context.Context, openId string) (dao.User, error) {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindByWechat", ctx, openId)
-	ret0, _ := ret[0].(dao.User)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
-}
-
-// FindByWechat indicates an expected call of FindByWechat.
-func (mr *MockUserDaoMockRecorder) FindByWechat(ctx, openId any) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByWechat", reflect.TypeOf((*MockUserDao)(nil).FindByWechat), ctx, openId)
-}
-
 // Insert mocks base method.
 func (m *MockUserDao) Insert(ctx context.Context, user dao.User) error {
 	m.ctrl.T.Helper()
@@ -114,6 +99,22 @@ func (mr *MockUserDaoMockRecorder) Insert(ctx, user any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockUserDao)(nil).Insert), ctx, user)
 }
 
+// Search mocks base method.
+func (m *MockUserDao) Search(ctx context.Context, keyword string, offset, limit int) ([]dao.User, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, keyword, offset, limit)
+	ret0, _ := ret[0].([]dao.User)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockUserDaoMockRecorder) Search(ctx, keyword, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockUserDao)(nil).Search), ctx, keyword, offset, limit)
+}
+
 // UpdateById mocks base method.
 func (m *MockUserDao) UpdateById(ctx context.Context, entity dao.User) error {
 	m.ctrl.T.Helper()
@@ -127,3 +128,17 @@ func (mr *MockUserDaoMockRecorder) UpdateById(ctx, entity any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateById", reflect.TypeOf((*MockUserDao)(nil).UpdateById), ctx, entity)
 }
+
+// UpdateSensitive mocks base method.
+func (m *MockUserDao) UpdateSensitive(ctx context.Context, id int64, fields map[string]any, audit dao.UserAuditLog) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSensitive", ctx, id, fields, audit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSensitive indicates an expected call of UpdateSensitive.
+func (mr *MockUserDaoMockRecorder) UpdateSensitive(ctx, id, fields, audit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSensitive", reflect.TypeOf((*MockUserDao)(nil).UpdateSensitive), ctx, id, fields, audit)
+}
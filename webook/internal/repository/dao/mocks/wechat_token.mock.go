@@ -0,0 +1,99 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./webook/internal/repository/dao/wechat_token.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./webook/internal/repository/dao/wechat_token.go -package=daomocks -destination=./webook/internal/repository/dao/mocks/wechat_token.mock.go
+//
+
+// Package daomocks is a generated GoMock package.
+package daomocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	dao "gitee.com/geekbang/basic-go/webook/internal/repository/dao"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockWechatTokenDAO is a mock of WechatTokenDAO interface.
+type MockWechatTokenDAO struct {
+	ctrl     *gomock.Controller
+	recorder *MockWechatTokenDAOMockRecorder
+}
+
+// MockWechatTokenDAOMockRecorder is the mock recorder for MockWechatTokenDAO.
+type MockWechatTokenDAOMockRecorder struct {
+	mock *MockWechatTokenDAO
+}
+
+// NewMockWechatTokenDAO creates a new mock instance.
+func NewMockWechatTokenDAO(ctrl *gomock.Controller) *MockWechatTokenDAO {
+	mock := &MockWechatTokenDAO{ctrl: ctrl}
+	mock.recorder = &MockWechatTokenDAOMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWechatTokenDAO) EXPECT() *MockWechatTokenDAOMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockWechatTokenDAO) Delete(ctx context.Context, uid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockWechatTokenDAOMockRecorder) Delete(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockWechatTokenDAO)(nil).Delete), ctx, uid)
+}
+
+// FindByUid mocks base method.
+func (m *MockWechatTokenDAO) FindByUid(ctx context.Context, uid int64) (dao.WechatToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByUid", ctx, uid)
+	ret0, _ := ret[0].(dao.WechatToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByUid indicates an expected call of FindByUid.
+func (mr *MockWechatTokenDAOMockRecorder) FindByUid(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByUid", reflect.TypeOf((*MockWechatTokenDAO)(nil).FindByUid), ctx, uid)
+}
+
+// FindExpiring mocks base method.
+func (m *MockWechatTokenDAO) FindExpiring(ctx context.Context, before, afterUid int64, limit int) ([]dao.WechatToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindExpiring", ctx, before, afterUid, limit)
+	ret0, _ := ret[0].([]dao.WechatToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindExpiring indicates an expected call of FindExpiring.
+func (mr *MockWechatTokenDAOMockRecorder) FindExpiring(ctx, before, afterUid, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindExpiring", reflect.TypeOf((*MockWechatTokenDAO)(nil).FindExpiring), ctx, before, afterUid, limit)
+}
+
+// Upsert mocks base method.
+func (m *MockWechatTokenDAO) Upsert(ctx context.Context, t dao.WechatToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upsert", ctx, t)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Upsert indicates an expected call of Upsert.
+func (mr *MockWechatTokenDAOMockRecorder) Upsert(ctx, t any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upsert", reflect.TypeOf((*MockWechatTokenDAO)(nil).Upsert), ctx, t)
+}
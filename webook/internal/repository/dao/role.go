@@ -0,0 +1,107 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RoleDAO interface {
+	FindRolesByUid(ctx context.Context, uid int64) ([]UserRole, error)
+	FindPermissions(ctx context.Context, roles []string) ([]RolePermission, error)
+	// InsertUserRole 已经有这个角色不报错，也不写审计记录
+	InsertUserRole(ctx context.Context, r UserRole, audit UserAuditLog) error
+	DeleteUserRole(ctx context.Context, uid int64, role string, audit UserAuditLog) error
+}
+
+// UserRole 用户有哪些角色。第一个管理员只能直接往表里面插
+type UserRole struct {
+	Id    int64  `gorm:"primaryKey,autoIncrement"`
+	Uid   int64  `gorm:"uniqueIndex:uid_role"`
+	Role  string `gorm:"type:varchar(64);uniqueIndex:uid_role"`
+	Ctime int64
+}
+
+// RolePermission 角色有哪些权限，改了之后下一个请求就生效
+type RolePermission struct {
+	Id         int64  `gorm:"primaryKey,autoIncrement"`
+	Role       string `gorm:"type:varchar(64);uniqueIndex:role_permission"`
+	Permission string `gorm:"type:varchar(64);uniqueIndex:role_permission"`
+	Ctime      int64
+}
+
+type GORMRoleDAO struct {
+	db *gorm.DB
+}
+
+func NewRoleDAO(db *gorm.DB) RoleDAO {
+	return &GORMRoleDAO{
+		db: db,
+	}
+}
+
+func (dao *GORMRoleDAO) FindRolesByUid(ctx context.Context, uid int64) ([]UserRole, error) {
+	var res []UserRole
+	err := dao.db.WithContext(ctx).Where("uid = ?", uid).
+		Order("id").Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMRoleDAO) FindPermissions(ctx context.Context, roles []string) ([]RolePermission, error) {
+	var res []RolePermission
+	if len(roles) == 0 {
+		return res, nil
+	}
+	err := dao.db.WithContext(ctx).Where("role IN ?", roles).
+		Order("id").Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMRoleDAO) InsertUserRole(ctx context.Context, r UserRole, audit UserAuditLog) error {
+	now := time.Now().UnixMilli()
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		r.Ctime = now
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&r)
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		audit.Uid = r.Uid
+		audit.Ctime = now
+		return tx.Create(&audit).Error
+	})
+}
+
+func (dao *GORMRoleDAO) DeleteUserRole(ctx context.Context, uid int64,
+	role string, audit UserAuditLog) error {
+	now := time.Now().UnixMilli()
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("uid = ? AND role = ?", uid, role).Delete(&UserRole{})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		audit.Uid = uid
+		audit.Ctime = now
+		return tx.Create(&audit).Error
+	})
+}
+
+// InitRolePermissions 写入默认的角色权限，key 是角色。已经有的不动，可以重复执行
+func InitRolePermissions(db *gorm.DB, defaults map[string][]string) error {
+	now := time.Now().UnixMilli()
+	var rows []RolePermission
+	for role, perms := range defaults {
+		for _, p := range perms {
+			rows = append(rows, RolePermission{
+				Role:       role,
+				Permission: p,
+				Ctime:      now,
+			})
+		}
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error
+}
@@ -0,0 +1,69 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestGORMRoleDAO_InsertUserRole(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(t *testing.T) *sql.DB
+		wantErr error
+	}{
+		{
+			name: "granted",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO `user_roles` .* ON DUPLICATE KEY UPDATE .*").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO `user_audit_logs` .*").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+				return db
+			},
+		},
+		{
+			// 已经有这个角色，不写审计记录
+			name: "already granted",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO `user_roles` .*").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+				return db
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sqlDB := tc.mock(t)
+			db, err := gorm.Open(mysql.New(
+				mysql.Config{
+					Conn:                      sqlDB,
+					SkipInitializeWithVersion: true,
+				}),
+				&gorm.Config{
+					DisableAutomaticPing:   true,
+					SkipDefaultTransaction: true,
+				})
+			assert.NoError(t, err)
+			dao := NewRoleDAO(db)
+			err = dao.InsertUserRole(context.Background(), UserRole{
+				Uid:  123,
+				Role: "admin",
+			}, UserAuditLog{Action: "grant_role", After: "admin", Operator: 1})
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
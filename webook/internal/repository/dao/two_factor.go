@@ -0,0 +1,140 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrTOTPStepUsed 这个时间片的 code 已经用过了
+	ErrTOTPStepUsed = errors.New("totp step already used")
+	// ErrRecoveryCodeNotFound 恢复码不存在或者已经用过了
+	ErrRecoveryCodeNotFound = errors.New("recovery code not found")
+)
+
+type TwoFactorDAO interface {
+	FindByUid(ctx context.Context, uid int64) (UserTwoFactor, error)
+	// Upsert 重新绑定的时候覆盖掉还没有确认的密钥
+	Upsert(ctx context.Context, tf UserTwoFactor) error
+	// Enable 确认绑定，同时换掉所有恢复码
+	Enable(ctx context.Context, uid int64, step int64, recoveryCodes []string) error
+	// UseStep 只有 step 比上一次用过的大才会成功
+	UseStep(ctx context.Context, uid int64, step int64) error
+	// UseRecoveryCode code 是哈希过的恢复码，每个只能用一次
+	UseRecoveryCode(ctx context.Context, uid int64, code string) error
+}
+
+// UserTwoFactor 一个用户一条
+type UserTwoFactor struct {
+	Id  int64 `gorm:"primaryKey,autoIncrement"`
+	Uid int64 `gorm:"unique"`
+	// Secret 是加密过的，见 repository.CachedTwoFactorRepository
+	Secret   string `gorm:"type:varchar(256)"`
+	Enabled  bool
+	LastStep int64
+	Ctime    int64
+	Utime    int64
+}
+
+// UserRecoveryCode Code 存的是 SHA-256，恢复码本身是随机的，不需要慢哈希
+type UserRecoveryCode struct {
+	Id    int64  `gorm:"primaryKey,autoIncrement"`
+	Uid   int64  `gorm:"uniqueIndex:uid_code"`
+	Code  string `gorm:"type:varchar(64);uniqueIndex:uid_code"`
+	Used  bool
+	Ctime int64
+	Utime int64
+}
+
+type GORMTwoFactorDAO struct {
+	db *gorm.DB
+}
+
+func NewTwoFactorDAO(db *gorm.DB) TwoFactorDAO {
+	return &GORMTwoFactorDAO{
+		db: db,
+	}
+}
+
+func (dao *GORMTwoFactorDAO) FindByUid(ctx context.Context, uid int64) (UserTwoFactor, error) {
+	var res UserTwoFactor
+	err := dao.db.WithContext(ctx).Where("uid = ?", uid).First(&res).Error
+	return res, err
+}
+
+func (dao *GORMTwoFactorDAO) Upsert(ctx context.Context, tf UserTwoFactor) error {
+	now := time.Now().UnixMilli()
+	tf.Ctime = now
+	tf.Utime = now
+	return dao.db.WithContext(ctx).Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]any{
+			"secret":    tf.Secret,
+			"enabled":   tf.Enabled,
+			"last_step": tf.LastStep,
+			"utime":     now,
+		}),
+	}).Create(&tf).Error
+}
+
+func (dao *GORMTwoFactorDAO) Enable(ctx context.Context, uid int64, step int64, recoveryCodes []string) error {
+	now := time.Now().UnixMilli()
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&UserTwoFactor{}).Where("uid = ?", uid).
+			Updates(map[string]any{
+				"enabled":   true,
+				"last_step": step,
+				"utime":     now,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrRecordNotFound
+		}
+		err := tx.Where("uid = ?", uid).Delete(&UserRecoveryCode{}).Error
+		if err != nil {
+			return err
+		}
+		codes := make([]UserRecoveryCode, 0, len(recoveryCodes))
+		for _, c := range recoveryCodes {
+			codes = append(codes, UserRecoveryCode{Uid: uid, Code: c, Ctime: now, Utime: now})
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+func (dao *GORMTwoFactorDAO) UseStep(ctx context.Context, uid int64, step int64) error {
+	res := dao.db.WithContext(ctx).Model(&UserTwoFactor{}).
+		Where("uid = ? AND last_step < ?", uid, step).
+		Updates(map[string]any{
+			"last_step": step,
+			"utime":     time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrTOTPStepUsed
+	}
+	return nil
+}
+
+func (dao *GORMTwoFactorDAO) UseRecoveryCode(ctx context.Context, uid int64, code string) error {
+	res := dao.db.WithContext(ctx).Model(&UserRecoveryCode{}).
+		Where("uid = ? AND code = ? AND used = ?", uid, code, false).
+		Updates(map[string]any{
+			"used":  true,
+			"utime": time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrRecoveryCodeNotFound
+	}
+	return nil
+}
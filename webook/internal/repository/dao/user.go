@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-sql-driver/mysql"
@@ -12,6 +14,7 @@ import (
 
 var (
 	ErrDuplicateEmail = errors.New("邮箱冲突")
+	ErrDuplicatePhone = errors.New("手机号冲突")
 	ErrRecordNotFound = gorm.ErrRecordNotFound
 )
 
@@ -21,7 +24,10 @@ type UserDao interface{
 	FindByEmail(ctx context.Context, email string) (User, error)
 	FindById(ctx context.Context, uid int64) (User, error)
 	FindByPhone(ctx context.Context, phone string) (User, error)
-	FindByWechat(ctx context.Context, openId string) (User, error)
+	// UpdateSensitive 更新敏感字段，同一个事务里面写审计记录
+	UpdateSensitive(ctx context.Context, id int64, fields map[string]any, audit UserAuditLog) error
+	// Search keyword 为空的时候列出全部，按 id 倒序，同时返回总数
+	Search(ctx context.Context, keyword string, offset int, limit int) ([]User, int64, error)
 }
 
 type GORMUserDao struct {
@@ -31,15 +37,19 @@ type GORMUserDao struct {
 type User struct {
 	Id       int64          `gorm:"primaryKey,autoIncrement"`
 	Email    sql.NullString `gorm:"unique"`
+	EmailVerified bool
 	Password string
 	Nickname string `gorm:"type=varchar(128)"`
 	Birthday int64
 	AboutMe  string `gorm:"type=varchar(4096)"`
+	Avatar   string `gorm:"type:varchar(1024)"`
 
 	Phone sql.NullString `gorm:"unique"`
 
-	WechatOpenId sql.NullString `gorm:"unique"`
-	WechatUnionId sql.NullString
+	// 微信的 openid 和 unionid 挪到 UserIdentity 里面了，老的两列由 InitTables 迁移
+
+	// BannedAt 封禁的时间，0 是没有封禁
+	BannedAt int64
 
 	CreateAt int64
 	UpdateAt int64
@@ -56,14 +66,20 @@ func (dao *GORMUserDao) Insert(ctx context.Context, user User) error {
 	user.CreateAt = now
 	user.UpdateAt = now
 	err := dao.db.WithContext(ctx).Create(&user).Error
+	return duplicateUserErr(err)
+}
 
-	if me, ok := err.(*mysql.MySQLError); ok {
-		const duplicateErr uint16 = 1062
-		if me.Number == duplicateErr {
-			return ErrDuplicateEmail
-		}
+// duplicateUserErr 邮箱和手机号都是唯一索引，冲突的时候按照索引名字区分是哪一个。
+// MySQL 的错误信息是 Duplicate entry 'xxx' for key 'users.phone'
+func duplicateUserErr(err error) error {
+	var me *mysql.MySQLError
+	if !isDuplicate(err) || !errors.As(err, &me) {
+		return err
 	}
-	return err
+	if strings.Contains(me.Message, "phone") {
+		return ErrDuplicatePhone
+	}
+	return ErrDuplicateEmail
 }
 
 func (dao *GORMUserDao) UpdateById(ctx context.Context, entity User) error {
@@ -100,9 +116,58 @@ func (dao *GORMUserDao) FindByPhone(ctx context.Context, phone string) (User, er
 	return res, err
 }
 
+func (dao *GORMUserDao) UpdateSensitive(ctx context.Context, id int64,
+	fields map[string]any, audit UserAuditLog) error {
+	now := time.Now().UnixMilli()
+	fields["update_at"] = now
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&User{}).Where("id = ?", id).Updates(fields).Error
+		if err != nil {
+			return duplicateUserErr(err)
+		}
+		audit.Uid = id
+		audit.Ctime = now
+		return tx.Create(&audit).Error
+	})
+}
 
-func (dao *GORMUserDao) FindByWechat(ctx context.Context, openId string) (User, error) {
-	var u User
-	err := dao.db.WithContext(ctx).Where("wechat_open_id=?", openId).First(&u).Error
-	return u, err
-}
\ No newline at end of file
+func (dao *GORMUserDao) Search(ctx context.Context, keyword string,
+	offset int, limit int) ([]User, int64, error) {
+	query := dao.db.WithContext(ctx).Model(&User{})
+	if keyword != "" {
+		prefix := escapeLike(keyword) + "%"
+		cond := dao.db.Where("email LIKE ?", prefix).
+			Or("phone LIKE ?", prefix).
+			Or("nickname LIKE ?", prefix)
+		if id, err := strconv.ParseInt(keyword, 10, 64); err == nil {
+			cond = cond.Or("id = ?", id)
+		}
+		query = query.Where(cond)
+	}
+	// Count 和 Find 共用同一组条件，要开一个新的 Session 才能复用
+	query = query.Session(&gorm.Session{})
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var res []User
+	err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&res).Error
+	return res, total, err
+}
+
+// escapeLike 转义 LIKE 里面的通配符，避免 % 和 _ 把全表都匹配出来
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// UserAuditLog 用户敏感信息变更的审计记录
+type UserAuditLog struct {
+	Id     int64  `gorm:"primaryKey,autoIncrement"`
+	Uid    int64  `gorm:"index"`
+	Action string `gorm:"type=varchar(64)"`
+	Before string `gorm:"type=varchar(256)"`
+	After  string `gorm:"type=varchar(256)"`
+	// Operator 管理员操作的时候是管理员的 id，用户自己操作是 0
+	Operator int64
+	Ctime  int64
+}
@@ -92,3 +92,73 @@ func TestGORMUserDao_Insert(t *testing.T) {
 		})
 	}
 }
+
+func TestGORMUserDao_UpdateSensitive(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(t *testing.T) *sql.DB
+		wantErr error
+	}{
+		{
+			name: "updated with audit log",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `users` SET .*").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO `user_audit_logs` .*").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+				return db
+			},
+		},
+		{
+			name: "email in use",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `users` SET .*").
+					WillReturnError(&mysqlDriver.MySQLError{Number: 1062})
+				mock.ExpectRollback()
+				return db
+			},
+			wantErr: ErrDuplicateEmail,
+		},
+		{
+			name: "phone in use",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `users` SET .*").
+					WillReturnError(&mysqlDriver.MySQLError{Number: 1062,
+						Message: "Duplicate entry '13800000000' for key 'users.phone'"})
+				mock.ExpectRollback()
+				return db
+			},
+			wantErr: ErrDuplicatePhone,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sqlDB := tc.mock(t)
+			db, err := gorm.Open(mysql.New(
+				mysql.Config{
+					Conn:                      sqlDB,
+					SkipInitializeWithVersion: true,
+				}),
+				&gorm.Config{
+					DisableAutomaticPing:   true,
+					SkipDefaultTransaction: true,
+				})
+			assert.NoError(t, err)
+			dao := NewUserDao(db)
+			err = dao.UpdateSensitive(context.Background(), 123, map[string]any{
+				"email": sql.NullString{String: "new@qq.com", Valid: true},
+			}, UserAuditLog{Action: "change_email", Before: "old@qq.com", After: "new@qq.com"})
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
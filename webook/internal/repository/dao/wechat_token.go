@@ -0,0 +1,77 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WechatTokenDAO interface {
+	FindByUid(ctx context.Context, uid int64) (WechatToken, error)
+	// Upsert 一个用户只有一条，重新授权或者刷新之后覆盖
+	Upsert(ctx context.Context, t WechatToken) error
+	// FindExpiring access token 在 before 之前过期、但是 refresh token 还能用的，按照 uid 分页
+	FindExpiring(ctx context.Context, before int64, afterUid int64, limit int) ([]WechatToken, error)
+	Delete(ctx context.Context, uid int64) error
+}
+
+// WechatToken AccessToken 和 RefreshToken 存的都是密文
+type WechatToken struct {
+	Id              int64  `gorm:"primaryKey,autoIncrement"`
+	Uid             int64  `gorm:"unique"`
+	OpenId          string `gorm:"type:varchar(128)"`
+	AccessToken     string `gorm:"type:varchar(1024)"`
+	RefreshToken    string `gorm:"type:varchar(1024)"`
+	AccessExpireAt  int64  `gorm:"index"`
+	RefreshExpireAt int64
+	Ctime           int64
+	Utime           int64
+}
+
+type GORMWechatTokenDAO struct {
+	db *gorm.DB
+}
+
+func NewWechatTokenDAO(db *gorm.DB) WechatTokenDAO {
+	return &GORMWechatTokenDAO{
+		db: db,
+	}
+}
+
+func (dao *GORMWechatTokenDAO) FindByUid(ctx context.Context, uid int64) (WechatToken, error) {
+	var res WechatToken
+	err := dao.db.WithContext(ctx).Where("uid = ?", uid).First(&res).Error
+	return res, err
+}
+
+func (dao *GORMWechatTokenDAO) Upsert(ctx context.Context, t WechatToken) error {
+	now := time.Now().UnixMilli()
+	t.Ctime = now
+	t.Utime = now
+	return dao.db.WithContext(ctx).Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]any{
+			"open_id":           t.OpenId,
+			"access_token":      t.AccessToken,
+			"refresh_token":     t.RefreshToken,
+			"access_expire_at":  t.AccessExpireAt,
+			"refresh_expire_at": t.RefreshExpireAt,
+			"utime":             now,
+		}),
+	}).Create(&t).Error
+}
+
+func (dao *GORMWechatTokenDAO) FindExpiring(ctx context.Context,
+	before int64, afterUid int64, limit int) ([]WechatToken, error) {
+	var res []WechatToken
+	err := dao.db.WithContext(ctx).
+		Where("uid > ? AND access_expire_at < ? AND refresh_expire_at > ?",
+			afterUid, before, time.Now().UnixMilli()).
+		Order("uid").Limit(limit).Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMWechatTokenDAO) Delete(ctx context.Context, uid int64) error {
+	return dao.db.WithContext(ctx).Where("uid = ?", uid).Delete(&WechatToken{}).Error
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"log"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/cache"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/dao"
+	"github.com/ecodeclub/ekit/slice"
+)
+
+type InteractiveRepository interface {
+	BatchIncrReadCnt(ctx context.Context, deltas []domain.ReadCntDelta) error
+	IncrLike(ctx context.Context, biz string, bizId int64, uid int64) error
+	DecrLike(ctx context.Context, biz string, bizId int64, uid int64) error
+	AddCollectionItem(ctx context.Context, biz string, bizId int64, cid int64, uid int64) error
+	Get(ctx context.Context, biz string, bizId int64) (domain.Interactive, error)
+	GetByIds(ctx context.Context, biz string, ids []int64) ([]domain.Interactive, error)
+	Liked(ctx context.Context, biz string, bizId int64, uid int64) (bool, error)
+	Collected(ctx context.Context, biz string, bizId int64, uid int64) (bool, error)
+}
+
+type CachedInteractiveRepository struct {
+	dao   dao.InteractiveDAO
+	cache cache.InteractiveCache
+}
+
+func NewInteractiveRepository(dao dao.InteractiveDAO, cache cache.InteractiveCache) InteractiveRepository {
+	return &CachedInteractiveRepository{
+		dao:   dao,
+		cache: cache,
+	}
+}
+
+func (repo *CachedInteractiveRepository) BatchIncrReadCnt(ctx context.Context, deltas []domain.ReadCntDelta) error {
+	err := repo.dao.BatchIncrReadCnt(ctx, slice.Map(deltas, func(idx int, src domain.ReadCntDelta) dao.ReadCntDelta {
+		return dao.ReadCntDelta{
+			Biz:   src.Biz,
+			BizId: src.BizId,
+			Delta: src.Delta,
+		}
+	}))
+	if err != nil {
+		return err
+	}
+	for _, d := range deltas {
+		err = repo.cache.AddReadCntIfPresent(ctx, d.Biz, d.BizId, d.Delta)
+		if err != nil {
+			log.Println(err)
+		}
+	}
+	return nil
+}
+
+func (repo *CachedInteractiveRepository) IncrLike(ctx context.Context, biz string, bizId int64, uid int64) error {
+	changed, err := repo.dao.InsertLikeInfo(ctx, biz, bizId, uid)
+	if err != nil || !changed {
+		return err
+	}
+	return repo.cache.IncrLikeCntIfPresent(ctx, biz, bizId)
+}
+
+func (repo *CachedInteractiveRepository) DecrLike(ctx context.Context, biz string, bizId int64, uid int64) error {
+	changed, err := repo.dao.DeleteLikeInfo(ctx, biz, bizId, uid)
+	if err != nil || !changed {
+		return err
+	}
+	return repo.cache.DecrLikeCntIfPresent(ctx, biz, bizId)
+}
+
+func (repo *CachedInteractiveRepository) AddCollectionItem(ctx context.Context, biz string, bizId int64, cid int64, uid int64) error {
+	changed, err := repo.dao.InsertCollectionBiz(ctx, dao.UserCollectionBiz{
+		Uid:   uid,
+		BizId: bizId,
+		Biz:   biz,
+		Cid:   cid,
+	})
+	if err != nil || !changed {
+		return err
+	}
+	return repo.cache.IncrCollectCntIfPresent(ctx, biz, bizId)
+}
+
+func (repo *CachedInteractiveRepository) Get(ctx context.Context, biz string, bizId int64) (domain.Interactive, error) {
+	intr, err := repo.cache.Get(ctx, biz, bizId)
+	if err == nil {
+		return intr, nil
+	}
+	ie, err := repo.dao.Get(ctx, biz, bizId)
+	switch err {
+	case nil:
+		intr = repo.toDomain(ie)
+	case dao.ErrRecordNotFound:
+		// 还没有人看过，计数都是 0
+		intr = domain.Interactive{Biz: biz, BizId: bizId}
+	default:
+		return domain.Interactive{}, err
+	}
+	err = repo.cache.Set(ctx, biz, bizId, intr)
+	if err != nil {
+		log.Println(err)
+	}
+	return intr, nil
+}
+
+func (repo *CachedInteractiveRepository) GetByIds(ctx context.Context, biz string, ids []int64) ([]domain.Interactive, error) {
+	intrs, err := repo.dao.GetByIds(ctx, biz, ids)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map(intrs, func(idx int, src dao.Interactive) domain.Interactive {
+		return repo.toDomain(src)
+	}), nil
+}
+
+func (repo *CachedInteractiveRepository) Liked(ctx context.Context, biz string, bizId int64, uid int64) (bool, error) {
+	_, err := repo.dao.GetLikeInfo(ctx, biz, bizId, uid)
+	switch err {
+	case nil:
+		return true, nil
+	case dao.ErrRecordNotFound:
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+func (repo *CachedInteractiveRepository) Collected(ctx context.Context, biz string, bizId int64, uid int64) (bool, error) {
+	_, err := repo.dao.GetCollectionInfo(ctx, biz, bizId, uid)
+	switch err {
+	case nil:
+		return true, nil
+	case dao.ErrRecordNotFound:
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+func (repo *CachedInteractiveRepository) toDomain(ie dao.Interactive) domain.Interactive {
+	return domain.Interactive{
+		Biz:        ie.Biz,
+		BizId:      ie.BizId,
+		ReadCnt:    ie.ReadCnt,
+		LikeCnt:    ie.LikeCnt,
+		CollectCnt: ie.CollectCnt,
+	}
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gitee.com/geekbang/basic-go/webook/internal/repository/cache"
+	cachemocks "gitee.com/geekbang/basic-go/webook/internal/repository/cache/mocks"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/dao"
+	daomocks "gitee.com/geekbang/basic-go/webook/internal/repository/dao/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCachedInteractiveRepository_IncrLike(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) (dao.InteractiveDAO, cache.InteractiveCache)
+		wantErr error
+	}{
+		{
+			name: "first like",
+			mock: func(ctrl *gomock.Controller) (dao.InteractiveDAO, cache.InteractiveCache) {
+				d := daomocks.NewMockInteractiveDAO(ctrl)
+				c := cachemocks.NewMockInteractiveCache(ctrl)
+				d.EXPECT().InsertLikeInfo(gomock.Any(), "article", int64(1), int64(123)).
+					Return(true, nil)
+				c.EXPECT().IncrLikeCntIfPresent(gomock.Any(), "article", int64(1)).
+					Return(nil)
+				return d, c
+			},
+		},
+		{
+			name: "repeated like",
+			mock: func(ctrl *gomock.Controller) (dao.InteractiveDAO, cache.InteractiveCache) {
+				d := daomocks.NewMockInteractiveDAO(ctrl)
+				c := cachemocks.NewMockInteractiveCache(ctrl)
+				// 状态没变，缓存不能再加一
+				d.EXPECT().InsertLikeInfo(gomock.Any(), "article", int64(1), int64(123)).
+					Return(false, nil)
+				return d, c
+			},
+		},
+		{
+			name: "db error",
+			mock: func(ctrl *gomock.Controller) (dao.InteractiveDAO, cache.InteractiveCache) {
+				d := daomocks.NewMockInteractiveDAO(ctrl)
+				c := cachemocks.NewMockInteractiveCache(ctrl)
+				d.EXPECT().InsertLikeInfo(gomock.Any(), "article", int64(1), int64(123)).
+					Return(false, errors.New("db error"))
+				return d, c
+			},
+			wantErr: errors.New("db error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			d, c := tc.mock(ctrl)
+			repo := NewInteractiveRepository(d, c)
+			err := repo.IncrLike(context.Background(), "article", 1, 123)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestCachedInteractiveRepository_DecrLike(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) (dao.InteractiveDAO, cache.InteractiveCache)
+		wantErr error
+	}{
+		{
+			name: "cancel like",
+			mock: func(ctrl *gomock.Controller) (dao.InteractiveDAO, cache.InteractiveCache) {
+				d := daomocks.NewMockInteractiveDAO(ctrl)
+				c := cachemocks.NewMockInteractiveCache(ctrl)
+				d.EXPECT().DeleteLikeInfo(gomock.Any(), "article", int64(1), int64(123)).
+					Return(true, nil)
+				c.EXPECT().DecrLikeCntIfPresent(gomock.Any(), "article", int64(1)).
+					Return(nil)
+				return d, c
+			},
+		},
+		{
+			name: "never liked",
+			mock: func(ctrl *gomock.Controller) (dao.InteractiveDAO, cache.InteractiveCache) {
+				d := daomocks.NewMockInteractiveDAO(ctrl)
+				c := cachemocks.NewMockInteractiveCache(ctrl)
+				d.EXPECT().DeleteLikeInfo(gomock.Any(), "article", int64(1), int64(123)).
+					Return(false, nil)
+				return d, c
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			d, c := tc.mock(ctrl)
+			repo := NewInteractiveRepository(d, c)
+			err := repo.DecrLike(context.Background(), "article", 1, 123)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
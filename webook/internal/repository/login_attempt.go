@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/cache"
+)
+
+type LoginAttemptRepository interface {
+	Reserve(ctx context.Context, subjects []string, policies []domain.LoginGuardPolicy,
+		window time.Duration) ([]int64, time.Duration, bool, error)
+	Release(ctx context.Context, subject string) error
+	Reset(ctx context.Context, subject string) error
+}
+
+// CachedLoginAttemptRepository 失败计数只放 Redis，丢了也就是少限制一会儿
+type CachedLoginAttemptRepository struct {
+	cache cache.LoginAttemptCache
+}
+
+func NewLoginAttemptRepository(cache cache.LoginAttemptCache) LoginAttemptRepository {
+	return &CachedLoginAttemptRepository{
+		cache: cache,
+	}
+}
+
+func (repo *CachedLoginAttemptRepository) Reserve(ctx context.Context, subjects []string, policies []domain.LoginGuardPolicy,
+	window time.Duration) ([]int64, time.Duration, bool, error) {
+	return repo.cache.Reserve(ctx, subjects, policies, window)
+}
+
+func (repo *CachedLoginAttemptRepository) Release(ctx context.Context, subject string) error {
+	return repo.cache.Release(ctx, subject)
+}
+
+func (repo *CachedLoginAttemptRepository) Reset(ctx context.Context, subject string) error {
+	return repo.cache.Reset(ctx, subject)
+}
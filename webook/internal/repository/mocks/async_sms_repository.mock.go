@@ -1,9 +1,9 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: ./async_sms_repository.go
+// Source: ./webook/internal/repository/async_sms_repository.go
 //
 // Generated by this command:
 //
-//	mockgen -source=./async_sms_repository.go -package=repomocks -destination=mocks/async_sms_repository.mock.go AsyncSmsRepository
+//	mockgen -source=./webook/internal/repository/async_sms_repository.go -package=repomocks -destination=./webook/internal/repository/mocks/async_sms_repository.mock.go
 //
 
 // Package repomocks is a generated GoMock package.
@@ -54,6 +54,36 @@ func (mr *MockAsyncSmsRepositoryMockRecorder) Add(ctx, s any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockAsyncSmsRepository)(nil).Add), ctx, s)
 }
 
+// CountByStatus mocks base method.
+func (m *MockAsyncSmsRepository) CountByStatus(ctx context.Context) (map[domain.AsyncSmsStatus]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountByStatus", ctx)
+	ret0, _ := ret[0].(map[domain.AsyncSmsStatus]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountByStatus indicates an expected call of CountByStatus.
+func (mr *MockAsyncSmsRepositoryMockRecorder) CountByStatus(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountByStatus", reflect.TypeOf((*MockAsyncSmsRepository)(nil).CountByStatus), ctx)
+}
+
+// ListByStatus mocks base method.
+func (m *MockAsyncSmsRepository) ListByStatus(ctx context.Context, status domain.AsyncSmsStatus, offset, limit int) ([]domain.AsyncSms, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByStatus", ctx, status, offset, limit)
+	ret0, _ := ret[0].([]domain.AsyncSms)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByStatus indicates an expected call of ListByStatus.
+func (mr *MockAsyncSmsRepositoryMockRecorder) ListByStatus(ctx, status, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByStatus", reflect.TypeOf((*MockAsyncSmsRepository)(nil).ListByStatus), ctx, status, offset, limit)
+}
+
 // PreemptWaitingSMS mocks base method.
 func (m *MockAsyncSmsRepository) PreemptWaitingSMS(ctx context.Context) (domain.AsyncSms, error) {
 	m.ctrl.T.Helper()
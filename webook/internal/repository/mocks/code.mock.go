@@ -13,6 +13,7 @@ import (
 	context "context"
 	reflect "reflect"
 
+	domain "gitee.com/geekbang/basic-go/webook/internal/domain"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -40,30 +41,30 @@ func (m *MockCodeRepository) EXPECT() *MockCodeRepositoryMockRecorder {
 }
 
 // Set mocks base method.
-func (m *MockCodeRepository) Set(ctx context.Context, biz, phone, code string) error {
+func (m *MockCodeRepository) Set(ctx context.Context, channel domain.CodeChannel, biz, target, code string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Set", ctx, biz, phone, code)
+	ret := m.ctrl.Call(m, "Set", ctx, channel, biz, target, code)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Set indicates an expected call of Set.
-func (mr *MockCodeRepositoryMockRecorder) Set(ctx, biz, phone, code any) *gomock.Call {
+func (mr *MockCodeRepositoryMockRecorder) Set(ctx, channel, biz, target, code any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockCodeRepository)(nil).Set), ctx, biz, phone, code)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockCodeRepository)(nil).Set), ctx, channel, biz, target, code)
 }
 
 // Verify mocks base method.
-func (m *MockCodeRepository) Verify(ctx context.Context, biz, phone, code string) (bool, error) {
+func (m *MockCodeRepository) Verify(ctx context.Context, channel domain.CodeChannel, biz, target, code string) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Verify", ctx, biz, phone, code)
+	ret := m.ctrl.Call(m, "Verify", ctx, channel, biz, target, code)
 	ret0, _ := ret[0].(bool)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Verify indicates an expected call of Verify.
-func (mr *MockCodeRepositoryMockRecorder) Verify(ctx, biz, phone, code any) *gomock.Call {
+func (mr *MockCodeRepositoryMockRecorder) Verify(ctx, channel, biz, target, code any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockCodeRepository)(nil).Verify), ctx, biz, phone, code)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockCodeRepository)(nil).Verify), ctx, channel, biz, target, code)
 }
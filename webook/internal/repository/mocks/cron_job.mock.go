@@ -0,0 +1,100 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./webook/internal/repository/cron_job.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./webook/internal/repository/cron_job.go -package=repomocks -destination=./webook/internal/repository/mocks/cron_job.mock.go
+//
+
+// Package repomocks is a generated GoMock package.
+package repomocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	domain "gitee.com/geekbang/basic-go/webook/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockCronJobRepository is a mock of CronJobRepository interface.
+type MockCronJobRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockCronJobRepositoryMockRecorder
+}
+
+// MockCronJobRepositoryMockRecorder is the mock recorder for MockCronJobRepository.
+type MockCronJobRepositoryMockRecorder struct {
+	mock *MockCronJobRepository
+}
+
+// NewMockCronJobRepository creates a new mock instance.
+func NewMockCronJobRepository(ctrl *gomock.Controller) *MockCronJobRepository {
+	mock := &MockCronJobRepository{ctrl: ctrl}
+	mock.recorder = &MockCronJobRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCronJobRepository) EXPECT() *MockCronJobRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockCronJobRepository) Create(ctx context.Context, j domain.CronJob) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, j)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockCronJobRepositoryMockRecorder) Create(ctx, j any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockCronJobRepository)(nil).Create), ctx, j)
+}
+
+// Preempt mocks base method.
+func (m *MockCronJobRepository) Preempt(ctx context.Context, expiration time.Duration) (domain.CronJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Preempt", ctx, expiration)
+	ret0, _ := ret[0].(domain.CronJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Preempt indicates an expected call of Preempt.
+func (mr *MockCronJobRepositoryMockRecorder) Preempt(ctx, expiration any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Preempt", reflect.TypeOf((*MockCronJobRepository)(nil).Preempt), ctx, expiration)
+}
+
+// Release mocks base method.
+func (m *MockCronJobRepository) Release(ctx context.Context, id, version int64, nextTime time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Release", ctx, id, version, nextTime)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Release indicates an expected call of Release.
+func (mr *MockCronJobRepositoryMockRecorder) Release(ctx, id, version, nextTime any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Release", reflect.TypeOf((*MockCronJobRepository)(nil).Release), ctx, id, version, nextTime)
+}
+
+// UpdateUtime mocks base method.
+func (m *MockCronJobRepository) UpdateUtime(ctx context.Context, id, version int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUtime", ctx, id, version)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUtime indicates an expected call of UpdateUtime.
+func (mr *MockCronJobRepositoryMockRecorder) UpdateUtime(ctx, id, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUtime", reflect.TypeOf((*MockCronJobRepository)(nil).UpdateUtime), ctx, id, version)
+}
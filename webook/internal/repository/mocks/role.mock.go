@@ -0,0 +1,99 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./webook/internal/repository/role.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./webook/internal/repository/role.go -package=repomocks -destination=./webook/internal/repository/mocks/role.mock.go
+//
+
+// Package repomocks is a generated GoMock package.
+package repomocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "gitee.com/geekbang/basic-go/webook/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockRoleRepository is a mock of RoleRepository interface.
+type MockRoleRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRoleRepositoryMockRecorder
+}
+
+// MockRoleRepositoryMockRecorder is the mock recorder for MockRoleRepository.
+type MockRoleRepositoryMockRecorder struct {
+	mock *MockRoleRepository
+}
+
+// NewMockRoleRepository creates a new mock instance.
+func NewMockRoleRepository(ctrl *gomock.Controller) *MockRoleRepository {
+	mock := &MockRoleRepository{ctrl: ctrl}
+	mock.recorder = &MockRoleRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRoleRepository) EXPECT() *MockRoleRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindAuthz mocks base method.
+func (m *MockRoleRepository) FindAuthz(ctx context.Context, uid int64) (domain.Authz, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAuthz", ctx, uid)
+	ret0, _ := ret[0].(domain.Authz)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAuthz indicates an expected call of FindAuthz.
+func (mr *MockRoleRepositoryMockRecorder) FindAuthz(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAuthz", reflect.TypeOf((*MockRoleRepository)(nil).FindAuthz), ctx, uid)
+}
+
+// FindPermissions mocks base method.
+func (m *MockRoleRepository) FindPermissions(ctx context.Context, role domain.Role) ([]domain.Permission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPermissions", ctx, role)
+	ret0, _ := ret[0].([]domain.Permission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPermissions indicates an expected call of FindPermissions.
+func (mr *MockRoleRepositoryMockRecorder) FindPermissions(ctx, role any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPermissions", reflect.TypeOf((*MockRoleRepository)(nil).FindPermissions), ctx, role)
+}
+
+// GrantRole mocks base method.
+func (m *MockRoleRepository) GrantRole(ctx context.Context, uid int64, role domain.Role, audit domain.UserAudit) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GrantRole", ctx, uid, role, audit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GrantRole indicates an expected call of GrantRole.
+func (mr *MockRoleRepositoryMockRecorder) GrantRole(ctx, uid, role, audit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GrantRole", reflect.TypeOf((*MockRoleRepository)(nil).GrantRole), ctx, uid, role, audit)
+}
+
+// RevokeRole mocks base method.
+func (m *MockRoleRepository) RevokeRole(ctx context.Context, uid int64, role domain.Role, audit domain.UserAudit) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeRole", ctx, uid, role, audit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeRole indicates an expected call of RevokeRole.
+func (mr *MockRoleRepositoryMockRecorder) RevokeRole(ctx, uid, role, audit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRole", reflect.TypeOf((*MockRoleRepository)(nil).RevokeRole), ctx, uid, role, audit)
+}
@@ -0,0 +1,170 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./webook/internal/repository/two_factor.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./webook/internal/repository/two_factor.go -package=repomocks -destination=./webook/internal/repository/mocks/two_factor.mock.go
+//
+
+// Package repomocks is a generated GoMock package.
+package repomocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "gitee.com/geekbang/basic-go/webook/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockTwoFactorRepository is a mock of TwoFactorRepository interface.
+type MockTwoFactorRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTwoFactorRepositoryMockRecorder
+}
+
+// MockTwoFactorRepositoryMockRecorder is the mock recorder for MockTwoFactorRepository.
+type MockTwoFactorRepositoryMockRecorder struct {
+	mock *MockTwoFactorRepository
+}
+
+// NewMockTwoFactorRepository creates a new mock instance.
+func NewMockTwoFactorRepository(ctrl *gomock.Controller) *MockTwoFactorRepository {
+	mock := &MockTwoFactorRepository{ctrl: ctrl}
+	mock.recorder = &MockTwoFactorRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTwoFactorRepository) EXPECT() *MockTwoFactorRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DelChallenge mocks base method.
+func (m *MockTwoFactorRepository) DelChallenge(ctx context.Context, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DelChallenge", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DelChallenge indicates an expected call of DelChallenge.
+func (mr *MockTwoFactorRepositoryMockRecorder) DelChallenge(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DelChallenge", reflect.TypeOf((*MockTwoFactorRepository)(nil).DelChallenge), ctx, token)
+}
+
+// Enable mocks base method.
+func (m *MockTwoFactorRepository) Enable(ctx context.Context, uid, step int64, recoveryCodes []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enable", ctx, uid, step, recoveryCodes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Enable indicates an expected call of Enable.
+func (mr *MockTwoFactorRepositoryMockRecorder) Enable(ctx, uid, step, recoveryCodes any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enable", reflect.TypeOf((*MockTwoFactorRepository)(nil).Enable), ctx, uid, step, recoveryCodes)
+}
+
+// FindByUid mocks base method.
+func (m *MockTwoFactorRepository) FindByUid(ctx context.Context, uid int64) (domain.TwoFactor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByUid", ctx, uid)
+	ret0, _ := ret[0].(domain.TwoFactor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByUid indicates an expected call of FindByUid.
+func (mr *MockTwoFactorRepositoryMockRecorder) FindByUid(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByUid", reflect.TypeOf((*MockTwoFactorRepository)(nil).FindByUid), ctx, uid)
+}
+
+// GetChallenge mocks base method.
+func (m *MockTwoFactorRepository) GetChallenge(ctx context.Context, token string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChallenge", ctx, token)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChallenge indicates an expected call of GetChallenge.
+func (mr *MockTwoFactorRepositoryMockRecorder) GetChallenge(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChallenge", reflect.TypeOf((*MockTwoFactorRepository)(nil).GetChallenge), ctx, token)
+}
+
+// PeekChallenge mocks base method.
+func (m *MockTwoFactorRepository) PeekChallenge(ctx context.Context, token string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PeekChallenge", ctx, token)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PeekChallenge indicates an expected call of PeekChallenge.
+func (mr *MockTwoFactorRepositoryMockRecorder) PeekChallenge(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PeekChallenge", reflect.TypeOf((*MockTwoFactorRepository)(nil).PeekChallenge), ctx, token)
+}
+
+// Save mocks base method.
+func (m *MockTwoFactorRepository) Save(ctx context.Context, tf domain.TwoFactor) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, tf)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockTwoFactorRepositoryMockRecorder) Save(ctx, tf any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockTwoFactorRepository)(nil).Save), ctx, tf)
+}
+
+// SetChallenge mocks base method.
+func (m *MockTwoFactorRepository) SetChallenge(ctx context.Context, token string, uid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetChallenge", ctx, token, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetChallenge indicates an expected call of SetChallenge.
+func (mr *MockTwoFactorRepositoryMockRecorder) SetChallenge(ctx, token, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetChallenge", reflect.TypeOf((*MockTwoFactorRepository)(nil).SetChallenge), ctx, token, uid)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockTwoFactorRepository) UseRecoveryCode(ctx context.Context, uid int64, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", ctx, uid, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockTwoFactorRepositoryMockRecorder) UseRecoveryCode(ctx, uid, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockTwoFactorRepository)(nil).UseRecoveryCode), ctx, uid, code)
+}
+
+// UseStep mocks base method.
+func (m *MockTwoFactorRepository) UseStep(ctx context.Context, uid, step int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseStep", ctx, uid, step)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseStep indicates an expected call of UseStep.
+func (mr *MockTwoFactorRepositoryMockRecorder) UseStep(ctx, uid, step any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseStep", reflect.TypeOf((*MockTwoFactorRepository)(nil).UseStep), ctx, uid, step)
+}
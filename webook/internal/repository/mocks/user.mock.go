@@ -12,6 +12,7 @@ package repomocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	domain "gitee.com/geekbang/basic-go/webook/internal/domain"
 	gomock "go.uber.org/mock/gomock"
@@ -40,6 +41,20 @@ func (m *MockUserRepository) EXPECT() *MockUserRepositoryMockRecorder {
 	return m.recorder
 }
 
+// BindIdentity mocks base method.
+func (m *MockUserRepository) BindIdentity(ctx context.Context, uid int64, identity domain.Identity, audit domain.UserAudit) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BindIdentity", ctx, uid, identity, audit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BindIdentity indicates an expected call of BindIdentity.
+func (mr *MockUserRepositoryMockRecorder) BindIdentity(ctx, uid, identity, audit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BindIdentity", reflect.TypeOf((*MockUserRepository)(nil).BindIdentity), ctx, uid, identity, audit)
+}
+
 // Create mocks base method.
 func (m *MockUserRepository) Create(ctx context.Context, user domain.User) error {
 	m.ctrl.T.Helper()
@@ -54,6 +69,21 @@ func (mr *MockUserRepositoryMockRecorder) Create(ctx, user any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockUserRepository)(nil).Create), ctx, user)
 }
 
+// CreateWithIdentity mocks base method.
+func (m *MockUserRepository) CreateWithIdentity(ctx context.Context, user domain.User, identity domain.Identity) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWithIdentity", ctx, user, identity)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWithIdentity indicates an expected call of CreateWithIdentity.
+func (mr *MockUserRepositoryMockRecorder) CreateWithIdentity(ctx, user, identity any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWithIdentity", reflect.TypeOf((*MockUserRepository)(nil).CreateWithIdentity), ctx, user, identity)
+}
+
 // FindByEmail mocks base method.
 func (m *MockUserRepository) FindByEmail(ctx context.Context, email string) (domain.User, error) {
 	m.ctrl.T.Helper()
@@ -84,6 +114,21 @@ func (mr *MockUserRepositoryMockRecorder) FindById(ctx, uid any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindById", reflect.TypeOf((*MockUserRepository)(nil).FindById), ctx, uid)
 }
 
+// FindByIdentity mocks base method.
+func (m *MockUserRepository) FindByIdentity(ctx context.Context, provider, subject string) (domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByIdentity", ctx, provider, subject)
+	ret0, _ := ret[0].(domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByIdentity indicates an expected call of FindByIdentity.
+func (mr *MockUserRepositoryMockRecorder) FindByIdentity(ctx, provider, subject any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByIdentity", reflect.TypeOf((*MockUserRepository)(nil).FindByIdentity), ctx, provider, subject)
+}
+
 // FindByPhone mocks base method.
 func (m *MockUserRepository) FindByPhone(ctx context.Context, phone string) (domain.User, error) {
 	m.ctrl.T.Helper()
@@ -99,19 +144,76 @@ func (mr *MockUserRepositoryMockRecorder) FindByPhone(ctx, phone any) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByPhone", reflect.TypeOf((*MockUserRepository)(nil).FindByPhone), ctx, phone)
 }
 
-// FindByWechat mocks base method.
-func (m *MockUserRepository) FindByWechat(ctx context.Context, openId string) (domain.User, error) {
+// MarkEmailVerified mocks base method.
+func (m *MockUserRepository) MarkEmailVerified(ctx context.Context, uid int64, audit domain.UserAudit) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindByWechat", ctx, openId)
-	ret0, _ := ret[0].(domain.User)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "MarkEmailVerified", ctx, uid, audit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkEmailVerified indicates an expected call of MarkEmailVerified.
+func (mr *MockUserRepositoryMockRecorder) MarkEmailVerified(ctx, uid, audit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkEmailVerified", reflect.TypeOf((*MockUserRepository)(nil).MarkEmailVerified), ctx, uid, audit)
+}
+
+// Search mocks base method.
+func (m *MockUserRepository) Search(ctx context.Context, q domain.UserQuery) ([]domain.User, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, q)
+	ret0, _ := ret[0].([]domain.User)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockUserRepositoryMockRecorder) Search(ctx, q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockUserRepository)(nil).Search), ctx, q)
+}
+
+// UnbindIdentity mocks base method.
+func (m *MockUserRepository) UnbindIdentity(ctx context.Context, uid int64, provider string, audit domain.UserAudit) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnbindIdentity", ctx, uid, provider, audit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnbindIdentity indicates an expected call of UnbindIdentity.
+func (mr *MockUserRepositoryMockRecorder) UnbindIdentity(ctx, uid, provider, audit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnbindIdentity", reflect.TypeOf((*MockUserRepository)(nil).UnbindIdentity), ctx, uid, provider, audit)
+}
+
+// UpdateBannedAt mocks base method.
+func (m *MockUserRepository) UpdateBannedAt(ctx context.Context, uid int64, bannedAt time.Time, audit domain.UserAudit) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateBannedAt", ctx, uid, bannedAt, audit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateBannedAt indicates an expected call of UpdateBannedAt.
+func (mr *MockUserRepositoryMockRecorder) UpdateBannedAt(ctx, uid, bannedAt, audit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBannedAt", reflect.TypeOf((*MockUserRepository)(nil).UpdateBannedAt), ctx, uid, bannedAt, audit)
+}
+
+// UpdateEmail mocks base method.
+func (m *MockUserRepository) UpdateEmail(ctx context.Context, uid int64, email string, audit domain.UserAudit) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateEmail", ctx, uid, email, audit)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// FindByWechat indicates an expected call of FindByWechat.
-func (mr *MockUserRepositoryMockRecorder) FindByWechat(ctx, openId any) *gomock.Call {
+// UpdateEmail indicates an expected call of UpdateEmail.
+func (mr *MockUserRepositoryMockRecorder) UpdateEmail(ctx, uid, email, audit any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByWechat", reflect.TypeOf((*MockUserRepository)(nil).FindByWechat), ctx, openId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateEmail", reflect.TypeOf((*MockUserRepository)(nil).UpdateEmail), ctx, uid, email, audit)
 }
 
 // UpdateNonZeroFields mocks base method.
@@ -127,3 +229,31 @@ func (mr *MockUserRepositoryMockRecorder) UpdateNonZeroFields(ctx, user any) *go
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateNonZeroFields", reflect.TypeOf((*MockUserRepository)(nil).UpdateNonZeroFields), ctx, user)
 }
+
+// UpdatePassword mocks base method.
+func (m *MockUserRepository) UpdatePassword(ctx context.Context, uid int64, password string, audit domain.UserAudit) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePassword", ctx, uid, password, audit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePassword indicates an expected call of UpdatePassword.
+func (mr *MockUserRepositoryMockRecorder) UpdatePassword(ctx, uid, password, audit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockUserRepository)(nil).UpdatePassword), ctx, uid, password, audit)
+}
+
+// UpdatePhone mocks base method.
+func (m *MockUserRepository) UpdatePhone(ctx context.Context, uid int64, phone string, audit domain.UserAudit) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePhone", ctx, uid, phone, audit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePhone indicates an expected call of UpdatePhone.
+func (mr *MockUserRepositoryMockRecorder) UpdatePhone(ctx, uid, phone, audit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePhone", reflect.TypeOf((*MockUserRepository)(nil).UpdatePhone), ctx, uid, phone, audit)
+}
@@ -0,0 +1,100 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./webook/internal/repository/wechat_token.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./webook/internal/repository/wechat_token.go -package=repomocks -destination=./webook/internal/repository/mocks/wechat_token.mock.go
+//
+
+// Package repomocks is a generated GoMock package.
+package repomocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	domain "gitee.com/geekbang/basic-go/webook/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockWechatTokenRepository is a mock of WechatTokenRepository interface.
+type MockWechatTokenRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockWechatTokenRepositoryMockRecorder
+}
+
+// MockWechatTokenRepositoryMockRecorder is the mock recorder for MockWechatTokenRepository.
+type MockWechatTokenRepositoryMockRecorder struct {
+	mock *MockWechatTokenRepository
+}
+
+// NewMockWechatTokenRepository creates a new mock instance.
+func NewMockWechatTokenRepository(ctrl *gomock.Controller) *MockWechatTokenRepository {
+	mock := &MockWechatTokenRepository{ctrl: ctrl}
+	mock.recorder = &MockWechatTokenRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWechatTokenRepository) EXPECT() *MockWechatTokenRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockWechatTokenRepository) Delete(ctx context.Context, uid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockWechatTokenRepositoryMockRecorder) Delete(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockWechatTokenRepository)(nil).Delete), ctx, uid)
+}
+
+// FindByUid mocks base method.
+func (m *MockWechatTokenRepository) FindByUid(ctx context.Context, uid int64) (domain.WechatToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByUid", ctx, uid)
+	ret0, _ := ret[0].(domain.WechatToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByUid indicates an expected call of FindByUid.
+func (mr *MockWechatTokenRepositoryMockRecorder) FindByUid(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByUid", reflect.TypeOf((*MockWechatTokenRepository)(nil).FindByUid), ctx, uid)
+}
+
+// FindExpiring mocks base method.
+func (m *MockWechatTokenRepository) FindExpiring(ctx context.Context, before time.Time, afterUid int64, limit int) ([]domain.WechatToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindExpiring", ctx, before, afterUid, limit)
+	ret0, _ := ret[0].([]domain.WechatToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindExpiring indicates an expected call of FindExpiring.
+func (mr *MockWechatTokenRepositoryMockRecorder) FindExpiring(ctx, before, afterUid, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindExpiring", reflect.TypeOf((*MockWechatTokenRepository)(nil).FindExpiring), ctx, before, afterUid, limit)
+}
+
+// Save mocks base method.
+func (m *MockWechatTokenRepository) Save(ctx context.Context, t domain.WechatToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, t)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockWechatTokenRepositoryMockRecorder) Save(ctx, t any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockWechatTokenRepository)(nil).Save), ctx, t)
+}
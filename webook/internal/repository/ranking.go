@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"log"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/cache"
+)
+
+type RankingRepository interface {
+	ReplaceTopN(ctx context.Context, arts []domain.Article) error
+	GetTopN(ctx context.Context) ([]domain.Article, error)
+}
+
+// CachedRankingRepository 热榜只存在缓存里面，先查本地缓存，再查 Redis
+type CachedRankingRepository struct {
+	redis cache.RankingCache
+	local *cache.RankingLocalCache
+}
+
+func NewRankingRepository(redis cache.RankingCache, local *cache.RankingLocalCache) RankingRepository {
+	return &CachedRankingRepository{
+		redis: redis,
+		local: local,
+	}
+}
+
+func (repo *CachedRankingRepository) ReplaceTopN(ctx context.Context, arts []domain.Article) error {
+	// 本地缓存不会失败
+	_ = repo.local.Set(ctx, arts)
+	return repo.redis.Set(ctx, arts)
+}
+
+func (repo *CachedRankingRepository) GetTopN(ctx context.Context) ([]domain.Article, error) {
+	arts, err := repo.local.Get(ctx)
+	if err == nil {
+		return arts, nil
+	}
+	arts, err = repo.redis.Get(ctx)
+	if err != nil {
+		// Redis 出问题了，过期的本地缓存也比没有好
+		log.Println("get ranking from redis error", err)
+		return repo.local.ForceGet(ctx)
+	}
+	_ = repo.local.Set(ctx, arts)
+	return arts, nil
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/cache"
+	cachemocks "gitee.com/geekbang/basic-go/webook/internal/repository/cache/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCachedRankingRepository_GetTopN(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) cache.RankingCache
+		local    func() *cache.RankingLocalCache
+		wantArts []domain.Article
+		wantErr  error
+	}{
+		{
+			name: "hit local",
+			mock: func(ctrl *gomock.Controller) cache.RankingCache {
+				return cachemocks.NewMockRankingCache(ctrl)
+			},
+			local: func() *cache.RankingLocalCache {
+				lc := cache.NewRankingLocalCache()
+				_ = lc.Set(context.Background(), []domain.Article{{Id: 1}})
+				return lc
+			},
+			wantArts: []domain.Article{{Id: 1}},
+		},
+		{
+			name: "miss local, hit redis",
+			mock: func(ctrl *gomock.Controller) cache.RankingCache {
+				c := cachemocks.NewMockRankingCache(ctrl)
+				c.EXPECT().Get(gomock.Any()).Return([]domain.Article{{Id: 2}}, nil)
+				return c
+			},
+			local:    cache.NewRankingLocalCache,
+			wantArts: []domain.Article{{Id: 2}},
+		},
+		{
+			name: "redis down, no local",
+			mock: func(ctrl *gomock.Controller) cache.RankingCache {
+				c := cachemocks.NewMockRankingCache(ctrl)
+				c.EXPECT().Get(gomock.Any()).Return(nil, errors.New("redis down"))
+				return c
+			},
+			local:   cache.NewRankingLocalCache,
+			wantErr: cache.ErrRankingLocalMiss,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo := NewRankingRepository(tc.mock(ctrl), tc.local())
+			arts, err := repo.GetTopN(context.Background())
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantArts, arts)
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/dao"
+)
+
+type RoleRepository interface {
+	// FindAuthz 用户的角色，以及这些角色的权限去重之后的并集
+	FindAuthz(ctx context.Context, uid int64) (domain.Authz, error)
+	// FindPermissions 角色不存在的时候返回空
+	FindPermissions(ctx context.Context, role domain.Role) ([]domain.Permission, error)
+	GrantRole(ctx context.Context, uid int64, role domain.Role, audit domain.UserAudit) error
+	RevokeRole(ctx context.Context, uid int64, role domain.Role, audit domain.UserAudit) error
+}
+
+// RoleDBRepository 只在下发 token 和访问管理接口的时候查，量不大，所以不加缓存
+type RoleDBRepository struct {
+	dao dao.RoleDAO
+}
+
+func NewRoleRepository(dao dao.RoleDAO) RoleRepository {
+	return &RoleDBRepository{
+		dao: dao,
+	}
+}
+
+func (repo *RoleDBRepository) FindAuthz(ctx context.Context, uid int64) (domain.Authz, error) {
+	roles, err := repo.dao.FindRolesByUid(ctx, uid)
+	if err != nil {
+		return domain.Authz{}, err
+	}
+	var res domain.Authz
+	names := make([]string, 0, len(roles))
+	for _, r := range roles {
+		res.Roles = append(res.Roles, domain.Role(r.Role))
+		names = append(names, r.Role)
+	}
+	perms, err := repo.dao.FindPermissions(ctx, names)
+	if err != nil {
+		return domain.Authz{}, err
+	}
+	seen := make(map[string]struct{}, len(perms))
+	for _, p := range perms {
+		if _, ok := seen[p.Permission]; ok {
+			continue
+		}
+		seen[p.Permission] = struct{}{}
+		res.Permissions = append(res.Permissions, domain.Permission(p.Permission))
+	}
+	return res, nil
+}
+
+func (repo *RoleDBRepository) FindPermissions(ctx context.Context,
+	role domain.Role) ([]domain.Permission, error) {
+	perms, err := repo.dao.FindPermissions(ctx, []string{string(role)})
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.Permission, 0, len(perms))
+	for _, p := range perms {
+		res = append(res, domain.Permission(p.Permission))
+	}
+	return res, nil
+}
+
+func (repo *RoleDBRepository) GrantRole(ctx context.Context, uid int64,
+	role domain.Role, audit domain.UserAudit) error {
+	return repo.dao.InsertUserRole(ctx, dao.UserRole{
+		Uid:  uid,
+		Role: string(role),
+	}, repo.auditToEntity(audit))
+}
+
+func (repo *RoleDBRepository) RevokeRole(ctx context.Context, uid int64,
+	role domain.Role, audit domain.UserAudit) error {
+	return repo.dao.DeleteUserRole(ctx, uid, string(role), repo.auditToEntity(audit))
+}
+
+func (repo *RoleDBRepository) auditToEntity(audit domain.UserAudit) dao.UserAuditLog {
+	return dao.UserAuditLog{
+		Action:   string(audit.Action),
+		Before:   audit.Before,
+		After:    audit.After,
+		Operator: audit.Operator,
+	}
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/dao"
+	daomocks "gitee.com/geekbang/basic-go/webook/internal/repository/dao/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRoleDBRepository_FindAuthz(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	d := daomocks.NewMockRoleDAO(ctrl)
+	d.EXPECT().FindRolesByUid(gomock.Any(), int64(123)).Return([]dao.UserRole{
+		{Uid: 123, Role: "admin"},
+		{Uid: 123, Role: "auditor"},
+	}, nil)
+	// 两个角色都有 user:read，只留一个
+	d.EXPECT().FindPermissions(gomock.Any(), []string{"admin", "auditor"}).Return([]dao.RolePermission{
+		{Role: "admin", Permission: "user:read"},
+		{Role: "admin", Permission: "user:ban"},
+		{Role: "auditor", Permission: "user:read"},
+	}, nil)
+
+	authz, err := NewRoleRepository(d).FindAuthz(context.Background(), 123)
+	require.NoError(t, err)
+	assert.Equal(t, domain.Authz{
+		Roles:       []domain.Role{"admin", "auditor"},
+		Permissions: []domain.Permission{domain.PermissionUserRead, domain.PermissionUserBan},
+	}, authz)
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"sort"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/cache"
+)
+
+type SessionRepository interface {
+	Add(ctx context.Context, s domain.Session) error
+	// List 按照登录时间倒序
+	List(ctx context.Context, uid int64) ([]domain.Session, error)
+	Remove(ctx context.Context, uid int64, ssid string) (bool, error)
+}
+
+// CachedSessionRepository 会话只存在 Redis 里面，和 refresh token 一起过期
+type CachedSessionRepository struct {
+	cache cache.SessionCache
+}
+
+func NewSessionRepository(cache cache.SessionCache) SessionRepository {
+	return &CachedSessionRepository{
+		cache: cache,
+	}
+}
+
+func (repo *CachedSessionRepository) Add(ctx context.Context, s domain.Session) error {
+	return repo.cache.Add(ctx, s)
+}
+
+func (repo *CachedSessionRepository) List(ctx context.Context, uid int64) ([]domain.Session, error) {
+	sessions, err := repo.cache.List(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].Ctime.After(sessions[j].Ctime)
+	})
+	return sessions, nil
+}
+
+func (repo *CachedSessionRepository) Remove(ctx context.Context, uid int64, ssid string) (bool, error) {
+	return repo.cache.Del(ctx, uid, ssid)
+}
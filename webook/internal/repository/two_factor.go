@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/cache"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/dao"
+	"gitee.com/geekbang/basic-go/webook/pkg/cryptox"
+)
+
+var (
+	ErrTwoFactorNotFound    = dao.ErrRecordNotFound
+	ErrTOTPStepUsed         = dao.ErrTOTPStepUsed
+	ErrRecoveryCodeNotFound = dao.ErrRecoveryCodeNotFound
+	ErrChallengeNotFound    = cache.ErrChallengeNotFound
+)
+
+type TwoFactorRepository interface {
+	FindByUid(ctx context.Context, uid int64) (domain.TwoFactor, error)
+	Save(ctx context.Context, tf domain.TwoFactor) error
+	// Enable recoveryCodes 是哈希过的
+	Enable(ctx context.Context, uid int64, step int64, recoveryCodes []string) error
+	UseStep(ctx context.Context, uid int64, step int64) error
+	UseRecoveryCode(ctx context.Context, uid int64, code string) error
+
+	SetChallenge(ctx context.Context, token string, uid int64) error
+	GetChallenge(ctx context.Context, token string) (int64, error)
+	PeekChallenge(ctx context.Context, token string) (int64, error)
+	DelChallenge(ctx context.Context, token string) error
+}
+
+// CachedTwoFactorRepository 配置和恢复码在数据库，登录挑战在 Redis。
+// TOTP 密钥加密之后才落库，拖库了也生成不了 code
+type CachedTwoFactorRepository struct {
+	dao    dao.TwoFactorDAO
+	cache  cache.TwoFactorCache
+	cipher *cryptox.AESGCM
+}
+
+func NewTwoFactorRepository(dao dao.TwoFactorDAO, cache cache.TwoFactorCache,
+	cipher *cryptox.AESGCM) TwoFactorRepository {
+	return &CachedTwoFactorRepository{
+		dao:    dao,
+		cache:  cache,
+		cipher: cipher,
+	}
+}
+
+func (repo *CachedTwoFactorRepository) FindByUid(ctx context.Context, uid int64) (domain.TwoFactor, error) {
+	tf, err := repo.dao.FindByUid(ctx, uid)
+	if err != nil {
+		return domain.TwoFactor{}, err
+	}
+	secret, err := repo.cipher.Decrypt(tf.Secret)
+	if err != nil {
+		return domain.TwoFactor{}, err
+	}
+	return domain.TwoFactor{
+		Uid:      tf.Uid,
+		Secret:   secret,
+		Enabled:  tf.Enabled,
+		LastStep: tf.LastStep,
+	}, nil
+}
+
+func (repo *CachedTwoFactorRepository) Save(ctx context.Context, tf domain.TwoFactor) error {
+	secret, err := repo.cipher.Encrypt(tf.Secret)
+	if err != nil {
+		return err
+	}
+	return repo.dao.Upsert(ctx, dao.UserTwoFactor{
+		Uid:      tf.Uid,
+		Secret:   secret,
+		Enabled:  tf.Enabled,
+		LastStep: tf.LastStep,
+	})
+}
+
+func (repo *CachedTwoFactorRepository) Enable(ctx context.Context, uid int64, step int64, recoveryCodes []string) error {
+	return repo.dao.Enable(ctx, uid, step, recoveryCodes)
+}
+
+func (repo *CachedTwoFactorRepository) UseStep(ctx context.Context, uid int64, step int64) error {
+	return repo.dao.UseStep(ctx, uid, step)
+}
+
+func (repo *CachedTwoFactorRepository) UseRecoveryCode(ctx context.Context, uid int64, code string) error {
+	return repo.dao.UseRecoveryCode(ctx, uid, code)
+}
+
+func (repo *CachedTwoFactorRepository) SetChallenge(ctx context.Context, token string, uid int64) error {
+	return repo.cache.SetChallenge(ctx, token, uid)
+}
+
+func (repo *CachedTwoFactorRepository) GetChallenge(ctx context.Context, token string) (int64, error) {
+	return repo.cache.GetChallenge(ctx, token)
+}
+
+func (repo *CachedTwoFactorRepository) PeekChallenge(ctx context.Context, token string) (int64, error) {
+	return repo.cache.PeekChallenge(ctx, token)
+}
+
+func (repo *CachedTwoFactorRepository) DelChallenge(ctx context.Context, token string) error {
+	return repo.cache.DelChallenge(ctx, token)
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	cachemocks "gitee.com/geekbang/basic-go/webook/internal/repository/cache/mocks"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/dao"
+	daomocks "gitee.com/geekbang/basic-go/webook/internal/repository/dao/mocks"
+	"gitee.com/geekbang/basic-go/webook/pkg/cryptox"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCachedTwoFactorRepository_Secret(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cipher, err := cryptox.NewAESGCM([]byte("0123456789abcdef0123456789abcdef"))
+	require.NoError(t, err)
+	d := daomocks.NewMockTwoFactorDAO(ctrl)
+	repo := NewTwoFactorRepository(d, cachemocks.NewMockTwoFactorCache(ctrl), cipher)
+
+	tf := domain.TwoFactor{
+		Uid:    123,
+		Secret: "JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP",
+	}
+	var saved dao.UserTwoFactor
+	d.EXPECT().Upsert(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, tf dao.UserTwoFactor) error {
+			saved = tf
+			return nil
+		})
+	require.NoError(t, repo.Save(context.Background(), tf))
+	// 数据库里面不能有明文
+	assert.NotContains(t, saved.Secret, tf.Secret)
+
+	d.EXPECT().FindByUid(gomock.Any(), int64(123)).Return(saved, nil)
+	got, err := repo.FindByUid(context.Background(), 123)
+	require.NoError(t, err)
+	assert.Equal(t, tf, got)
+}
@@ -13,8 +13,11 @@ import (
 )
 
 var (
-	ErrDuplicateUser = dao.ErrDuplicateEmail
-	ErrUserNotFound  = dao.ErrRecordNotFound
+	ErrDuplicateEmail = dao.ErrDuplicateEmail
+	ErrDuplicatePhone = dao.ErrDuplicatePhone
+	ErrUserNotFound   = dao.ErrRecordNotFound
+	// ErrDuplicateIdentity 第三方账号已经绑定了别的用户
+	ErrDuplicateIdentity = dao.ErrDuplicateIdentity
 )
 
 type UserRepository interface {
@@ -26,18 +29,39 @@ type UserRepository interface {
 		user domain.User) error
 	FindById(ctx context.Context, uid int64) (domain.User, error)
 	FindByPhone(ctx context.Context, phone string) (domain.User, error)
-	FindByWechat(ctx context.Context, openId string) (domain.User, error)
+	// FindByIdentity 只会填 Id，要完整的用户再调 FindById
+	FindByIdentity(ctx context.Context, provider string, subject string) (domain.User, error)
+	// CreateWithIdentity 第三方第一次登录，返回新用户的 id
+	CreateWithIdentity(ctx context.Context, user domain.User, identity domain.Identity) (int64, error)
+	// UpdatePassword password 是已经哈希过的密码
+	UpdatePassword(ctx context.Context, uid int64, password string, audit domain.UserAudit) error
+	// UpdateEmail 邮箱已经被别人用了返回 ErrDuplicateEmail
+	UpdateEmail(ctx context.Context, uid int64, email string, audit domain.UserAudit) error
+	// MarkEmailVerified 当前邮箱验证通过
+	MarkEmailVerified(ctx context.Context, uid int64, audit domain.UserAudit) error
+	// UpdatePhone 手机号已经被别人用了返回 ErrDuplicatePhone，phone 为空就是解绑
+	UpdatePhone(ctx context.Context, uid int64, phone string, audit domain.UserAudit) error
+	// BindIdentity 第三方账号已经绑定了别的用户返回 ErrDuplicateIdentity
+	BindIdentity(ctx context.Context, uid int64, identity domain.Identity, audit domain.UserAudit) error
+	UnbindIdentity(ctx context.Context, uid int64, provider string, audit domain.UserAudit) error
+	// UpdateBannedAt bannedAt 是零值就是解封
+	UpdateBannedAt(ctx context.Context, uid int64, bannedAt time.Time, audit domain.UserAudit) error
+	// Search 管理后台用，不会填 Identities
+	Search(ctx context.Context, q domain.UserQuery) ([]domain.User, int64, error)
 }
 
 type CachedUserRepository struct {
-	dao   dao.UserDao
-	cache cache.UserCache
+	dao         dao.UserDao
+	identityDao dao.IdentityDAO
+	cache       cache.UserCache
 }
 
-func NewUserRepository(dao dao.UserDao, cache cache.UserCache) UserRepository {
+func NewUserRepository(dao dao.UserDao, identityDao dao.IdentityDAO,
+	cache cache.UserCache) UserRepository {
 	return &CachedUserRepository{
-		dao:   dao,
-		cache: cache,
+		dao:         dao,
+		identityDao: identityDao,
+		cache:       cache,
 	}
 
 }
@@ -57,20 +81,22 @@ func (repo *CachedUserRepository) FindByEmail(ctx context.Context, email string)
 }
 
 func (repo *CachedUserRepository) toDomain(u dao.User) domain.User {
-	return domain.User{
+	res := domain.User{
 		Id:       u.Id,
 		Email:    u.Email.String,
+		EmailVerified: u.EmailVerified,
 		Phone:    u.Phone.String,
 		Password: u.Password,
 		Nickname: u.Nickname,
 		Birthday: time.UnixMilli(u.Birthday),
 		AboutMe:  u.AboutMe,
-		WechatInfo: domain.WechatInfo{
-			OpenId: u.WechatOpenId.String,
-			UnionId: u.WechatUnionId.String,
-		},
+		Avatar:   u.Avatar,
+		Ctime:    time.UnixMilli(u.CreateAt),
 	}
-
+	if u.BannedAt > 0 {
+		res.BannedAt = time.UnixMilli(u.BannedAt)
+	}
+	return res
 }
 
 func (repo *CachedUserRepository) toEntity(u domain.User) dao.User {
@@ -81,6 +107,7 @@ func (repo *CachedUserRepository) toEntity(u domain.User) dao.User {
 			String: u.Email,
 			Valid:  u.Email != "",
 		},
+		EmailVerified: u.EmailVerified,
 		Phone: sql.NullString{
 			String: u.Phone,
 			Valid:  u.Phone != "",
@@ -89,17 +116,43 @@ func (repo *CachedUserRepository) toEntity(u domain.User) dao.User {
 		Birthday: u.Birthday.UnixMilli(),
 		AboutMe:  u.AboutMe,
 		Nickname: u.Nickname,
-		WechatUnionId: sql.NullString{
-			String: u.WechatInfo.UnionId,
-			Valid: u.WechatInfo.UnionId!="",
-		},
-		WechatOpenId: sql.NullString{
-			String: u.WechatInfo.OpenId,
-			Valid: u.WechatInfo.OpenId!="",
-		},
+		Avatar:   u.Avatar,
+	}
+}
+
+func (repo *CachedUserRepository) identityToEntity(uid int64, i domain.Identity) dao.UserIdentity {
+	return dao.UserIdentity{
+		Uid:      uid,
+		Provider: i.Provider,
+		Subject:  i.Subject,
+		UnionId:  i.UnionId,
+		Email:    i.Email,
 	}
 }
 
+// withIdentities 填上第三方身份，微信的顺便放到 WechatInfo 里面
+func (repo *CachedUserRepository) withIdentities(ctx context.Context, u domain.User) (domain.User, error) {
+	ids, err := repo.identityDao.FindByUid(ctx, u.Id)
+	if err != nil {
+		return domain.User{}, err
+	}
+	for _, i := range ids {
+		u.Identities = append(u.Identities, domain.Identity{
+			Provider: i.Provider,
+			Subject:  i.Subject,
+			UnionId:  i.UnionId,
+			Email:    i.Email,
+		})
+		if i.Provider == domain.IdentityProviderWechat {
+			u.WechatInfo = domain.WechatInfo{
+				OpenId:  i.Subject,
+				UnionId: i.UnionId,
+			}
+		}
+	}
+	return u, nil
+}
+
 func (repo *CachedUserRepository) UpdateNonZeroFields(ctx context.Context,
 	user domain.User) error {
 	// 更新 DB 之后，删除
@@ -123,7 +176,10 @@ func (repo *CachedUserRepository) FindById(ctx context.Context, uid int64) (doma
 		return domain.User{}, err
 	}
 
-	du = repo.toDomain(u)
+	du, err = repo.withIdentities(ctx, repo.toDomain(u))
+	if err != nil {
+		return domain.User{}, err
+	}
 	// set cache
 	err = repo.cache.Set(ctx, du)
 	if err != nil {
@@ -173,12 +229,110 @@ func (repo *CachedUserRepository) FindByPhone(ctx context.Context, phone string)
 }
 
 
-func (repo *CachedUserRepository) FindByWechat(ctx context.Context, openId string) (domain.User, error) {
-	u, err := repo.dao.FindByWechat(ctx, openId)
+func (repo *CachedUserRepository) FindByIdentity(ctx context.Context,
+	provider string, subject string) (domain.User, error) {
+	i, err := repo.identityDao.FindByProvider(ctx, provider, subject)
 	if err != nil {
 		return domain.User{}, err
 	}
-	return repo.toDomain(u), nil
+	return domain.User{Id: i.Uid}, nil
+}
+
+func (repo *CachedUserRepository) CreateWithIdentity(ctx context.Context,
+	user domain.User, identity domain.Identity) (int64, error) {
+	return repo.identityDao.InsertWithUser(ctx, repo.toEntity(user),
+		repo.identityToEntity(0, identity))
+}
+
+
+func (repo *CachedUserRepository) UpdatePassword(ctx context.Context, uid int64,
+	password string, audit domain.UserAudit) error {
+	return repo.updateSensitive(ctx, uid, map[string]any{
+		"password": password,
+	}, audit)
+}
+
+func (repo *CachedUserRepository) UpdateEmail(ctx context.Context, uid int64,
+	email string, audit domain.UserAudit) error {
+	return repo.updateSensitive(ctx, uid, map[string]any{
+		"email": sql.NullString{String: email, Valid: email != ""},
+		// 改邮箱之前已经校验过发到新邮箱的验证码了
+		"email_verified": email != "",
+	}, audit)
+}
 
+func (repo *CachedUserRepository) MarkEmailVerified(ctx context.Context, uid int64,
+	audit domain.UserAudit) error {
+	return repo.updateSensitive(ctx, uid, map[string]any{
+		"email_verified": true,
+	}, audit)
 }
 
+func (repo *CachedUserRepository) UpdatePhone(ctx context.Context, uid int64,
+	phone string, audit domain.UserAudit) error {
+	return repo.updateSensitive(ctx, uid, map[string]any{
+		"phone": sql.NullString{String: phone, Valid: phone != ""},
+	}, audit)
+}
+
+func (repo *CachedUserRepository) BindIdentity(ctx context.Context, uid int64,
+	identity domain.Identity, audit domain.UserAudit) error {
+	err := repo.identityDao.Bind(ctx, repo.identityToEntity(uid, identity), repo.auditToEntity(audit))
+	if err != nil {
+		return err
+	}
+	return repo.cache.Del(ctx, uid)
+}
+
+func (repo *CachedUserRepository) UnbindIdentity(ctx context.Context, uid int64,
+	provider string, audit domain.UserAudit) error {
+	err := repo.identityDao.Unbind(ctx, uid, provider, repo.auditToEntity(audit))
+	if err != nil {
+		return err
+	}
+	return repo.cache.Del(ctx, uid)
+}
+
+func (repo *CachedUserRepository) UpdateBannedAt(ctx context.Context, uid int64,
+	bannedAt time.Time, audit domain.UserAudit) error {
+	var val int64
+	if !bannedAt.IsZero() {
+		val = bannedAt.UnixMilli()
+	}
+	// 登录校验读的是缓存，所以封禁也要删缓存
+	return repo.updateSensitive(ctx, uid, map[string]any{
+		"banned_at": val,
+	}, audit)
+}
+
+func (repo *CachedUserRepository) Search(ctx context.Context,
+	q domain.UserQuery) ([]domain.User, int64, error) {
+	users, total, err := repo.dao.Search(ctx, q.Keyword, q.Offset, q.Limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	res := make([]domain.User, 0, len(users))
+	for _, u := range users {
+		res = append(res, repo.toDomain(u))
+	}
+	return res, total, nil
+}
+
+func (repo *CachedUserRepository) auditToEntity(audit domain.UserAudit) dao.UserAuditLog {
+	return dao.UserAuditLog{
+		Action:   string(audit.Action),
+		Before:   audit.Before,
+		After:    audit.After,
+		Operator: audit.Operator,
+	}
+}
+
+func (repo *CachedUserRepository) updateSensitive(ctx context.Context, uid int64,
+	fields map[string]any, audit domain.UserAudit) error {
+	err := repo.dao.UpdateSensitive(ctx, uid, fields, repo.auditToEntity(audit))
+	if err != nil {
+		return err
+	}
+	// 缓存里面有密码、邮箱和手机号，必须删掉
+	return repo.cache.Del(ctx, uid)
+}
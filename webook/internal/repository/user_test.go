@@ -20,7 +20,7 @@ func TestCachedUserRepository_FindById(t *testing.T) {
 
 	testCases := []struct {
 		name     string
-		mock     func(ctrl *gomock.Controller) (cache.UserCache, dao.UserDao)
+		mock     func(ctrl *gomock.Controller) (cache.UserCache, dao.UserDao, dao.IdentityDAO)
 		ctx      context.Context
 		uid      int64
 		wantUser domain.User
@@ -29,9 +29,10 @@ func TestCachedUserRepository_FindById(t *testing.T) {
 		// TODO: Add test cases.
 		{
 			name: "no cache",
-			mock: func(ctrl *gomock.Controller) (cache.UserCache, dao.UserDao) {
+			mock: func(ctrl *gomock.Controller) (cache.UserCache, dao.UserDao, dao.IdentityDAO) {
 				uid := int64(123)
 				d := daomocks.NewMockUserDao(ctrl)
+				i := daomocks.NewMockIdentityDAO(ctrl)
 				c := cachemocks.NewMockUserCache(ctrl)
 				c.EXPECT().Get(gomock.Any(), uid).Return(domain.User{}, cache.ErrKeyNotExist)
 				d.EXPECT().FindById(gomock.Any(), uid).Return(
@@ -52,6 +53,10 @@ func TestCachedUserRepository_FindById(t *testing.T) {
 						CreateAt: 100,
 						UpdateAt: 101,
 					}, nil)
+				i.EXPECT().FindByUid(gomock.Any(), uid).Return([]dao.UserIdentity{
+					{Uid: uid, Provider: "wechat", Subject: "open_id", UnionId: "union_id"},
+					{Uid: uid, Provider: "github", Subject: "1234"},
+				}, nil)
 				c.EXPECT().Set(gomock.Any(), domain.User{
 					Id:       123,
 					Email:    "123@qq.com",
@@ -60,9 +65,18 @@ func TestCachedUserRepository_FindById(t *testing.T) {
 					Nickname: "",
 					Birthday: time.UnixMilli(123),
 					AboutMe:  "",
+					Ctime:    time.UnixMilli(100),
+					WechatInfo: domain.WechatInfo{
+						OpenId:  "open_id",
+						UnionId: "union_id",
+					},
+					Identities: []domain.Identity{
+						{Provider: "wechat", Subject: "open_id", UnionId: "union_id"},
+						{Provider: "github", Subject: "1234"},
+					},
 				}).Return(nil)
 
-				return c, d
+				return c, d, i
 			},
 			uid: 123,
 			ctx: context.Background(),
@@ -74,14 +88,24 @@ func TestCachedUserRepository_FindById(t *testing.T) {
 				Nickname: "",
 				Birthday: time.UnixMilli(123),
 				AboutMe:  "",
+				Ctime:    time.UnixMilli(100),
+				WechatInfo: domain.WechatInfo{
+					OpenId:  "open_id",
+					UnionId: "union_id",
+				},
+				Identities: []domain.Identity{
+					{Provider: "wechat", Subject: "open_id", UnionId: "union_id"},
+					{Provider: "github", Subject: "1234"},
+				},
 			},
 			wantErr: nil,
 		},
 		{
 			name: "find cache",
-			mock: func(ctrl *gomock.Controller) (cache.UserCache, dao.UserDao) {
+			mock: func(ctrl *gomock.Controller) (cache.UserCache, dao.UserDao, dao.IdentityDAO) {
 				uid := int64(123)
 				d := daomocks.NewMockUserDao(ctrl)
+				i := daomocks.NewMockIdentityDAO(ctrl)
 				c := cachemocks.NewMockUserCache(ctrl)
 				c.EXPECT().Get(gomock.Any(), uid).Return(domain.User{
 					Id:       123,
@@ -93,7 +117,7 @@ func TestCachedUserRepository_FindById(t *testing.T) {
 					AboutMe:  "",
 				}, nil)
 
-				return c, d
+				return c, d, i
 			},
 			uid: 123,
 			ctx: context.Background(),
@@ -110,16 +134,17 @@ func TestCachedUserRepository_FindById(t *testing.T) {
 		},
 		{
 			name: "no user",
-			mock: func(ctrl *gomock.Controller) (cache.UserCache, dao.UserDao) {
+			mock: func(ctrl *gomock.Controller) (cache.UserCache, dao.UserDao, dao.IdentityDAO) {
 				uid := int64(123)
 				d := daomocks.NewMockUserDao(ctrl)
+				i := daomocks.NewMockIdentityDAO(ctrl)
 				c := cachemocks.NewMockUserCache(ctrl)
 				c.EXPECT().Get(gomock.Any(), uid).Return(domain.User{}, cache.ErrKeyNotExist)
 				d.EXPECT().FindById(gomock.Any(), uid).Return(
 					dao.User{}, dao.ErrRecordNotFound)
 				
 
-				return c, d
+				return c, d, i
 			},
 			uid: 123,
 			ctx: context.Background(),
@@ -129,9 +154,10 @@ func TestCachedUserRepository_FindById(t *testing.T) {
 		},
 		{
 			name: "write cache error",
-			mock: func(ctrl *gomock.Controller) (cache.UserCache, dao.UserDao) {
+			mock: func(ctrl *gomock.Controller) (cache.UserCache, dao.UserDao, dao.IdentityDAO) {
 				uid := int64(123)
 				d := daomocks.NewMockUserDao(ctrl)
+				i := daomocks.NewMockIdentityDAO(ctrl)
 				c := cachemocks.NewMockUserCache(ctrl)
 				c.EXPECT().Get(gomock.Any(), uid).Return(domain.User{}, cache.ErrKeyNotExist)
 				d.EXPECT().FindById(gomock.Any(), uid).Return(
@@ -152,6 +178,7 @@ func TestCachedUserRepository_FindById(t *testing.T) {
 						CreateAt: 100,
 						UpdateAt: 101,
 					}, nil)
+				i.EXPECT().FindByUid(gomock.Any(), uid).Return(nil, nil)
 				c.EXPECT().Set(gomock.Any(), domain.User{
 					Id:       123,
 					Email:    "123@qq.com",
@@ -160,9 +187,10 @@ func TestCachedUserRepository_FindById(t *testing.T) {
 					Nickname: "",
 					Birthday: time.UnixMilli(123),
 					AboutMe:  "",
+					Ctime:    time.UnixMilli(100),
 				}).Return(errors.New("redis error"))
 
-				return c, d
+				return c, d, i
 			},
 			uid: 123,
 			ctx: context.Background(),
@@ -174,6 +202,7 @@ func TestCachedUserRepository_FindById(t *testing.T) {
 				Nickname: "",
 				Birthday: time.UnixMilli(123),
 				AboutMe:  "",
+				Ctime:    time.UnixMilli(100),
 			},
 			wantErr: nil,
 		},
@@ -183,8 +212,8 @@ func TestCachedUserRepository_FindById(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 
-			uc, ud := tt.mock(ctrl)
-			repo := NewUserRepository(ud, uc)
+			uc, ud, id := tt.mock(ctrl)
+			repo := NewUserRepository(ud, id, uc)
 
 			user, err := repo.FindById(tt.ctx, tt.uid)
 			assert.Equal(t, tt.wantErr, err)
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/dao"
+	"gitee.com/geekbang/basic-go/webook/pkg/cryptox"
+)
+
+var ErrWechatTokenNotFound = dao.ErrRecordNotFound
+
+type WechatTokenRepository interface {
+	FindByUid(ctx context.Context, uid int64) (domain.WechatToken, error)
+	Save(ctx context.Context, t domain.WechatToken) error
+	// FindExpiring access token 在 before 之前过期、refresh token 还没过期的，按照 uid 分页。
+	// 解密失败的会跳过，所以返回的数量可能比 limit 少
+	FindExpiring(ctx context.Context, before time.Time, afterUid int64, limit int) ([]domain.WechatToken, error)
+	Delete(ctx context.Context, uid int64) error
+}
+
+// EncryptedWechatTokenRepository 数据库里面只有密文，拖库了也拿不到能用的 token
+type EncryptedWechatTokenRepository struct {
+	dao    dao.WechatTokenDAO
+	cipher *cryptox.AESGCM
+}
+
+func NewWechatTokenRepository(dao dao.WechatTokenDAO, cipher *cryptox.AESGCM) WechatTokenRepository {
+	return &EncryptedWechatTokenRepository{
+		dao:    dao,
+		cipher: cipher,
+	}
+}
+
+func (repo *EncryptedWechatTokenRepository) FindByUid(ctx context.Context, uid int64) (domain.WechatToken, error) {
+	t, err := repo.dao.FindByUid(ctx, uid)
+	if err != nil {
+		return domain.WechatToken{}, err
+	}
+	return repo.toDomain(t)
+}
+
+func (repo *EncryptedWechatTokenRepository) Save(ctx context.Context, t domain.WechatToken) error {
+	access, err := repo.cipher.Encrypt(t.AccessToken)
+	if err != nil {
+		return err
+	}
+	refresh, err := repo.cipher.Encrypt(t.RefreshToken)
+	if err != nil {
+		return err
+	}
+	return repo.dao.Upsert(ctx, dao.WechatToken{
+		Uid:             t.Uid,
+		OpenId:          t.OpenId,
+		AccessToken:     access,
+		RefreshToken:    refresh,
+		AccessExpireAt:  t.AccessExpireAt.UnixMilli(),
+		RefreshExpireAt: t.RefreshExpireAt.UnixMilli(),
+	})
+}
+
+func (repo *EncryptedWechatTokenRepository) FindExpiring(ctx context.Context,
+	before time.Time, afterUid int64, limit int) ([]domain.WechatToken, error) {
+	ts, err := repo.dao.FindExpiring(ctx, before.UnixMilli(), afterUid, limit)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.WechatToken, 0, len(ts))
+	for _, t := range ts {
+		dt, err := repo.toDomain(t)
+		if err != nil {
+			// 一般是换了 key，这种只能等用户重新授权
+			continue
+		}
+		res = append(res, dt)
+	}
+	return res, nil
+}
+
+func (repo *EncryptedWechatTokenRepository) Delete(ctx context.Context, uid int64) error {
+	return repo.dao.Delete(ctx, uid)
+}
+
+func (repo *EncryptedWechatTokenRepository) toDomain(t dao.WechatToken) (domain.WechatToken, error) {
+	access, err := repo.cipher.Decrypt(t.AccessToken)
+	if err != nil {
+		return domain.WechatToken{}, err
+	}
+	refresh, err := repo.cipher.Decrypt(t.RefreshToken)
+	if err != nil {
+		return domain.WechatToken{}, err
+	}
+	return domain.WechatToken{
+		Uid:             t.Uid,
+		OpenId:          t.OpenId,
+		AccessToken:     access,
+		RefreshToken:    refresh,
+		AccessExpireAt:  time.UnixMilli(t.AccessExpireAt),
+		RefreshExpireAt: time.UnixMilli(t.RefreshExpireAt),
+	}, nil
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/dao"
+	daomocks "gitee.com/geekbang/basic-go/webook/internal/repository/dao/mocks"
+	"gitee.com/geekbang/basic-go/webook/pkg/cryptox"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestEncryptedWechatTokenRepository(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cipher, err := cryptox.NewAESGCM([]byte("0123456789abcdef0123456789abcdef"))
+	require.NoError(t, err)
+	d := daomocks.NewMockWechatTokenDAO(ctrl)
+	repo := NewWechatTokenRepository(d, cipher)
+
+	expire := time.UnixMilli(time.Now().Add(time.Hour).UnixMilli())
+	token := domain.WechatToken{
+		Uid:             123,
+		OpenId:          "open-1",
+		AccessToken:     "access-1",
+		RefreshToken:    "refresh-1",
+		AccessExpireAt:  expire,
+		RefreshExpireAt: expire,
+	}
+	var saved dao.WechatToken
+	d.EXPECT().Upsert(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, t dao.WechatToken) error {
+			saved = t
+			return nil
+		})
+	require.NoError(t, repo.Save(context.Background(), token))
+	// 数据库里面不能有明文
+	assert.NotContains(t, saved.AccessToken, "access-1")
+	assert.NotContains(t, saved.RefreshToken, "refresh-1")
+
+	d.EXPECT().FindByUid(gomock.Any(), int64(123)).Return(saved, nil)
+	got, err := repo.FindByUid(context.Background(), 123)
+	require.NoError(t, err)
+	assert.Equal(t, token, got)
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository"
+	"github.com/ecodeclub/ekit/slice"
+)
+
+var (
+	ErrPossibleIncorrectAuthor = repository.ErrPossibleIncorrectAuthor
+	ErrArticleNotFound         = repository.ErrArticleNotFound
+)
+
+type ArticleService interface {
+	Save(ctx context.Context, art domain.Article) (int64, error)
+	Publish(ctx context.Context, art domain.Article) (int64, error)
+	// Withdraw 撤回已发表的文章，读者不可见，作者重新发表之后恢复
+	Withdraw(ctx context.Context, uid int64, id int64) error
+	GetById(ctx context.Context, id int64) (domain.Article, error)
+	List(ctx context.Context, uid int64, offset int, limit int) ([]domain.Article, error)
+	GetPublishedById(ctx context.Context, id int64) (domain.Article, error)
+	// GetPublishedByIds 批量查询读者可见的文章，撤回的文章会被过滤掉
+	GetPublishedByIds(ctx context.Context, ids []int64) ([]domain.Article, error)
+	// ListPub 分批查询 start 之前更新过的已发表文章，更新时间倒序
+	ListPub(ctx context.Context, start time.Time, offset int, limit int) ([]domain.Article, error)
+}
+
+type DefaultArticleService struct {
+	repo repository.ArticleRepository
+}
+
+func NewArticleService(repo repository.ArticleRepository) ArticleService {
+	return &DefaultArticleService{
+		repo: repo,
+	}
+}
+
+// Save 保存草稿，id 为 0 的时候新建，否则更新。草稿不会同步到线上库
+func (svc *DefaultArticleService) Save(ctx context.Context, art domain.Article) (int64, error) {
+	art.Status = domain.ArticleStatusUnpublished
+	if art.Id > 0 {
+		err := svc.repo.Update(ctx, art)
+		return art.Id, err
+	}
+	return svc.repo.Create(ctx, art)
+}
+
+func (svc *DefaultArticleService) Publish(ctx context.Context, art domain.Article) (int64, error) {
+	art.Status = domain.ArticleStatusPublished
+	return svc.repo.Sync(ctx, art)
+}
+
+func (svc *DefaultArticleService) Withdraw(ctx context.Context, uid int64, id int64) error {
+	return svc.repo.SyncStatus(ctx, uid, id, domain.ArticleStatusPrivate)
+}
+
+func (svc *DefaultArticleService) GetById(ctx context.Context, id int64) (domain.Article, error) {
+	return svc.repo.GetById(ctx, id)
+}
+
+func (svc *DefaultArticleService) List(ctx context.Context, uid int64, offset int, limit int) ([]domain.Article, error) {
+	return svc.repo.GetByAuthor(ctx, uid, offset, limit)
+}
+
+func (svc *DefaultArticleService) GetPublishedById(ctx context.Context, id int64) (domain.Article, error) {
+	art, err := svc.repo.GetPublishedById(ctx, id)
+	if err != nil {
+		return domain.Article{}, err
+	}
+	// 撤回的文章还在线上库里面，但是读者看不到
+	if art.Status != domain.ArticleStatusPublished {
+		return domain.Article{}, ErrArticleNotFound
+	}
+	return art, nil
+}
+
+func (svc *DefaultArticleService) GetPublishedByIds(ctx context.Context, ids []int64) ([]domain.Article, error) {
+	arts, err := svc.repo.GetPublishedByIds(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	return slice.FilterMap(arts, func(idx int, src domain.Article) (domain.Article, bool) {
+		return src, src.Status == domain.ArticleStatusPublished
+	}), nil
+}
+
+func (svc *DefaultArticleService) ListPub(ctx context.Context, start time.Time, offset int, limit int) ([]domain.Article, error) {
+	return svc.repo.ListPub(ctx, start, offset, limit)
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository"
+	repomocks "gitee.com/geekbang/basic-go/webook/internal/repository/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDefaultArticleService_Publish(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) repository.ArticleRepository
+		art     domain.Article
+		wantId  int64
+		wantErr error
+	}{
+		{
+			name: "create and publish",
+			mock: func(ctrl *gomock.Controller) repository.ArticleRepository {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().Sync(gomock.Any(), domain.Article{
+					Title:   "my title",
+					Content: "my content",
+					Author:  domain.Author{Id: 123},
+					Status:  domain.ArticleStatusPublished,
+				}).Return(int64(1), nil)
+				return repo
+			},
+			art: domain.Article{
+				Title:   "my title",
+				Content: "my content",
+				Author:  domain.Author{Id: 123},
+			},
+			wantId: 1,
+		},
+		{
+			name: "update and publish",
+			mock: func(ctrl *gomock.Controller) repository.ArticleRepository {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().Sync(gomock.Any(), domain.Article{
+					Id:      2,
+					Title:   "my title",
+					Content: "my content",
+					Author:  domain.Author{Id: 123},
+					Status:  domain.ArticleStatusPublished,
+				}).Return(int64(2), nil)
+				return repo
+			},
+			art: domain.Article{
+				Id:      2,
+				Title:   "my title",
+				Content: "my content",
+				Author:  domain.Author{Id: 123},
+			},
+			wantId: 2,
+		},
+		{
+			name: "update someone else's article",
+			mock: func(ctrl *gomock.Controller) repository.ArticleRepository {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().Sync(gomock.Any(), gomock.Any()).
+					Return(int64(2), repository.ErrPossibleIncorrectAuthor)
+				return repo
+			},
+			art: domain.Article{
+				Id:     2,
+				Author: domain.Author{Id: 456},
+			},
+			wantId:  2,
+			wantErr: ErrPossibleIncorrectAuthor,
+		},
+		{
+			name: "create failed",
+			mock: func(ctrl *gomock.Controller) repository.ArticleRepository {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().Sync(gomock.Any(), gomock.Any()).
+					Return(int64(0), errors.New("db error"))
+				return repo
+			},
+			art: domain.Article{
+				Title:  "my title",
+				Author: domain.Author{Id: 123},
+			},
+			wantErr: errors.New("db error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewArticleService(tc.mock(ctrl))
+			id, err := svc.Publish(context.Background(), tc.art)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantId, id)
+		})
+	}
+}
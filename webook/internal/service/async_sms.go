@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository"
+)
+
+// AsyncSmsService 给管理后台看异步短信的情况，发送在 sms/async 里面
+type AsyncSmsService interface {
+	// Stats 各个状态的数量
+	Stats(ctx context.Context) (map[domain.AsyncSmsStatus]int64, error)
+	List(ctx context.Context, status domain.AsyncSmsStatus, offset int, limit int) ([]domain.AsyncSms, error)
+}
+
+type DefaultAsyncSmsService struct {
+	repo repository.AsyncSmsRepository
+}
+
+func NewAsyncSmsService(repo repository.AsyncSmsRepository) AsyncSmsService {
+	return &DefaultAsyncSmsService{
+		repo: repo,
+	}
+}
+
+func (svc *DefaultAsyncSmsService) Stats(ctx context.Context) (map[domain.AsyncSmsStatus]int64, error) {
+	return svc.repo.CountByStatus(ctx)
+}
+
+func (svc *DefaultAsyncSmsService) List(ctx context.Context, status domain.AsyncSmsStatus,
+	offset int, limit int) ([]domain.AsyncSms, error) {
+	return svc.repo.ListByStatus(ctx, status, offset, limit)
+}
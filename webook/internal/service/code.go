@@ -2,53 +2,61 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/rand"
 
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
 	"gitee.com/geekbang/basic-go/webook/internal/repository"
+	"gitee.com/geekbang/basic-go/webook/internal/service/email"
 	"gitee.com/geekbang/basic-go/webook/internal/service/sms"
 )
 
 var (
-	ErrCodeVerifyTooMany = repository.ErrCodeVerifyTooMany
-	ErrCodeSendTooMany = repository.ErrCodeSendTooMany
+	ErrCodeVerifyTooMany  = repository.ErrCodeVerifyTooMany
+	ErrCodeSendTooMany    = repository.ErrCodeSendTooMany
+	ErrUnknownCodeChannel = errors.New("未知的验证码通道")
 )
 
 type CodeService interface {
-	Send(ctx context.Context, biz string, phone string) error
-	Verify(ctx context.Context, biz string, phone string, inputCode string) (bool, error)
+	Send(ctx context.Context, channel domain.CodeChannel, biz string, target string) error
+	Verify(ctx context.Context, channel domain.CodeChannel, biz string, target string, inputCode string) (bool, error)
 	generate() string
 }
 
+// CodeSender 负责把验证码投递出去，一个通道一个实现
+type CodeSender interface {
+	Send(ctx context.Context, biz string, target string, code string) error
+}
+
 type DefaultCodeService struct {
-	repo repository.CodeRepository
-	sms  sms.Service
+	repo    repository.CodeRepository
+	senders map[domain.CodeChannel]CodeSender
 }
 
-// TODO: no newcodeservice
-func NewCodeService(repo repository.CodeRepository, smsSvc sms.Service) CodeService {
+func NewCodeService(repo repository.CodeRepository, senders map[domain.CodeChannel]CodeSender) CodeService {
 	return &DefaultCodeService{
-		repo: repo,
-		sms:  smsSvc,
+		repo:    repo,
+		senders: senders,
 	}
 
 }
 
-// 没有new codeService吗？
-func (svc *DefaultCodeService) Send(ctx context.Context, biz string, phone string) error {
+func (svc *DefaultCodeService) Send(ctx context.Context, channel domain.CodeChannel, biz string, target string) error {
+	sender, ok := svc.senders[channel]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownCodeChannel, channel)
+	}
 	code := svc.generate()
-	err := svc.repo.Set(ctx, biz, phone, code)
+	err := svc.repo.Set(ctx, channel, biz, target, code)
 	if err != nil {
 		return err
 	}
-
-	const codeTplId = "12345"
-	err = svc.sms.Send(ctx, codeTplId, []string{code}, phone)
-	return err
+	return sender.Send(ctx, biz, target, code)
 }
 
-func (svc *DefaultCodeService) Verify(ctx context.Context, biz string, phone string, inputCode string) (bool, error) {
-	ok, err := svc.repo.Verify(ctx, biz, phone, inputCode)
+func (svc *DefaultCodeService) Verify(ctx context.Context, channel domain.CodeChannel, biz string, target string, inputCode string) (bool, error) {
+	ok, err := svc.repo.Verify(ctx, channel, biz, target, inputCode)
 	if err == repository.ErrCodeVerifyTooMany {
 		return false, nil
 	}
@@ -60,3 +68,50 @@ func (svc *DefaultCodeService) generate() string {
 	code := rand.Intn(1000000)
 	return fmt.Sprintf("%06d", code)
 }
+
+// codeExpireMinutes 和 set_code.lua 里面的过期时间保持一致
+const codeExpireMinutes = 10
+
+type SMSCodeSender struct {
+	sms   sms.Service
+	tplId string
+}
+
+func NewSMSCodeSender(smsSvc sms.Service) *SMSCodeSender {
+	return &SMSCodeSender{
+		sms:   smsSvc,
+		tplId: "12345",
+	}
+}
+
+func (s *SMSCodeSender) Send(ctx context.Context, biz string, target string, code string) error {
+	return s.sms.Send(ctx, s.tplId, []string{code}, target)
+}
+
+type EmailCodeSender struct {
+	svc email.Service
+	tpl *email.Template
+}
+
+func NewEmailCodeSender(svc email.Service) *EmailCodeSender {
+	tpl, err := email.ParseTemplate("Your webook verification code", "code")
+	if err != nil {
+		// 模板是打包进来的，解析不了就是代码写错了
+		panic(err)
+	}
+	return &EmailCodeSender{
+		svc: svc,
+		tpl: tpl,
+	}
+}
+
+func (s *EmailCodeSender) Send(ctx context.Context, biz string, target string, code string) error {
+	msg, err := s.tpl.Render(target, map[string]any{
+		"Code":    code,
+		"Minutes": codeExpireMinutes,
+	})
+	if err != nil {
+		return err
+	}
+	return s.svc.Send(ctx, msg)
+}
@@ -1,10 +1,20 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository"
+	repomocks "gitee.com/geekbang/basic-go/webook/internal/repository/mocks"
+	"gitee.com/geekbang/basic-go/webook/internal/service/email"
+	emailmocks "gitee.com/geekbang/basic-go/webook/internal/service/email/mocks"
+	smsmocks "gitee.com/geekbang/basic-go/webook/internal/service/sms/mocks"
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
 )
 
 func TestCodeGenerate(t *testing.T) {
@@ -13,3 +23,86 @@ func TestCodeGenerate(t *testing.T) {
 
 	assert.Equal(t, expectedOutput, output)
 }
+
+func TestDefaultCodeService_Send(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) (repository.CodeRepository, map[domain.CodeChannel]CodeSender)
+		channel domain.CodeChannel
+		target  string
+		wantErr error
+	}{
+		{
+			name: "sent by sms",
+			mock: func(ctrl *gomock.Controller) (repository.CodeRepository, map[domain.CodeChannel]CodeSender) {
+				repo := repomocks.NewMockCodeRepository(ctrl)
+				repo.EXPECT().Set(gomock.Any(), domain.CodeChannelPhone, "login", "13800000000", gomock.Any()).
+					Return(nil)
+				smsSvc := smsmocks.NewMockService(ctrl)
+				smsSvc.EXPECT().Send(gomock.Any(), "12345", gomock.Any(), "13800000000").Return(nil)
+				return repo, map[domain.CodeChannel]CodeSender{
+					domain.CodeChannelPhone: NewSMSCodeSender(smsSvc),
+				}
+			},
+			channel: domain.CodeChannelPhone,
+			target:  "13800000000",
+		},
+		{
+			name: "sent by email",
+			mock: func(ctrl *gomock.Controller) (repository.CodeRepository, map[domain.CodeChannel]CodeSender) {
+				var code string
+				repo := repomocks.NewMockCodeRepository(ctrl)
+				repo.EXPECT().Set(gomock.Any(), domain.CodeChannelEmail, "login", "123@qq.com", gomock.Any()).
+					DoAndReturn(func(ctx context.Context, channel domain.CodeChannel, biz, target, c string) error {
+						code = c
+						return nil
+					})
+				emailSvc := emailmocks.NewMockService(ctrl)
+				emailSvc.EXPECT().Send(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, msg email.Message) error {
+						assert.Equal(t, "123@qq.com", msg.To)
+						assert.True(t, strings.Contains(msg.HTML, code))
+						assert.True(t, strings.Contains(msg.Text, code))
+						return nil
+					})
+				return repo, map[domain.CodeChannel]CodeSender{
+					domain.CodeChannelEmail: NewEmailCodeSender(emailSvc),
+				}
+			},
+			channel: domain.CodeChannelEmail,
+			target:  "123@qq.com",
+		},
+		{
+			name: "send too many",
+			mock: func(ctrl *gomock.Controller) (repository.CodeRepository, map[domain.CodeChannel]CodeSender) {
+				repo := repomocks.NewMockCodeRepository(ctrl)
+				repo.EXPECT().Set(gomock.Any(), domain.CodeChannelEmail, "login", "123@qq.com", gomock.Any()).
+					Return(repository.ErrCodeSendTooMany)
+				return repo, map[domain.CodeChannel]CodeSender{
+					domain.CodeChannelEmail: NewEmailCodeSender(emailmocks.NewMockService(ctrl)),
+				}
+			},
+			channel: domain.CodeChannelEmail,
+			target:  "123@qq.com",
+			wantErr: ErrCodeSendTooMany,
+		},
+		{
+			name: "unknown channel",
+			mock: func(ctrl *gomock.Controller) (repository.CodeRepository, map[domain.CodeChannel]CodeSender) {
+				return repomocks.NewMockCodeRepository(ctrl), map[domain.CodeChannel]CodeSender{}
+			},
+			channel: domain.CodeChannelEmail,
+			target:  "123@qq.com",
+			wantErr: ErrUnknownCodeChannel,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewCodeService(tc.mock(ctrl))
+			err := svc.Send(context.Background(), tc.channel, "login", tc.target)
+			assert.True(t, errors.Is(err, tc.wantErr))
+		})
+	}
+}
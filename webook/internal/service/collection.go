@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository"
+)
+
+var ErrCollectionNotFound = repository.ErrCollectionNotFound
+
+type CollectionService interface {
+	Create(ctx context.Context, c domain.Collection) (int64, error)
+	Rename(ctx context.Context, uid int64, id int64, name string) error
+	// Delete 删除收藏夹，里面的收藏一起删除
+	Delete(ctx context.Context, uid int64, id int64) error
+	List(ctx context.Context, uid int64) ([]domain.Collection, error)
+	// ListItems 收藏夹里面的内容，cid 为 0 是默认收藏夹
+	ListItems(ctx context.Context, uid int64, cid int64, offset int, limit int) ([]domain.CollectionItem, error)
+	RemoveItem(ctx context.Context, uid int64, biz string, bizId int64) error
+}
+
+type DefaultCollectionService struct {
+	repo repository.CollectionRepository
+}
+
+func NewCollectionService(repo repository.CollectionRepository) CollectionService {
+	return &DefaultCollectionService{
+		repo: repo,
+	}
+}
+
+func (svc *DefaultCollectionService) Create(ctx context.Context, c domain.Collection) (int64, error) {
+	return svc.repo.Create(ctx, c)
+}
+
+func (svc *DefaultCollectionService) Rename(ctx context.Context, uid int64, id int64, name string) error {
+	return svc.repo.Rename(ctx, uid, id, name)
+}
+
+func (svc *DefaultCollectionService) Delete(ctx context.Context, uid int64, id int64) error {
+	return svc.repo.Delete(ctx, uid, id)
+}
+
+func (svc *DefaultCollectionService) List(ctx context.Context, uid int64) ([]domain.Collection, error) {
+	return svc.repo.FindByUid(ctx, uid)
+}
+
+func (svc *DefaultCollectionService) ListItems(ctx context.Context, uid int64, cid int64, offset int, limit int) ([]domain.CollectionItem, error) {
+	if cid > 0 {
+		c, err := svc.repo.FindById(ctx, cid)
+		if err != nil {
+			return nil, err
+		}
+		if c.Uid != uid {
+			// 不能看别人的收藏夹
+			return nil, ErrCollectionNotFound
+		}
+	}
+	return svc.repo.FindItems(ctx, uid, cid, offset, limit)
+}
+
+func (svc *DefaultCollectionService) RemoveItem(ctx context.Context, uid int64, biz string, bizId int64) error {
+	return svc.repo.RemoveItem(ctx, uid, biz, bizId)
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository"
+	repomocks "gitee.com/geekbang/basic-go/webook/internal/repository/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDefaultCollectionService_ListItems(t *testing.T) {
+	testCases := []struct {
+		name      string
+		mock      func(ctrl *gomock.Controller) repository.CollectionRepository
+		cid       int64
+		wantItems []domain.CollectionItem
+		wantErr   error
+	}{
+		{
+			name: "own collection",
+			mock: func(ctrl *gomock.Controller) repository.CollectionRepository {
+				repo := repomocks.NewMockCollectionRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(1)).
+					Return(domain.Collection{Id: 1, Uid: 123}, nil)
+				repo.EXPECT().FindItems(gomock.Any(), int64(123), int64(1), 0, 10).
+					Return([]domain.CollectionItem{{Biz: "article", BizId: 2, Cid: 1}}, nil)
+				return repo
+			},
+			cid:       1,
+			wantItems: []domain.CollectionItem{{Biz: "article", BizId: 2, Cid: 1}},
+		},
+		{
+			name: "default collection",
+			mock: func(ctrl *gomock.Controller) repository.CollectionRepository {
+				repo := repomocks.NewMockCollectionRepository(ctrl)
+				repo.EXPECT().FindItems(gomock.Any(), int64(123), int64(0), 0, 10).
+					Return([]domain.CollectionItem{{Biz: "article", BizId: 2}}, nil)
+				return repo
+			},
+			wantItems: []domain.CollectionItem{{Biz: "article", BizId: 2}},
+		},
+		{
+			name: "someone else's collection",
+			mock: func(ctrl *gomock.Controller) repository.CollectionRepository {
+				repo := repomocks.NewMockCollectionRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(1)).
+					Return(domain.Collection{Id: 1, Uid: 456}, nil)
+				return repo
+			},
+			cid:     1,
+			wantErr: ErrCollectionNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewCollectionService(tc.mock(ctrl))
+			items, err := svc.ListItems(context.Background(), 123, tc.cid, 0, 10)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantItems, items)
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrNoWaitingJob          = repository.ErrNoWaitingJob
+	ErrJobPreempted          = repository.ErrJobPreempted
+	ErrDuplicateJob          = repository.ErrDuplicateJob
+	ErrInvalidCronExpression = errors.New("invalid cron expression")
+)
+
+type CronJobService interface {
+	// AddJob 添加一个任务，第一次执行时间按照 cron 表达式计算。
+	// 表达式不对返回 ErrInvalidCronExpression，名字重复返回 ErrDuplicateJob
+	AddJob(ctx context.Context, j domain.CronJob) (int64, error)
+	// Preempt 抢占一个任务，抢到之后会在后台续约。
+	// 返回的 ctx 从参数 ctx 派生，ctx 被取消或者续约失败（任务被别人抢走）的时候都会被取消，
+	// 执行任务要用这个 ctx。
+	// 执行完之后调用 CancelFunc 停止续约并且释放任务，下一次执行时间按照 cron 表达式计算。
+	Preempt(ctx context.Context) (context.Context, domain.CronJob, error)
+}
+
+type PreemptCronJobService struct {
+	repo repository.CronJobRepository
+	l    *zap.Logger
+	// expiration 超过这个时间没有续约，任务可以被别的节点抢走
+	expiration time.Duration
+	// refreshInterval 续约间隔，要比 expiration 短得多
+	refreshInterval time.Duration
+}
+
+func NewCronJobService(repo repository.CronJobRepository, l *zap.Logger) CronJobService {
+	return &PreemptCronJobService{
+		repo:            repo,
+		l:               l,
+		expiration:      time.Minute,
+		refreshInterval: time.Second * 10,
+	}
+}
+
+func (svc *PreemptCronJobService) AddJob(ctx context.Context, j domain.CronJob) (int64, error) {
+	next := j.Next(time.Now())
+	if next.IsZero() {
+		return 0, ErrInvalidCronExpression
+	}
+	j.NextTime = next
+	return svc.repo.Create(ctx, j)
+}
+
+func (svc *PreemptCronJobService) Preempt(ctx context.Context) (context.Context, domain.CronJob, error) {
+	pctx, pcancel := context.WithTimeout(ctx, time.Second)
+	j, err := svc.repo.Preempt(pctx, svc.expiration)
+	pcancel()
+	if err != nil {
+		return nil, domain.CronJob{}, err
+	}
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		svc.refresh(runCtx, cancel, j)
+	}()
+	j.CancelFunc = func() {
+		cancel()
+		<-done
+		svc.release(j)
+	}
+	return runCtx, j, nil
+}
+
+func (svc *PreemptCronJobService) refresh(ctx context.Context, cancel context.CancelFunc, j domain.CronJob) {
+	ticker := time.NewTicker(svc.refreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		rctx, rcancel := context.WithTimeout(ctx, time.Second)
+		err := svc.repo.UpdateUtime(rctx, j.Id, j.Version)
+		rcancel()
+		switch err {
+		case nil:
+		case ErrJobPreempted:
+			svc.l.Error("job preempted by others, stop running",
+				zap.Int64("id", j.Id), zap.String("name", j.Name))
+			cancel()
+			return
+		default:
+			// 偶尔失败没关系，只要在 expiration 之内续约成功就行
+			svc.l.Error("refresh job failed", zap.Error(err),
+				zap.Int64("id", j.Id), zap.String("name", j.Name))
+		}
+	}
+}
+
+func (svc *PreemptCronJobService) release(j domain.CronJob) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	next := j.Next(time.Now())
+	if next.IsZero() {
+		svc.l.Error("invalid cron expression", zap.Int64("id", j.Id),
+			zap.String("name", j.Name), zap.String("expression", j.Expression))
+		// 表达式写错了就等人修，不要一直抢占
+		next = time.Now().Add(time.Hour * 24 * 365)
+	}
+	err := svc.repo.Release(ctx, j.Id, j.Version, next)
+	if err != nil {
+		svc.l.Error("release job failed", zap.Error(err),
+			zap.Int64("id", j.Id), zap.String("name", j.Name))
+	}
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository"
+	repomocks "gitee.com/geekbang/basic-go/webook/internal/repository/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+func TestPreemptCronJobService_Preempt(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := repomocks.NewMockCronJobRepository(ctrl)
+	repo.EXPECT().Preempt(gomock.Any(), time.Minute).Return(domain.CronJob{
+		Id: 1, Name: "ranking", Expression: "0 */3 * * * ?", Version: 4,
+	}, nil)
+	// 续约一次成功，第二次发现被别人抢走了
+	repo.EXPECT().UpdateUtime(gomock.Any(), int64(1), int64(4)).Return(nil)
+	repo.EXPECT().UpdateUtime(gomock.Any(), int64(1), int64(4)).Return(ErrJobPreempted)
+	repo.EXPECT().Release(gomock.Any(), int64(1), int64(4), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, id int64, version int64, next time.Time) error {
+			// 下一次执行时间按照表达式计算，是三分钟的整数倍
+			assert.True(t, next.After(time.Now()))
+			assert.Equal(t, 0, next.Minute()%3)
+			assert.Equal(t, 0, next.Second())
+			return nil
+		})
+
+	svc := NewCronJobService(repo, zap.NewNop()).(*PreemptCronJobService)
+	svc.refreshInterval = time.Millisecond * 10
+	ctx, j, err := svc.Preempt(context.Background())
+	require.NoError(t, err)
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("ctx should be cancelled after the job is preempted")
+	}
+	j.CancelFunc()
+}
+
+func TestPreemptCronJobService_PreemptParentCancelled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := repomocks.NewMockCronJobRepository(ctrl)
+	repo.EXPECT().Preempt(gomock.Any(), time.Minute).Return(domain.CronJob{
+		Id: 1, Name: "ranking", Expression: "0 */3 * * * ?", Version: 4,
+	}, nil)
+	repo.EXPECT().Release(gomock.Any(), int64(1), int64(4), gomock.Any()).Return(nil)
+
+	svc := NewCronJobService(repo, zap.NewNop())
+	parent, cancel := context.WithCancel(context.Background())
+	ctx, j, err := svc.Preempt(parent)
+	require.NoError(t, err)
+	// 节点退出的时候，正在执行的任务也要知道
+	cancel()
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("ctx should be cancelled with its parent")
+	}
+	j.CancelFunc()
+}
+
+func TestPreemptCronJobService_AddJob(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) repository.CronJobRepository
+		expr    string
+		wantId  int64
+		wantErr error
+	}{
+		{
+			name: "added",
+			mock: func(ctrl *gomock.Controller) repository.CronJobRepository {
+				repo := repomocks.NewMockCronJobRepository(ctrl)
+				repo.EXPECT().Create(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, j domain.CronJob) (int64, error) {
+						// 第一次执行时间是下一个整点
+						assert.True(t, j.NextTime.After(time.Now()))
+						assert.Equal(t, 0, j.NextTime.Minute())
+						return 3, nil
+					})
+				return repo
+			},
+			expr:   "0 0 * * * ?",
+			wantId: 3,
+		},
+		{
+			name: "invalid expression",
+			mock: func(ctrl *gomock.Controller) repository.CronJobRepository {
+				return repomocks.NewMockCronJobRepository(ctrl)
+			},
+			expr:    "every hour",
+			wantErr: ErrInvalidCronExpression,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewCronJobService(tc.mock(ctrl), zap.NewNop())
+			id, err := svc.AddJob(context.Background(), domain.CronJob{
+				Name:       "cleanup",
+				Executor:   "local",
+				Expression: tc.expr,
+			})
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantId, id)
+		})
+	}
+}
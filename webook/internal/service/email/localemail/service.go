@@ -0,0 +1,21 @@
+package localemail
+
+import (
+	"context"
+	"log"
+
+	"gitee.com/geekbang/basic-go/webook/internal/service/email"
+)
+
+// Service 本地开发用，不真的发邮件，只打日志
+type Service struct {
+}
+
+func NewService() *Service {
+	return &Service{}
+}
+
+func (s *Service) Send(ctx context.Context, msg email.Message) error {
+	log.Println("邮件发给", msg.To, msg.Subject, msg.Text)
+	return nil
+}
@@ -0,0 +1,114 @@
+package smtp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"time"
+
+	"gitee.com/geekbang/basic-go/webook/internal/service/email"
+)
+
+// Service 通过 SMTP 发邮件，服务器支持 STARTTLS 的时候会自动升级，
+// 所以要用 587 这种提交端口，465 那种直接 TLS 的端口不支持
+type Service struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// NewService addr 是 host:port，username 为空的时候不做认证
+func NewService(addr string, username string, password string, from string) *Service {
+	var auth smtp.Auth
+	if username != "" {
+		host, _, _ := net.SplitHostPort(addr)
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &Service{
+		addr: addr,
+		auth: auth,
+		from: from,
+	}
+}
+
+func (s *Service) Send(ctx context.Context, msg email.Message) error {
+	data, err := buildMessage(s.from, msg)
+	if err != nil {
+		return err
+	}
+	// smtp.SendMail 不接受 ctx，这里只能在外面等
+	ch := make(chan error, 1)
+	go func() {
+		ch <- smtp.SendMail(s.addr, s.auth, s.from, []string{msg.To}, data)
+	}()
+	select {
+	case err = <-ch:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func buildMessage(from string, msg email.Message) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+
+	if msg.HTML == "" || msg.Text == "" {
+		contentType, body := "text/plain", msg.Text
+		if msg.HTML != "" {
+			contentType, body = "text/html", msg.HTML
+		}
+		fmt.Fprintf(&buf, "Content-Type: %s; charset=utf-8\r\n", contentType)
+		buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+		if err := writeQuotedPrintable(&buf, body); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	mw := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", mw.Boundary())
+	// 客户端会优先展示最后一个它能看懂的部分，所以 HTML 放后面
+	parts := []struct {
+		contentType string
+		body        string
+	}{
+		{contentType: "text/plain", body: msg.Text},
+		{contentType: "text/html", body: msg.HTML},
+	}
+	for _, p := range parts {
+		w, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {p.contentType + "; charset=utf-8"},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err = writeQuotedPrintable(w, p.body); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeQuotedPrintable(w io.Writer, body string) error {
+	qw := quotedprintable.NewWriter(w)
+	if _, err := qw.Write([]byte(body)); err != nil {
+		return err
+	}
+	return qw.Close()
+}
@@ -0,0 +1,49 @@
+package smtp
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+
+	"gitee.com/geekbang/basic-go/webook/internal/service/email"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildMessage(t *testing.T) {
+	data, err := buildMessage("noreply@webook.com", email.Message{
+		To:      "123@qq.com",
+		Subject: "验证码",
+		HTML:    "<p>123456</p>",
+		Text:    "123456",
+	})
+	require.NoError(t, err)
+
+	m, err := mail.ReadMessage(strings.NewReader(string(data)))
+	require.NoError(t, err)
+	assert.Equal(t, "123@qq.com", m.Header.Get("To"))
+	subject, err := new(mime.WordDecoder).DecodeHeader(m.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "验证码", subject)
+
+	mediaType, params, err := mime.ParseMediaType(m.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+	// multipart.Reader 会自动解 quoted-printable
+	mr := multipart.NewReader(m.Body, params["boundary"])
+	var bodies []string
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		body, err := io.ReadAll(p)
+		require.NoError(t, err)
+		bodies = append(bodies, string(body))
+	}
+	assert.Equal(t, []string{"123456", "<p>123456</p>"}, bodies)
+}
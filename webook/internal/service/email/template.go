@@ -0,0 +1,51 @@
+package email
+
+import (
+	"bytes"
+	"embed"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+// Template 一封邮件的 HTML 和纯文本两个版本，用同一份数据渲染
+type Template struct {
+	subject string
+	html    *htmltemplate.Template
+	text    *texttemplate.Template
+}
+
+// ParseTemplate name 对应 templates 下面的 name.html 和 name.txt
+func ParseTemplate(subject string, name string) (*Template, error) {
+	html, err := htmltemplate.ParseFS(templateFS, "templates/"+name+".html")
+	if err != nil {
+		return nil, err
+	}
+	text, err := texttemplate.ParseFS(templateFS, "templates/"+name+".txt")
+	if err != nil {
+		return nil, err
+	}
+	return &Template{
+		subject: subject,
+		html:    html,
+		text:    text,
+	}, nil
+}
+
+func (t *Template) Render(to string, data any) (Message, error) {
+	var html, text bytes.Buffer
+	if err := t.html.Execute(&html, data); err != nil {
+		return Message{}, err
+	}
+	if err := t.text.Execute(&text, data); err != nil {
+		return Message{}, err
+	}
+	return Message{
+		To:      to,
+		Subject: t.subject,
+		HTML:    html.String(),
+		Text:    text.String(),
+	}, nil
+}
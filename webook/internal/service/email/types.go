@@ -0,0 +1,15 @@
+package email
+
+import "context"
+
+type Service interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Message HTML 和 Text 至少要有一个，两个都有的时候发 multipart/alternative
+type Message struct {
+	To      string
+	Subject string
+	HTML    string
+	Text    string
+}
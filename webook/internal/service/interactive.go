@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository"
+)
+
+type InteractiveService interface {
+	// IncrReadCnt 阅读计数先在内存里面合并，批量写到数据库
+	IncrReadCnt(ctx context.Context, biz string, bizId int64) error
+	// Like 点赞是幂等的，重复点赞不会重复计数
+	Like(ctx context.Context, biz string, bizId int64, uid int64) error
+	CancelLike(ctx context.Context, biz string, bizId int64, uid int64) error
+	// Collect 收藏到 cid 对应的收藏夹
+	Collect(ctx context.Context, biz string, bizId int64, cid int64, uid int64) error
+	// Get 计数，以及 uid 是否点赞、收藏
+	Get(ctx context.Context, biz string, bizId int64, uid int64) (domain.Interactive, error)
+	// GetByIds 批量查询计数，没有计数的资源不在结果里面
+	GetByIds(ctx context.Context, biz string, ids []int64) (map[int64]domain.Interactive, error)
+}
+
+type DefaultInteractiveService struct {
+	repo          repository.InteractiveRepository
+	readCntBuffer *ReadCntBuffer
+}
+
+func NewInteractiveService(repo repository.InteractiveRepository,
+	readCntBuffer *ReadCntBuffer) InteractiveService {
+	return &DefaultInteractiveService{
+		repo:          repo,
+		readCntBuffer: readCntBuffer,
+	}
+}
+
+func (svc *DefaultInteractiveService) IncrReadCnt(ctx context.Context, biz string, bizId int64) error {
+	svc.readCntBuffer.Add(biz, bizId)
+	return nil
+}
+
+func (svc *DefaultInteractiveService) Like(ctx context.Context, biz string, bizId int64, uid int64) error {
+	return svc.repo.IncrLike(ctx, biz, bizId, uid)
+}
+
+func (svc *DefaultInteractiveService) CancelLike(ctx context.Context, biz string, bizId int64, uid int64) error {
+	return svc.repo.DecrLike(ctx, biz, bizId, uid)
+}
+
+func (svc *DefaultInteractiveService) Collect(ctx context.Context, biz string, bizId int64, cid int64, uid int64) error {
+	return svc.repo.AddCollectionItem(ctx, biz, bizId, cid, uid)
+}
+
+func (svc *DefaultInteractiveService) Get(ctx context.Context, biz string, bizId int64, uid int64) (domain.Interactive, error) {
+	intr, err := svc.repo.Get(ctx, biz, bizId)
+	if err != nil {
+		return domain.Interactive{}, err
+	}
+	intr.Liked, err = svc.repo.Liked(ctx, biz, bizId, uid)
+	if err != nil {
+		return domain.Interactive{}, err
+	}
+	intr.Collected, err = svc.repo.Collected(ctx, biz, bizId, uid)
+	if err != nil {
+		return domain.Interactive{}, err
+	}
+	return intr, nil
+}
+
+func (svc *DefaultInteractiveService) GetByIds(ctx context.Context, biz string, ids []int64) (map[int64]domain.Interactive, error) {
+	intrs, err := svc.repo.GetByIds(ctx, biz, ids)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[int64]domain.Interactive, len(intrs))
+	for _, intr := range intrs {
+		res[intr.BizId] = intr
+	}
+	return res, nil
+}
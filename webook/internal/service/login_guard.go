@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrLoginLocked      = errors.New("登录失败次数太多，暂时锁定")
+	ErrLoginTooFrequent = errors.New("登录失败之后要等一会儿才能再试")
+)
+
+// LoginGuardService 密码登录的防爆破，账号和 IP 分开计数
+type LoginGuardService interface {
+	// Check 校验密码之前调用，不能尝试的时候返回还要等多久，
+	// 以及 ErrLoginLocked 或者 ErrLoginTooFrequent。
+	// 能尝试的时候先记一次失败，这样并发的尝试也会被限制住，登录成功之后调用 Succeed 撤回
+	Check(ctx context.Context, email string, ip string) (time.Duration, error)
+	// Succeed 登录成功之后清掉账号的计数
+	Succeed(ctx context.Context, email string, ip string) error
+}
+
+type ProgressiveLoginGuardService struct {
+	repo repository.LoginAttemptRepository
+	// window 失败次数在这个窗口里面累计
+	window  time.Duration
+	account domain.LoginGuardPolicy
+	// ip 一个出口 IP 后面可能有很多人，放宽一点
+	ip domain.LoginGuardPolicy
+	l  *zap.Logger
+}
+
+func NewLoginGuardService(repo repository.LoginAttemptRepository, l *zap.Logger) LoginGuardService {
+	return &ProgressiveLoginGuardService{
+		repo:   repo,
+		window: time.Minute * 15,
+		account: domain.LoginGuardPolicy{
+			Free:      3,
+			LockAt:    10,
+			BaseDelay: time.Second,
+			MaxDelay:  time.Second * 30,
+			Lockout:   time.Minute * 15,
+		},
+		ip: domain.LoginGuardPolicy{
+			Free:      10,
+			LockAt:    50,
+			BaseDelay: time.Second,
+			MaxDelay:  time.Second * 30,
+			Lockout:   time.Minute * 15,
+		},
+		l: l,
+	}
+}
+
+func (svc *ProgressiveLoginGuardService) Check(ctx context.Context, email string, ip string) (time.Duration, error) {
+	subjects := svc.subjects(email, ip)
+	policies := []domain.LoginGuardPolicy{svc.account, svc.ip}
+	counts, wait, locked, err := svc.repo.Reserve(ctx, subjects, policies, svc.window)
+	switch {
+	case err != nil:
+		return 0, err
+	case locked:
+		return wait, ErrLoginLocked
+	case wait > 0:
+		return wait, ErrLoginTooFrequent
+	}
+	for i, cnt := range counts {
+		if cnt == policies[i].LockAt {
+			// 锁定要留痕，给安全审查用
+			svc.l.Warn("login locked after too many attempts",
+				zap.String("subject", subjects[i]),
+				zap.String("email", email),
+				zap.String("ip", ip),
+				zap.Int64("attempts", cnt),
+				zap.Duration("lockout", policies[i].Lockout))
+		}
+	}
+	return 0, nil
+}
+
+// Succeed 账号的计数全部清掉。IP 只撤回这一次 Check 记的失败，之前的失败等窗口过期，
+// 不然用自己的账号登录一次就能清掉，接着拿这个 IP 去撞别人的密码
+func (svc *ProgressiveLoginGuardService) Succeed(ctx context.Context, email string, ip string) error {
+	subjects := svc.subjects(email, ip)
+	if err := svc.repo.Reset(ctx, subjects[0]); err != nil {
+		return err
+	}
+	return svc.repo.Release(ctx, subjects[1])
+}
+
+// subjects 顺序和 Check 里面的 policies 对应
+func (svc *ProgressiveLoginGuardService) subjects(email string, ip string) []string {
+	return []string{
+		"account:" + strings.ToLower(strings.TrimSpace(email)),
+		"ip:" + ip,
+	}
+}
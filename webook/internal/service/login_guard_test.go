@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gitee.com/geekbang/basic-go/webook/internal/repository"
+	repomocks "gitee.com/geekbang/basic-go/webook/internal/repository/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+func TestProgressiveLoginGuardService_Check(t *testing.T) {
+	subjects := []string{"account:123@qq.com", "ip:1.2.3.4"}
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) repository.LoginAttemptRepository
+		wantWait time.Duration
+		wantErr  error
+	}{
+		{
+			name: "allowed",
+			mock: func(ctrl *gomock.Controller) repository.LoginAttemptRepository {
+				repo := repomocks.NewMockLoginAttemptRepository(ctrl)
+				repo.EXPECT().Reserve(gomock.Any(), subjects, gomock.Any(), time.Minute*15).
+					Return([]int64{1, 1}, time.Duration(0), false, nil)
+				return repo
+			},
+		},
+		{
+			// 这一次把账号锁了，这一次本身还是可以尝试的
+			name: "locked by this attempt",
+			mock: func(ctrl *gomock.Controller) repository.LoginAttemptRepository {
+				repo := repomocks.NewMockLoginAttemptRepository(ctrl)
+				repo.EXPECT().Reserve(gomock.Any(), subjects, gomock.Any(), time.Minute*15).
+					Return([]int64{10, 10}, time.Duration(0), false, nil)
+				return repo
+			},
+		},
+		{
+			name: "ip delayed",
+			mock: func(ctrl *gomock.Controller) repository.LoginAttemptRepository {
+				repo := repomocks.NewMockLoginAttemptRepository(ctrl)
+				repo.EXPECT().Reserve(gomock.Any(), subjects, gomock.Any(), time.Minute*15).
+					Return(nil, time.Second*4, false, nil)
+				return repo
+			},
+			wantWait: time.Second * 4,
+			wantErr:  ErrLoginTooFrequent,
+		},
+		{
+			name: "account locked",
+			mock: func(ctrl *gomock.Controller) repository.LoginAttemptRepository {
+				repo := repomocks.NewMockLoginAttemptRepository(ctrl)
+				repo.EXPECT().Reserve(gomock.Any(), subjects, gomock.Any(), time.Minute*15).
+					Return(nil, time.Minute*10, true, nil)
+				return repo
+			},
+			wantWait: time.Minute * 10,
+			wantErr:  ErrLoginLocked,
+		},
+		{
+			name: "redis error",
+			mock: func(ctrl *gomock.Controller) repository.LoginAttemptRepository {
+				repo := repomocks.NewMockLoginAttemptRepository(ctrl)
+				repo.EXPECT().Reserve(gomock.Any(), subjects, gomock.Any(), time.Minute*15).
+					Return(nil, time.Duration(0), false, errors.New("redis down"))
+				return repo
+			},
+			wantErr: errors.New("redis down"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewLoginGuardService(tc.mock(ctrl), zap.NewNop())
+			// 邮箱大小写不同也算同一个账号
+			wait, err := svc.Check(context.Background(), " 123@QQ.com", "1.2.3.4")
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantWait, wait)
+		})
+	}
+}
+
+func TestProgressiveLoginGuardService_Succeed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := repomocks.NewMockLoginAttemptRepository(ctrl)
+	// 账号全部清掉，IP 只撤回这一次
+	repo.EXPECT().Reset(gomock.Any(), "account:123@qq.com").Return(nil)
+	repo.EXPECT().Release(gomock.Any(), "ip:1.2.3.4").Return(nil)
+	svc := NewLoginGuardService(repo, zap.NewNop())
+	err := svc.Succeed(context.Background(), "123@qq.com", "1.2.3.4")
+	assert.NoError(t, err)
+}
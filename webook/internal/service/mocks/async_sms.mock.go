@@ -0,0 +1,71 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./webook/internal/service/async_sms.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./webook/internal/service/async_sms.go -package=svcmocks -destination=./webook/internal/service/mocks/async_sms.mock.go
+//
+
+// Package svcmocks is a generated GoMock package.
+package svcmocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "gitee.com/geekbang/basic-go/webook/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockAsyncSmsService is a mock of AsyncSmsService interface.
+type MockAsyncSmsService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAsyncSmsServiceMockRecorder
+}
+
+// MockAsyncSmsServiceMockRecorder is the mock recorder for MockAsyncSmsService.
+type MockAsyncSmsServiceMockRecorder struct {
+	mock *MockAsyncSmsService
+}
+
+// NewMockAsyncSmsService creates a new mock instance.
+func NewMockAsyncSmsService(ctrl *gomock.Controller) *MockAsyncSmsService {
+	mock := &MockAsyncSmsService{ctrl: ctrl}
+	mock.recorder = &MockAsyncSmsServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAsyncSmsService) EXPECT() *MockAsyncSmsServiceMockRecorder {
+	return m.recorder
+}
+
+// List mocks base method.
+func (m *MockAsyncSmsService) List(ctx context.Context, status domain.AsyncSmsStatus, offset, limit int) ([]domain.AsyncSms, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, status, offset, limit)
+	ret0, _ := ret[0].([]domain.AsyncSms)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockAsyncSmsServiceMockRecorder) List(ctx, status, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockAsyncSmsService)(nil).List), ctx, status, offset, limit)
+}
+
+// Stats mocks base method.
+func (m *MockAsyncSmsService) Stats(ctx context.Context) (map[domain.AsyncSmsStatus]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats", ctx)
+	ret0, _ := ret[0].(map[domain.AsyncSmsStatus]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockAsyncSmsServiceMockRecorder) Stats(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockAsyncSmsService)(nil).Stats), ctx)
+}
@@ -13,6 +13,7 @@ import (
 	context "context"
 	reflect "reflect"
 
+	domain "gitee.com/geekbang/basic-go/webook/internal/domain"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -40,32 +41,32 @@ func (m *MockCodeService) EXPECT() *MockCodeServiceMockRecorder {
 }
 
 // Send mocks base method.
-func (m *MockCodeService) Send(ctx context.Context, biz, phone string) error {
+func (m *MockCodeService) Send(ctx context.Context, channel domain.CodeChannel, biz, target string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Send", ctx, biz, phone)
+	ret := m.ctrl.Call(m, "Send", ctx, channel, biz, target)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Send indicates an expected call of Send.
-func (mr *MockCodeServiceMockRecorder) Send(ctx, biz, phone any) *gomock.Call {
+func (mr *MockCodeServiceMockRecorder) Send(ctx, channel, biz, target any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockCodeService)(nil).Send), ctx, biz, phone)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockCodeService)(nil).Send), ctx, channel, biz, target)
 }
 
 // Verify mocks base method.
-func (m *MockCodeService) Verify(ctx context.Context, biz, phone, inputCode string) (bool, error) {
+func (m *MockCodeService) Verify(ctx context.Context, channel domain.CodeChannel, biz, target, inputCode string) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Verify", ctx, biz, phone, inputCode)
+	ret := m.ctrl.Call(m, "Verify", ctx, channel, biz, target, inputCode)
 	ret0, _ := ret[0].(bool)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Verify indicates an expected call of Verify.
-func (mr *MockCodeServiceMockRecorder) Verify(ctx, biz, phone, inputCode any) *gomock.Call {
+func (mr *MockCodeServiceMockRecorder) Verify(ctx, channel, biz, target, inputCode any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockCodeService)(nil).Verify), ctx, biz, phone, inputCode)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockCodeService)(nil).Verify), ctx, channel, biz, target, inputCode)
 }
 
 // generate mocks base method.
@@ -81,3 +82,40 @@ func (mr *MockCodeServiceMockRecorder) generate() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "generate", reflect.TypeOf((*MockCodeService)(nil).generate))
 }
+
+// MockCodeSender is a mock of CodeSender interface.
+type MockCodeSender struct {
+	ctrl     *gomock.Controller
+	recorder *MockCodeSenderMockRecorder
+}
+
+// MockCodeSenderMockRecorder is the mock recorder for MockCodeSender.
+type MockCodeSenderMockRecorder struct {
+	mock *MockCodeSender
+}
+
+// NewMockCodeSender creates a new mock instance.
+func NewMockCodeSender(ctrl *gomock.Controller) *MockCodeSender {
+	mock := &MockCodeSender{ctrl: ctrl}
+	mock.recorder = &MockCodeSenderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCodeSender) EXPECT() *MockCodeSenderMockRecorder {
+	return m.recorder
+}
+
+// Send mocks base method.
+func (m *MockCodeSender) Send(ctx context.Context, biz, target, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", ctx, biz, target, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockCodeSenderMockRecorder) Send(ctx, biz, target, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockCodeSender)(nil).Send), ctx, biz, target, code)
+}
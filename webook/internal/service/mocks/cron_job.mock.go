@@ -0,0 +1,72 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./webook/internal/service/cron_job.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./webook/internal/service/cron_job.go -package=svcmocks -destination=./webook/internal/service/mocks/cron_job.mock.go
+//
+
+// Package svcmocks is a generated GoMock package.
+package svcmocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "gitee.com/geekbang/basic-go/webook/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockCronJobService is a mock of CronJobService interface.
+type MockCronJobService struct {
+	ctrl     *gomock.Controller
+	recorder *MockCronJobServiceMockRecorder
+}
+
+// MockCronJobServiceMockRecorder is the mock recorder for MockCronJobService.
+type MockCronJobServiceMockRecorder struct {
+	mock *MockCronJobService
+}
+
+// NewMockCronJobService creates a new mock instance.
+func NewMockCronJobService(ctrl *gomock.Controller) *MockCronJobService {
+	mock := &MockCronJobService{ctrl: ctrl}
+	mock.recorder = &MockCronJobServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCronJobService) EXPECT() *MockCronJobServiceMockRecorder {
+	return m.recorder
+}
+
+// AddJob mocks base method.
+func (m *MockCronJobService) AddJob(ctx context.Context, j domain.CronJob) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddJob", ctx, j)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddJob indicates an expected call of AddJob.
+func (mr *MockCronJobServiceMockRecorder) AddJob(ctx, j any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddJob", reflect.TypeOf((*MockCronJobService)(nil).AddJob), ctx, j)
+}
+
+// Preempt mocks base method.
+func (m *MockCronJobService) Preempt(ctx context.Context) (context.Context, domain.CronJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Preempt", ctx)
+	ret0, _ := ret[0].(context.Context)
+	ret1, _ := ret[1].(domain.CronJob)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Preempt indicates an expected call of Preempt.
+func (mr *MockCronJobServiceMockRecorder) Preempt(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Preempt", reflect.TypeOf((*MockCronJobService)(nil).Preempt), ctx)
+}
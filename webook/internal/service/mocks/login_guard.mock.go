@@ -0,0 +1,70 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./webook/internal/service/login_guard.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./webook/internal/service/login_guard.go -package=svcmocks -destination=./webook/internal/service/mocks/login_guard.mock.go
+//
+
+// Package svcmocks is a generated GoMock package.
+package svcmocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockLoginGuardService is a mock of LoginGuardService interface.
+type MockLoginGuardService struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoginGuardServiceMockRecorder
+}
+
+// MockLoginGuardServiceMockRecorder is the mock recorder for MockLoginGuardService.
+type MockLoginGuardServiceMockRecorder struct {
+	mock *MockLoginGuardService
+}
+
+// NewMockLoginGuardService creates a new mock instance.
+func NewMockLoginGuardService(ctrl *gomock.Controller) *MockLoginGuardService {
+	mock := &MockLoginGuardService{ctrl: ctrl}
+	mock.recorder = &MockLoginGuardServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLoginGuardService) EXPECT() *MockLoginGuardServiceMockRecorder {
+	return m.recorder
+}
+
+// Check mocks base method.
+func (m *MockLoginGuardService) Check(ctx context.Context, email, ip string) (time.Duration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Check", ctx, email, ip)
+	ret0, _ := ret[0].(time.Duration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Check indicates an expected call of Check.
+func (mr *MockLoginGuardServiceMockRecorder) Check(ctx, email, ip any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Check", reflect.TypeOf((*MockLoginGuardService)(nil).Check), ctx, email, ip)
+}
+
+// Succeed mocks base method.
+func (m *MockLoginGuardService) Succeed(ctx context.Context, email, ip string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Succeed", ctx, email, ip)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Succeed indicates an expected call of Succeed.
+func (mr *MockLoginGuardServiceMockRecorder) Succeed(ctx, email, ip any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Succeed", reflect.TypeOf((*MockLoginGuardService)(nil).Succeed), ctx, email, ip)
+}
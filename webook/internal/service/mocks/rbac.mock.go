@@ -0,0 +1,84 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./webook/internal/service/rbac.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./webook/internal/service/rbac.go -package=svcmocks -destination=./webook/internal/service/mocks/rbac.mock.go
+//
+
+// Package svcmocks is a generated GoMock package.
+package svcmocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "gitee.com/geekbang/basic-go/webook/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockRBACService is a mock of RBACService interface.
+type MockRBACService struct {
+	ctrl     *gomock.Controller
+	recorder *MockRBACServiceMockRecorder
+}
+
+// MockRBACServiceMockRecorder is the mock recorder for MockRBACService.
+type MockRBACServiceMockRecorder struct {
+	mock *MockRBACService
+}
+
+// NewMockRBACService creates a new mock instance.
+func NewMockRBACService(ctrl *gomock.Controller) *MockRBACService {
+	mock := &MockRBACService{ctrl: ctrl}
+	mock.recorder = &MockRBACServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRBACService) EXPECT() *MockRBACServiceMockRecorder {
+	return m.recorder
+}
+
+// Authz mocks base method.
+func (m *MockRBACService) Authz(ctx context.Context, uid int64) (domain.Authz, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authz", ctx, uid)
+	ret0, _ := ret[0].(domain.Authz)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authz indicates an expected call of Authz.
+func (mr *MockRBACServiceMockRecorder) Authz(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authz", reflect.TypeOf((*MockRBACService)(nil).Authz), ctx, uid)
+}
+
+// GrantRole mocks base method.
+func (m *MockRBACService) GrantRole(ctx context.Context, operator, uid int64, role domain.Role) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GrantRole", ctx, operator, uid, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GrantRole indicates an expected call of GrantRole.
+func (mr *MockRBACServiceMockRecorder) GrantRole(ctx, operator, uid, role any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GrantRole", reflect.TypeOf((*MockRBACService)(nil).GrantRole), ctx, operator, uid, role)
+}
+
+// RevokeRole mocks base method.
+func (m *MockRBACService) RevokeRole(ctx context.Context, operator, uid int64, role domain.Role) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeRole", ctx, operator, uid, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeRole indicates an expected call of RevokeRole.
+func (mr *MockRBACServiceMockRecorder) RevokeRole(ctx, operator, uid, role any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRole", reflect.TypeOf((*MockRBACService)(nil).RevokeRole), ctx, operator, uid, role)
+}
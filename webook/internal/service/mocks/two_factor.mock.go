@@ -0,0 +1,131 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./webook/internal/service/two_factor.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./webook/internal/service/two_factor.go -package=svcmocks -destination=./webook/internal/service/mocks/two_factor.mock.go
+//
+
+// Package svcmocks is a generated GoMock package.
+package svcmocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockTwoFactorService is a mock of TwoFactorService interface.
+type MockTwoFactorService struct {
+	ctrl     *gomock.Controller
+	recorder *MockTwoFactorServiceMockRecorder
+}
+
+// MockTwoFactorServiceMockRecorder is the mock recorder for MockTwoFactorService.
+type MockTwoFactorServiceMockRecorder struct {
+	mock *MockTwoFactorService
+}
+
+// NewMockTwoFactorService creates a new mock instance.
+func NewMockTwoFactorService(ctrl *gomock.Controller) *MockTwoFactorService {
+	mock := &MockTwoFactorService{ctrl: ctrl}
+	mock.recorder = &MockTwoFactorServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTwoFactorService) EXPECT() *MockTwoFactorServiceMockRecorder {
+	return m.recorder
+}
+
+// Challenge mocks base method.
+func (m *MockTwoFactorService) Challenge(ctx context.Context, uid int64) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Challenge", ctx, uid)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Challenge indicates an expected call of Challenge.
+func (mr *MockTwoFactorServiceMockRecorder) Challenge(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Challenge", reflect.TypeOf((*MockTwoFactorService)(nil).Challenge), ctx, uid)
+}
+
+// ChallengeUid mocks base method.
+func (m *MockTwoFactorService) ChallengeUid(ctx context.Context, token string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChallengeUid", ctx, token)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChallengeUid indicates an expected call of ChallengeUid.
+func (mr *MockTwoFactorServiceMockRecorder) ChallengeUid(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChallengeUid", reflect.TypeOf((*MockTwoFactorService)(nil).ChallengeUid), ctx, token)
+}
+
+// Confirm mocks base method.
+func (m *MockTwoFactorService) Confirm(ctx context.Context, uid int64, code string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Confirm", ctx, uid, code)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Confirm indicates an expected call of Confirm.
+func (mr *MockTwoFactorServiceMockRecorder) Confirm(ctx, uid, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Confirm", reflect.TypeOf((*MockTwoFactorService)(nil).Confirm), ctx, uid, code)
+}
+
+// Enabled mocks base method.
+func (m *MockTwoFactorService) Enabled(ctx context.Context, uid int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enabled", ctx, uid)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Enabled indicates an expected call of Enabled.
+func (mr *MockTwoFactorServiceMockRecorder) Enabled(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enabled", reflect.TypeOf((*MockTwoFactorService)(nil).Enabled), ctx, uid)
+}
+
+// Enroll mocks base method.
+func (m *MockTwoFactorService) Enroll(ctx context.Context, uid int64, account string) (string, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enroll", ctx, uid, account)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Enroll indicates an expected call of Enroll.
+func (mr *MockTwoFactorServiceMockRecorder) Enroll(ctx, uid, account any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enroll", reflect.TypeOf((*MockTwoFactorService)(nil).Enroll), ctx, uid, account)
+}
+
+// VerifyChallenge mocks base method.
+func (m *MockTwoFactorService) VerifyChallenge(ctx context.Context, token, code string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyChallenge", ctx, token, code)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyChallenge indicates an expected call of VerifyChallenge.
+func (mr *MockTwoFactorServiceMockRecorder) VerifyChallenge(ctx, token, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyChallenge", reflect.TypeOf((*MockTwoFactorService)(nil).VerifyChallenge), ctx, token, code)
+}
@@ -41,6 +41,119 @@ func (m *MockUserService) EXPECT() *MockUserServiceMockRecorder {
 	return m.recorder
 }
 
+// Ban mocks base method.
+func (m *MockUserService) Ban(ctx context.Context, operator, uid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ban", ctx, operator, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ban indicates an expected call of Ban.
+func (mr *MockUserServiceMockRecorder) Ban(ctx, operator, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ban", reflect.TypeOf((*MockUserService)(nil).Ban), ctx, operator, uid)
+}
+
+// BindIdentity mocks base method.
+func (m *MockUserService) BindIdentity(ctx context.Context, uid int64, identity domain.Identity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BindIdentity", ctx, uid, identity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BindIdentity indicates an expected call of BindIdentity.
+func (mr *MockUserServiceMockRecorder) BindIdentity(ctx, uid, identity any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BindIdentity", reflect.TypeOf((*MockUserService)(nil).BindIdentity), ctx, uid, identity)
+}
+
+// BindWechat mocks base method.
+func (m *MockUserService) BindWechat(ctx context.Context, uid int64, info domain.WechatInfo) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BindWechat", ctx, uid, info)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BindWechat indicates an expected call of BindWechat.
+func (mr *MockUserServiceMockRecorder) BindWechat(ctx, uid, info any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BindWechat", reflect.TypeOf((*MockUserService)(nil).BindWechat), ctx, uid, info)
+}
+
+// ChangeEmail mocks base method.
+func (m *MockUserService) ChangeEmail(ctx context.Context, uid int64, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeEmail", ctx, uid, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangeEmail indicates an expected call of ChangeEmail.
+func (mr *MockUserServiceMockRecorder) ChangeEmail(ctx, uid, email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeEmail", reflect.TypeOf((*MockUserService)(nil).ChangeEmail), ctx, uid, email)
+}
+
+// ChangePassword mocks base method.
+func (m *MockUserService) ChangePassword(ctx context.Context, uid int64, oldPassword, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePassword", ctx, uid, oldPassword, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangePassword indicates an expected call of ChangePassword.
+func (mr *MockUserServiceMockRecorder) ChangePassword(ctx, uid, oldPassword, password any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockUserService)(nil).ChangePassword), ctx, uid, oldPassword, password)
+}
+
+// ChangePhone mocks base method.
+func (m *MockUserService) ChangePhone(ctx context.Context, uid int64, phone string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePhone", ctx, uid, phone)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangePhone indicates an expected call of ChangePhone.
+func (mr *MockUserServiceMockRecorder) ChangePhone(ctx, uid, phone any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePhone", reflect.TypeOf((*MockUserService)(nil).ChangePhone), ctx, uid, phone)
+}
+
+// ConfirmEmail mocks base method.
+func (m *MockUserService) ConfirmEmail(ctx context.Context, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmEmail", ctx, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfirmEmail indicates an expected call of ConfirmEmail.
+func (mr *MockUserServiceMockRecorder) ConfirmEmail(ctx, email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmEmail", reflect.TypeOf((*MockUserService)(nil).ConfirmEmail), ctx, email)
+}
+
+// FindByEmail mocks base method.
+func (m *MockUserService) FindByEmail(ctx context.Context, email string) (domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByEmail", ctx, email)
+	ret0, _ := ret[0].(domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByEmail indicates an expected call of FindByEmail.
+func (mr *MockUserServiceMockRecorder) FindByEmail(ctx, email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByEmail", reflect.TypeOf((*MockUserService)(nil).FindByEmail), ctx, email)
+}
+
 // FindById mocks base method.
 func (m *MockUserService) FindById(ctx context.Context, uid int64) (domain.User, error) {
 	m.ctrl.T.Helper()
@@ -56,6 +169,21 @@ func (mr *MockUserServiceMockRecorder) FindById(ctx, uid any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindById", reflect.TypeOf((*MockUserService)(nil).FindById), ctx, uid)
 }
 
+// FindByPhone mocks base method.
+func (m *MockUserService) FindByPhone(ctx context.Context, phone string) (domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByPhone", ctx, phone)
+	ret0, _ := ret[0].(domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByPhone indicates an expected call of FindByPhone.
+func (mr *MockUserServiceMockRecorder) FindByPhone(ctx, phone any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByPhone", reflect.TypeOf((*MockUserService)(nil).FindByPhone), ctx, phone)
+}
+
 // FindOrCreate mocks base method.
 func (m *MockUserService) FindOrCreate(ctx context.Context, phone string) (domain.User, error) {
 	m.ctrl.T.Helper()
@@ -71,19 +199,19 @@ func (mr *MockUserServiceMockRecorder) FindOrCreate(ctx, phone any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindOrCreate", reflect.TypeOf((*MockUserService)(nil).FindOrCreate), ctx, phone)
 }
 
-// FindOrCreateByWechat mocks base method.
-func (m *MockUserService) FindOrCreateByWechat(ctx context.Context, info domain.WechatInfo) (domain.User, error) {
+// FindOrCreateByIdentity mocks base method.
+func (m *MockUserService) FindOrCreateByIdentity(ctx context.Context, identity domain.Identity) (domain.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindOrCreateByWechat", ctx, info)
+	ret := m.ctrl.Call(m, "FindOrCreateByIdentity", ctx, identity)
 	ret0, _ := ret[0].(domain.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// FindOrCreateByWechat indicates an expected call of FindOrCreateByWechat.
-func (mr *MockUserServiceMockRecorder) FindOrCreateByWechat(ctx, info any) *gomock.Call {
+// FindOrCreateByIdentity indicates an expected call of FindOrCreateByIdentity.
+func (mr *MockUserServiceMockRecorder) FindOrCreateByIdentity(ctx, identity any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindOrCreateByWechat", reflect.TypeOf((*MockUserService)(nil).FindOrCreateByWechat), ctx, info)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindOrCreateByIdentity", reflect.TypeOf((*MockUserService)(nil).FindOrCreateByIdentity), ctx, identity)
 }
 
 // GetUserIdFromSession mocks base method.
@@ -116,6 +244,37 @@ func (mr *MockUserServiceMockRecorder) Login(ctx, email, password any) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockUserService)(nil).Login), ctx, email, password)
 }
 
+// ResetPassword mocks base method.
+func (m *MockUserService) ResetPassword(ctx context.Context, channel domain.CodeChannel, target, password string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", ctx, channel, target, password)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockUserServiceMockRecorder) ResetPassword(ctx, channel, target, password any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockUserService)(nil).ResetPassword), ctx, channel, target, password)
+}
+
+// Search mocks base method.
+func (m *MockUserService) Search(ctx context.Context, q domain.UserQuery) ([]domain.User, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, q)
+	ret0, _ := ret[0].([]domain.User)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockUserServiceMockRecorder) Search(ctx, q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockUserService)(nil).Search), ctx, q)
+}
+
 // SignUp mocks base method.
 func (m *MockUserService) SignUp(ctx context.Context, user domain.User) error {
 	m.ctrl.T.Helper()
@@ -130,6 +289,76 @@ func (mr *MockUserServiceMockRecorder) SignUp(ctx, user any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignUp", reflect.TypeOf((*MockUserService)(nil).SignUp), ctx, user)
 }
 
+// Unban mocks base method.
+func (m *MockUserService) Unban(ctx context.Context, operator, uid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unban", ctx, operator, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unban indicates an expected call of Unban.
+func (mr *MockUserServiceMockRecorder) Unban(ctx, operator, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unban", reflect.TypeOf((*MockUserService)(nil).Unban), ctx, operator, uid)
+}
+
+// UnbindEmail mocks base method.
+func (m *MockUserService) UnbindEmail(ctx context.Context, uid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnbindEmail", ctx, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnbindEmail indicates an expected call of UnbindEmail.
+func (mr *MockUserServiceMockRecorder) UnbindEmail(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnbindEmail", reflect.TypeOf((*MockUserService)(nil).UnbindEmail), ctx, uid)
+}
+
+// UnbindIdentity mocks base method.
+func (m *MockUserService) UnbindIdentity(ctx context.Context, uid int64, provider string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnbindIdentity", ctx, uid, provider)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnbindIdentity indicates an expected call of UnbindIdentity.
+func (mr *MockUserServiceMockRecorder) UnbindIdentity(ctx, uid, provider any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnbindIdentity", reflect.TypeOf((*MockUserService)(nil).UnbindIdentity), ctx, uid, provider)
+}
+
+// UnbindPhone mocks base method.
+func (m *MockUserService) UnbindPhone(ctx context.Context, uid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnbindPhone", ctx, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnbindPhone indicates an expected call of UnbindPhone.
+func (mr *MockUserServiceMockRecorder) UnbindPhone(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnbindPhone", reflect.TypeOf((*MockUserService)(nil).UnbindPhone), ctx, uid)
+}
+
+// UnbindWechat mocks base method.
+func (m *MockUserService) UnbindWechat(ctx context.Context, uid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnbindWechat", ctx, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnbindWechat indicates an expected call of UnbindWechat.
+func (mr *MockUserServiceMockRecorder) UnbindWechat(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnbindWechat", reflect.TypeOf((*MockUserService)(nil).UnbindWechat), ctx, uid)
+}
+
 // UpdateNonSensitiveInfo mocks base method.
 func (m *MockUserService) UpdateNonSensitiveInfo(ctx context.Context, user domain.User) error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,113 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./webook/internal/service/wechat_account.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./webook/internal/service/wechat_account.go -package=svcmocks -destination=./webook/internal/service/mocks/wechat_account.mock.go
+//
+
+// Package svcmocks is a generated GoMock package.
+package svcmocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "gitee.com/geekbang/basic-go/webook/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockWechatAccountService is a mock of WechatAccountService interface.
+type MockWechatAccountService struct {
+	ctrl     *gomock.Controller
+	recorder *MockWechatAccountServiceMockRecorder
+}
+
+// MockWechatAccountServiceMockRecorder is the mock recorder for MockWechatAccountService.
+type MockWechatAccountServiceMockRecorder struct {
+	mock *MockWechatAccountService
+}
+
+// NewMockWechatAccountService creates a new mock instance.
+func NewMockWechatAccountService(ctrl *gomock.Controller) *MockWechatAccountService {
+	mock := &MockWechatAccountService{ctrl: ctrl}
+	mock.recorder = &MockWechatAccountServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWechatAccountService) EXPECT() *MockWechatAccountServiceMockRecorder {
+	return m.recorder
+}
+
+// AccessToken mocks base method.
+func (m *MockWechatAccountService) AccessToken(ctx context.Context, uid int64) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AccessToken", ctx, uid)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AccessToken indicates an expected call of AccessToken.
+func (mr *MockWechatAccountServiceMockRecorder) AccessToken(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AccessToken", reflect.TypeOf((*MockWechatAccountService)(nil).AccessToken), ctx, uid)
+}
+
+// Bind mocks base method.
+func (m *MockWechatAccountService) Bind(ctx context.Context, uid int64, info domain.WechatInfo, token domain.WechatToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Bind", ctx, uid, info, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Bind indicates an expected call of Bind.
+func (mr *MockWechatAccountServiceMockRecorder) Bind(ctx, uid, info, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Bind", reflect.TypeOf((*MockWechatAccountService)(nil).Bind), ctx, uid, info, token)
+}
+
+// Login mocks base method.
+func (m *MockWechatAccountService) Login(ctx context.Context, info domain.WechatInfo, token domain.WechatToken) (domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Login", ctx, info, token)
+	ret0, _ := ret[0].(domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Login indicates an expected call of Login.
+func (mr *MockWechatAccountServiceMockRecorder) Login(ctx, info, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockWechatAccountService)(nil).Login), ctx, info, token)
+}
+
+// RefreshExpiring mocks base method.
+func (m *MockWechatAccountService) RefreshExpiring(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshExpiring", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RefreshExpiring indicates an expected call of RefreshExpiring.
+func (mr *MockWechatAccountServiceMockRecorder) RefreshExpiring(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshExpiring", reflect.TypeOf((*MockWechatAccountService)(nil).RefreshExpiring), ctx)
+}
+
+// Unbind mocks base method.
+func (m *MockWechatAccountService) Unbind(ctx context.Context, uid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unbind", ctx, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unbind indicates an expected call of Unbind.
+func (mr *MockWechatAccountServiceMockRecorder) Unbind(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unbind", reflect.TypeOf((*MockWechatAccountService)(nil).Unbind), ctx, uid)
+}
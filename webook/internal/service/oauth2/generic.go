@@ -0,0 +1,185 @@
+package oauth2
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+)
+
+// Config 两种 Provider 共用的配置
+type Config struct {
+	Name         string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL 一般是 https://域名/oauth2/{Name}/callback
+	RedirectURL string
+	Scopes      []string
+}
+
+// Endpoints 不支持 discovery 的平台手动配，比如 GitHub
+type Endpoints struct {
+	AuthURL     string
+	TokenURL    string
+	UserInfoURL string
+	// SubjectField 用户信息里面用户 ID 的字段，默认 sub，GitHub 是 id
+	SubjectField string
+	// EmailField 默认 email，空的或者没有这个字段就不要邮箱
+	EmailField string
+}
+
+type GenericProvider struct {
+	cfg       Config
+	endpoints Endpoints
+	client    *http.Client
+}
+
+func NewGenericProvider(cfg Config, endpoints Endpoints, client *http.Client) Provider {
+	if endpoints.SubjectField == "" {
+		endpoints.SubjectField = "sub"
+	}
+	if endpoints.EmailField == "" {
+		endpoints.EmailField = "email"
+	}
+	return &GenericProvider{
+		cfg:       cfg,
+		endpoints: endpoints,
+		client:    client,
+	}
+}
+
+func (p *GenericProvider) Name() string {
+	return p.cfg.Name
+}
+
+func (p *GenericProvider) AuthURL(ctx context.Context, req AuthRequest) (string, error) {
+	return authURL(p.endpoints.AuthURL, p.cfg, p.cfg.Scopes, req, false)
+}
+
+func (p *GenericProvider) VerifyCode(ctx context.Context, code string,
+	req AuthRequest) (domain.Identity, error) {
+	tok, err := exchange(ctx, p.client, p.endpoints.TokenURL, p.cfg, code, req)
+	if err != nil {
+		return domain.Identity{}, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, p.endpoints.UserInfoURL, nil)
+	if err != nil {
+		return domain.Identity{}, err
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+tok.AccessToken)
+	httpReq.Header.Set("Accept", "application/json")
+	var info map[string]any
+	if err = doJSON(p.client, httpReq, &info); err != nil {
+		return domain.Identity{}, fmt.Errorf("%s userinfo: %w", p.cfg.Name, err)
+	}
+	sub := stringField(info, p.endpoints.SubjectField)
+	if sub == "" {
+		return domain.Identity{}, fmt.Errorf("%s userinfo: missing %s", p.cfg.Name, p.endpoints.SubjectField)
+	}
+	return domain.Identity{
+		Provider: p.cfg.Name,
+		Subject:  sub,
+		Email:    stringField(info, p.endpoints.EmailField),
+	}, nil
+}
+
+// authURL endpoint 本身可能已经带了参数
+func authURL(endpoint string, cfg Config, scopes []string, req AuthRequest, oidc bool) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", cfg.ClientID)
+	q.Set("redirect_uri", cfg.RedirectURL)
+	if len(scopes) > 0 {
+		q.Set("scope", strings.Join(scopes, " "))
+	}
+	q.Set("state", req.State)
+	q.Set("code_challenge", req.CodeChallenge())
+	q.Set("code_challenge_method", "S256")
+	if oidc {
+		q.Set("nonce", req.Nonce)
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IdToken     string `json:"id_token"`
+	Error       string `json:"error"`
+	ErrorDesc   string `json:"error_description"`
+}
+
+func exchange(ctx context.Context, client *http.Client, endpoint string,
+	cfg Config, code string, req AuthRequest) (tokenResponse, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", cfg.RedirectURL)
+	form.Set("client_id", cfg.ClientID)
+	form.Set("client_secret", cfg.ClientSecret)
+	form.Set("code_verifier", req.CodeVerifier)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint,
+		strings.NewReader(form.Encode()))
+	if err != nil {
+		return tokenResponse{}, err
+	}
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	// GitHub 默认返回的是表单格式
+	httpReq.Header.Set("Accept", "application/json")
+	var res tokenResponse
+	err = doJSON(client, httpReq, &res)
+	// 有些平台出错了也返回 200，所以两个都要看
+	if res.Error != "" {
+		return tokenResponse{}, fmt.Errorf("%s token: %s %s", cfg.Name, res.Error, res.ErrorDesc)
+	}
+	if err != nil {
+		return tokenResponse{}, fmt.Errorf("%s token: %w", cfg.Name, err)
+	}
+	if res.AccessToken == "" {
+		return tokenResponse{}, fmt.Errorf("%s token: empty access_token", cfg.Name)
+	}
+	return res, nil
+}
+
+// doJSON 非 2xx 也会尽量把 body 解析到 val 里面，方便拿错误信息
+func doJSON(client *http.Client, req *http.Request, val any) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	// GitHub 的 id 是数字，不能变成 float64
+	dec.UseNumber()
+	decErr := dec.Decode(val)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("http status %d", resp.StatusCode)
+	}
+	return decErr
+}
+
+func stringField(m map[string]any, key string) string {
+	switch v := m[key].(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	default:
+		return ""
+	}
+}
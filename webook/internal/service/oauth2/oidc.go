@@ -0,0 +1,254 @@
+package oauth2
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// OIDCProvider 端点从 {Issuer}/.well-known/openid-configuration 拿，
+// 用户身份从 id_token 里面拿，签名用 jwks_uri 里面的公钥校验
+type OIDCProvider struct {
+	cfg    Config
+	issuer string
+	scopes []string
+	client *http.Client
+
+	mu        sync.Mutex
+	discovery *discoveryDocument
+	// keys kid 到公钥，找不到 kid 的时候重新拉一次，这样平台轮换密钥也不用重启
+	keys map[string]any
+}
+
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksURI               string `json:"jwks_uri"`
+}
+
+// NewOIDCProvider issuer 比如 https://accounts.google.com，
+// 也可以是 Keycloak 的 https://sso.example.com/realms/xxx。discovery 第一次用的时候才拉
+func NewOIDCProvider(cfg Config, issuer string, client *http.Client) Provider {
+	scopes := cfg.Scopes
+	hasOpenID := false
+	for _, s := range scopes {
+		if s == "openid" {
+			hasOpenID = true
+		}
+	}
+	if !hasOpenID {
+		scopes = append([]string{"openid"}, scopes...)
+	}
+	return &OIDCProvider{
+		cfg:    cfg,
+		issuer: strings.TrimSuffix(issuer, "/"),
+		scopes: scopes,
+		client: client,
+	}
+}
+
+func (p *OIDCProvider) Name() string {
+	return p.cfg.Name
+}
+
+func (p *OIDCProvider) AuthURL(ctx context.Context, req AuthRequest) (string, error) {
+	d, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	return authURL(d.AuthorizationEndpoint, p.cfg, p.scopes, req, true)
+}
+
+type idTokenClaims struct {
+	jwt.RegisteredClaims
+	Nonce         string `json:"nonce"`
+	Email         string `json:"email"`
+	EmailVerified *bool  `json:"email_verified"`
+}
+
+func (p *OIDCProvider) VerifyCode(ctx context.Context, code string,
+	req AuthRequest) (domain.Identity, error) {
+	d, err := p.discover(ctx)
+	if err != nil {
+		return domain.Identity{}, err
+	}
+	tok, err := exchange(ctx, p.client, d.TokenEndpoint, p.cfg, code, req)
+	if err != nil {
+		return domain.Identity{}, err
+	}
+	if tok.IdToken == "" {
+		return domain.Identity{}, fmt.Errorf("%s token: empty id_token", p.cfg.Name)
+	}
+	var claims idTokenClaims
+	_, err = jwt.ParseWithClaims(tok.IdToken, &claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.key(ctx, kid)
+	}, jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384"}),
+		jwt.WithIssuer(p.issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute))
+	if err != nil {
+		return domain.Identity{}, fmt.Errorf("%s id_token: %w", p.cfg.Name, err)
+	}
+	if claims.Nonce != req.Nonce {
+		return domain.Identity{}, fmt.Errorf("%s id_token: nonce not match", p.cfg.Name)
+	}
+	if claims.Subject == "" {
+		return domain.Identity{}, fmt.Errorf("%s id_token: missing sub", p.cfg.Name)
+	}
+	res := domain.Identity{
+		Provider: p.cfg.Name,
+		Subject:  claims.Subject,
+	}
+	// 没说验证过的邮箱不要
+	if claims.EmailVerified != nil && *claims.EmailVerified {
+		res.Email = claims.Email
+	}
+	return res, nil
+}
+
+func (p *OIDCProvider) discover(ctx context.Context) (discoveryDocument, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovery != nil {
+		return *p.discovery, nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		p.issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return discoveryDocument{}, err
+	}
+	var d discoveryDocument
+	if err = doJSON(p.client, req, &d); err != nil {
+		return discoveryDocument{}, fmt.Errorf("%s discovery: %w", p.cfg.Name, err)
+	}
+	// 规范要求必须一致，不然 id_token 里面的 iss 也对不上
+	if d.Issuer != p.issuer {
+		return discoveryDocument{}, fmt.Errorf("%s discovery: issuer %q not match", p.cfg.Name, d.Issuer)
+	}
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JwksURI == "" {
+		return discoveryDocument{}, fmt.Errorf("%s discovery: missing endpoints", p.cfg.Name)
+	}
+	p.discovery = &d
+	return d, nil
+}
+
+func (p *OIDCProvider) key(ctx context.Context, kid string) (any, error) {
+	d, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if k, ok := p.keys[kid]; ok {
+		return k, nil
+	}
+	keys, err := p.fetchKeys(ctx, d.JwksURI)
+	if err != nil {
+		return nil, err
+	}
+	p.keys = keys
+	k, ok := keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%s jwks: unknown kid %q", p.cfg.Name, kid)
+	}
+	return k, nil
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (p *OIDCProvider) fetchKeys(ctx context.Context, uri string) (map[string]any, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err = doJSON(p.client, req, &set); err != nil {
+		return nil, fmt.Errorf("%s jwks: %w", p.cfg.Name, err)
+	}
+	res := make(map[string]any, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		pub, err := k.publicKey()
+		if err != nil {
+			// 不认识的 key 跳过，别的 key 还能用
+			continue
+		}
+		res[k.Kid] = pub
+	}
+	return res, nil
+}
+
+func (k jwk) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		default:
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("point not on curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported kty %s", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
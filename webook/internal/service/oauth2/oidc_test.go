@@ -0,0 +1,271 @@
+package oauth2
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeOIDCServer 一个最简单的 OIDC 服务端，code 固定是 good-code，
+// 授权的时候记下 code_challenge，换 token 的时候校验 code_verifier
+type fakeOIDCServer struct {
+	*httptest.Server
+	// key 签 id_token 用的，jwks 里面一直是最开始的那把
+	key       *rsa.PrivateKey
+	challenge string
+	nonce     string
+	// claims 用来改 id_token 里面的内容
+	claims func(c jwt.MapClaims)
+}
+
+func newFakeOIDCServer(t *testing.T) *fakeOIDCServer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	s := &fakeOIDCServer{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 s.URL,
+			"authorization_endpoint": s.URL + "/authorize",
+			"token_endpoint":         s.URL + "/token",
+			"jwks_uri":               s.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]string{
+				{
+					"kty": "RSA",
+					"kid": "k1",
+					"use": "sig",
+					"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+					"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+				},
+			},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		w.Header().Set("Content-Type", "application/json")
+		req := AuthRequest{CodeVerifier: r.PostForm.Get("code_verifier")}
+		if r.PostForm.Get("code") != "good-code" ||
+			r.PostForm.Get("client_id") != "webook" ||
+			r.PostForm.Get("client_secret") != "secret" ||
+			req.CodeChallenge() != s.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		claims := jwt.MapClaims{
+			"iss":            s.URL,
+			"sub":            "user-1",
+			"aud":            "webook",
+			"exp":            time.Now().Add(time.Minute).Unix(),
+			"iat":            time.Now().Unix(),
+			"nonce":          s.nonce,
+			"email":          "user1@example.com",
+			"email_verified": true,
+		}
+		if s.claims != nil {
+			s.claims(claims)
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = "k1"
+		idToken, err := token.SignedString(s.key)
+		require.NoError(t, err)
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"access_token": "access",
+			"token_type":   "Bearer",
+			"id_token":     idToken,
+		})
+	})
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// authorize 模拟用户在授权页面上点了同意
+func (s *fakeOIDCServer) authorize(t *testing.T, authURL string) {
+	u, err := url.Parse(authURL)
+	require.NoError(t, err)
+	assert.Equal(t, s.URL+"/authorize", u.Scheme+"://"+u.Host+u.Path)
+	q := u.Query()
+	assert.Equal(t, "S256", q.Get("code_challenge_method"))
+	assert.Equal(t, "openid email", q.Get("scope"))
+	s.challenge = q.Get("code_challenge")
+	s.nonce = q.Get("nonce")
+}
+
+func TestOIDCProvider_VerifyCode(t *testing.T) {
+	testCases := []struct {
+		name string
+		// before 在授权之后、换 token 之前改一下
+		before func(s *fakeOIDCServer, req *AuthRequest, code *string)
+
+		wantIdentity domain.Identity
+		wantErr      bool
+	}{
+		{
+			name: "success",
+			wantIdentity: domain.Identity{
+				Provider: "keycloak",
+				Subject:  "user-1",
+				Email:    "user1@example.com",
+			},
+		},
+		{
+			name: "email not verified",
+			before: func(s *fakeOIDCServer, req *AuthRequest, code *string) {
+				s.claims = func(c jwt.MapClaims) {
+					c["email_verified"] = false
+				}
+			},
+			wantIdentity: domain.Identity{
+				Provider: "keycloak",
+				Subject:  "user-1",
+			},
+		},
+		{
+			name: "wrong code verifier",
+			before: func(s *fakeOIDCServer, req *AuthRequest, code *string) {
+				req.CodeVerifier = "attacker"
+			},
+			wantErr: true,
+		},
+		{
+			name: "wrong code",
+			before: func(s *fakeOIDCServer, req *AuthRequest, code *string) {
+				*code = "bad-code"
+			},
+			wantErr: true,
+		},
+		{
+			name: "nonce not match",
+			before: func(s *fakeOIDCServer, req *AuthRequest, code *string) {
+				req.Nonce = "another"
+			},
+			wantErr: true,
+		},
+		{
+			name: "wrong audience",
+			before: func(s *fakeOIDCServer, req *AuthRequest, code *string) {
+				s.claims = func(c jwt.MapClaims) {
+					c["aud"] = "other-app"
+				}
+			},
+			wantErr: true,
+		},
+		{
+			name: "expired",
+			before: func(s *fakeOIDCServer, req *AuthRequest, code *string) {
+				s.claims = func(c jwt.MapClaims) {
+					c["exp"] = time.Now().Add(-time.Hour).Unix()
+				}
+			},
+			wantErr: true,
+		},
+		{
+			name: "bad signature",
+			before: func(s *fakeOIDCServer, req *AuthRequest, code *string) {
+				// 换一把 jwks 里面没有的 key 签名
+				other, err := rsa.GenerateKey(rand.Reader, 2048)
+				require.NoError(t, err)
+				s.key = other
+			},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newFakeOIDCServer(t)
+			p := NewOIDCProvider(Config{
+				Name:         "keycloak",
+				ClientID:     "webook",
+				ClientSecret: "secret",
+				RedirectURL:  "http://localhost:8080/oauth2/keycloak/callback",
+				Scopes:       []string{"email"},
+			}, s.URL, http.DefaultClient)
+			req, err := NewAuthRequest()
+			require.NoError(t, err)
+			authURL, err := p.AuthURL(context.Background(), req)
+			require.NoError(t, err)
+			s.authorize(t, authURL)
+
+			code := "good-code"
+			if tc.before != nil {
+				tc.before(s, &req, &code)
+			}
+			identity, err := p.VerifyCode(context.Background(), code, req)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantIdentity, identity)
+		})
+	}
+}
+
+func TestGenericProvider_VerifyCode(t *testing.T) {
+	// 模拟 GitHub：token 接口返回 JSON，用户信息里面 id 是数字
+	var challenge string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		req := AuthRequest{CodeVerifier: r.PostForm.Get("code_verifier")}
+		if req.CodeChallenge() != challenge {
+			// GitHub 出错也是 200
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "bad_verification_code"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"access_token": "gho_xxx"})
+	})
+	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer gho_xxx" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id": 12345678901, "login": "octocat", "email": null}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	p := NewGenericProvider(Config{
+		Name:     "github",
+		ClientID: "webook",
+	}, Endpoints{
+		AuthURL:      server.URL + "/login/oauth/authorize",
+		TokenURL:     server.URL + "/login/oauth/access_token",
+		UserInfoURL:  server.URL + "/user",
+		SubjectField: "id",
+	}, http.DefaultClient)
+	req, err := NewAuthRequest()
+	require.NoError(t, err)
+	authURL, err := p.AuthURL(context.Background(), req)
+	require.NoError(t, err)
+	u, err := url.Parse(authURL)
+	require.NoError(t, err)
+	assert.Empty(t, u.Query().Get("nonce"))
+	challenge = u.Query().Get("code_challenge")
+
+	identity, err := p.VerifyCode(context.Background(), "code", req)
+	require.NoError(t, err)
+	assert.Equal(t, domain.Identity{Provider: "github", Subject: "12345678901"}, identity)
+
+	req.CodeVerifier = "attacker"
+	_, err = p.VerifyCode(context.Background(), "code", req)
+	assert.Error(t, err)
+}
@@ -0,0 +1,85 @@
+package oauth2
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"sort"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+)
+
+var ErrUnknownProvider = errors.New("unknown oauth2 provider")
+
+// Provider 一个第三方登录平台，OIDC 用 NewOIDCProvider，只支持 OAuth2 的用 NewGenericProvider。
+// 微信不走这里，它不支持 PKCE，返回的也不是标准格式
+type Provider interface {
+	Name() string
+	AuthURL(ctx context.Context, req AuthRequest) (string, error)
+	// VerifyCode req 要和 AuthURL 的时候是同一个
+	VerifyCode(ctx context.Context, code string, req AuthRequest) (domain.Identity, error)
+}
+
+// AuthRequest 一次授权的参数，放在 state cookie 里面，回调的时候带回来
+type AuthRequest struct {
+	State string
+	// Nonce 只有 OIDC 用，会出现在 id_token 里面
+	Nonce string
+	// CodeVerifier PKCE 的原始值，授权的时候只发 S256 之后的 challenge
+	CodeVerifier string
+}
+
+func NewAuthRequest() (AuthRequest, error) {
+	var vals [3]string
+	for i := range vals {
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			return AuthRequest{}, err
+		}
+		vals[i] = base64.RawURLEncoding.EncodeToString(b)
+	}
+	return AuthRequest{
+		State:        vals[0],
+		Nonce:        vals[1],
+		CodeVerifier: vals[2],
+	}, nil
+}
+
+// CodeChallenge PKCE S256
+func (r AuthRequest) CodeChallenge() string {
+	sum := sha256.Sum256([]byte(r.CodeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+type Registry struct {
+	providers map[string]Provider
+}
+
+func NewRegistry(providers ...Provider) *Registry {
+	r := &Registry{
+		providers: make(map[string]Provider, len(providers)),
+	}
+	for _, p := range providers {
+		r.providers[p.Name()] = p
+	}
+	return r
+}
+
+func (r *Registry) Get(name string) (Provider, error) {
+	p, ok := r.providers[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	return p, nil
+}
+
+func (r *Registry) Names() []string {
+	res := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		res = append(res, name)
+	}
+	sort.Strings(res)
+	return res
+}
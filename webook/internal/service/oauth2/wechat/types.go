@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"time"
 
 
 	"gitee.com/geekbang/basic-go/webook/internal/domain"
@@ -15,73 +16,173 @@ import (
 
 type Service interface{
 	AuthURL(ctx context.Context, state string) (string, error)
-	VerifyCode(ctx context.Context, code string) (domain.WechatInfo, error)
-
+	// VerifyCode 返回的 token 里面没有 Uid
+	VerifyCode(ctx context.Context, code string) (domain.WechatInfo, domain.WechatToken, error)
+	// RefreshToken 返回的 token 里面没有 Uid 和 RefreshExpireAt，微信刷新不会延长 refresh token
+	RefreshToken(ctx context.Context, refreshToken string) (domain.WechatToken, error)
+	UserInfo(ctx context.Context, accessToken string, openId string) (domain.WechatProfile, error)
 }
 
-var redirectURL = url.PathEscape("https://meoying.com/oauth2/wechat/callback")
+const (
+	// DefaultAuthURL 网站应用扫码登录的页面
+	DefaultAuthURL = "https://open.weixin.qq.com/connect/qrconnect"
+	// DefaultBaseURL 微信开放平台的接口地址，测试的时候换成 httptest 的
+	DefaultBaseURL = "https://api.weixin.qq.com"
+)
 
-//var redirectURL = url.PathEscape("https://passport.yhd.com/wechat/callback.do")
+// refreshTokenTTL 微信文档里面写死的三十天
+const refreshTokenTTL = 30 * 24 * time.Hour
+
+type Config struct {
+	AppID     string
+	AppSecret string
+	// RedirectURL 微信授权之后回调我们的地址，要和开放平台上配置的域名一致
+	RedirectURL string
+	// AuthURL 为空就用 DefaultAuthURL
+	AuthURL string
+	// BaseURL 为空就用 DefaultBaseURL
+	BaseURL string
+}
 
 type service struct{
 	appID string
 	appSecret string
+	redirectURL string
+	authURL string
+	baseURL string
 	client *http.Client
 }
 
-func NewService(appID string, appSecret string) Service{
+func NewService(cfg Config) Service{
+	if cfg.AuthURL == "" {
+		cfg.AuthURL = DefaultAuthURL
+	}
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = DefaultBaseURL
+	}
 	return &service{
-		appID: appID,
-		appSecret: appSecret,
+		appID: cfg.AppID,
+		appSecret: cfg.AppSecret,
+		redirectURL: cfg.RedirectURL,
+		authURL: cfg.AuthURL,
+		baseURL: cfg.BaseURL,
 		client: http.DefaultClient,
 	}
 }
 
 func (s *service) VerifyCode(ctx context.Context, 
-	code string) (domain.WechatInfo, error){
-
-		acessTokenURL := fmt.Sprintf("https://api.weixin.qq.com/sns/oauth2/access_token?appid=%s&secret=%s&code=%s&grant_type=authorization_code",
-		s.appID, s.appSecret, code ) 
-		req, err := http.NewRequestWithContext(ctx, http.MethodGet, acessTokenURL, nil)
-		if err!=nil{
-			return domain.WechatInfo{}, err
-		}
-		httpResp, err := s.client.Do(req)
-		if err!=nil{
-			return domain.WechatInfo{}, err
-		}
-
+	code string) (domain.WechatInfo, domain.WechatToken, error){
+		q := url.Values{}
+		q.Set("appid", s.appID)
+		q.Set("secret", s.appSecret)
+		q.Set("code", code)
+		q.Set("grant_type", "authorization_code")
 		var res Result
-		err = json.NewDecoder(httpResp.Body).Decode(&res)
+		err := s.get(ctx, "/sns/oauth2/access_token", q, &res)
 		if err!=nil{
-			return domain.WechatInfo{}, err
-		}
-		if res.ErrCode!=0{
-			return domain.WechatInfo{}, fmt.Errorf("wechat api cal failure, error code:%d, error msg:%s",res.ErrCode, res.ErrMsg)
+			return domain.WechatInfo{}, domain.WechatToken{}, err
 		}
-	
+		now := time.Now()
+		tok := s.toToken(res, now)
+		tok.RefreshExpireAt = now.Add(refreshTokenTTL)
 		return domain.WechatInfo{
 			UnionId: res.UnionId,
 			OpenId: res.OpenId,
-		}, nil
+		}, tok, nil
+}
+
+func (s *service) RefreshToken(ctx context.Context, refreshToken string) (domain.WechatToken, error) {
+	q := url.Values{}
+	q.Set("appid", s.appID)
+	q.Set("grant_type", "refresh_token")
+	q.Set("refresh_token", refreshToken)
+	var res Result
+	err := s.get(ctx, "/sns/oauth2/refresh_token", q, &res)
+	if err != nil {
+		return domain.WechatToken{}, err
+	}
+	return s.toToken(res, time.Now()), nil
+}
+
+func (s *service) UserInfo(ctx context.Context, accessToken string, openId string) (domain.WechatProfile, error) {
+	q := url.Values{}
+	q.Set("access_token", accessToken)
+	q.Set("openid", openId)
+	var res UserInfoResult
+	err := s.get(ctx, "/sns/userinfo", q, &res)
+	if err != nil {
+		return domain.WechatProfile{}, err
+	}
+	return domain.WechatProfile{
+		Nickname: res.Nickname,
+		Avatar:   res.HeadImgURL,
+	}, nil
+}
+
+func (s *service) toToken(res Result, now time.Time) domain.WechatToken {
+	return domain.WechatToken{
+		OpenId:         res.OpenId,
+		AccessToken:    res.AccessToken,
+		RefreshToken:   res.RefreshToken,
+		AccessExpireAt: now.Add(time.Duration(res.ExpiresIn) * time.Second),
+	}
+}
+
+// get 微信的接口出错了也是 200，要看 errcode
+func (s *service) get(ctx context.Context, path string, q url.Values, val interface{ errResult() ErrResult }) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+path+"?"+q.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	httpResp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode != http.StatusOK {
+		return fmt.Errorf("wechat api call failure, http status:%d", httpResp.StatusCode)
+	}
+	err = json.NewDecoder(httpResp.Body).Decode(val)
+	if err != nil {
+		return err
+	}
+	if e := val.errResult(); e.ErrCode != 0 {
+		return fmt.Errorf("wechat api call failure, error code:%d, error msg:%s", e.ErrCode, e.ErrMsg)
+	}
+	return nil
 }
 
 func (s *service) AuthURL(ctx context.Context, state string) (string, error){
-	
-	
-	const authURLPattern = `https://open.weixin.qq.com/connect/qrconnect?appid=%s&redirect_uri=%s&response_type=code&scope=snsapi_login&state=%s#wechat_redirect`
-	return fmt.Sprintf(authURLPattern, s.appID, redirectURL, state), nil
+	// 微信会检查参数的顺序，所以不用 url.Values
+	const authURLPattern = `%s?appid=%s&redirect_uri=%s&response_type=code&scope=snsapi_login&state=%s#wechat_redirect`
+	return fmt.Sprintf(authURLPattern, s.authURL, url.QueryEscape(s.appID),
+		url.QueryEscape(s.redirectURL), url.QueryEscape(state)), nil
+}
+
+// ErrResult 微信接口出错的时候返回的字段
+type ErrResult struct {
+	ErrCode int64  `json:"errcode"`
+	ErrMsg  string `json:"errmsg"`
+}
 
+func (e ErrResult) errResult() ErrResult {
+	return e
 }
 
 type Result struct{
+	ErrResult
 	AccessToken	string `json:"access_token"`
 	ExpiresIn	int64 `json:"expires_in"`
 	RefreshToken	string `json:"refresh_token"`
 	OpenId	string `json:"openid"`
 	Scope	string `json:"scope"`
-	UnionId	string 	`json:"union_id"`
-	ErrCode int64 `json:"errcode"`
-	ErrMsg string  `json:"errmsg"`
+	UnionId	string 	`json:"unionid"`
+}
 
+type UserInfoResult struct {
+	ErrResult
+	OpenId     string `json:"openid"`
+	Nickname   string `json:"nickname"`
+	HeadImgURL string `json:"headimgurl"`
+	UnionId    string `json:"unionid"`
 }
\ No newline at end of file
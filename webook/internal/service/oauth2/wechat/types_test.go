@@ -0,0 +1,96 @@
+package wechat
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeWechatServer 只认 code=good-code、refresh_token=refresh-1、access_token=access-1
+func newFakeWechatServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sns/oauth2/access_token", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("appid") != "app" || q.Get("secret") != "secret" || q.Get("code") != "good-code" {
+			_, _ = w.Write([]byte(`{"errcode":40029,"errmsg":"invalid code"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"access_token":"access-1","expires_in":7200,"refresh_token":"refresh-1",
+			"openid":"open-1","scope":"snsapi_login","unionid":"union-1"}`))
+	})
+	mux.HandleFunc("/sns/oauth2/refresh_token", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("refresh_token") != "refresh-1" {
+			_, _ = w.Write([]byte(`{"errcode":40030,"errmsg":"invalid refresh_token"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"access_token":"access-2","expires_in":7200,"refresh_token":"refresh-1",
+			"openid":"open-1","scope":"snsapi_login"}`))
+	})
+	mux.HandleFunc("/sns/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("access_token") != "access-1" || q.Get("openid") != "open-1" {
+			_, _ = w.Write([]byte(`{"errcode":40001,"errmsg":"invalid credential"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"openid":"open-1","nickname":"小明",
+			"headimgurl":"https://thirdwx.qlogo.cn/1.png","unionid":"union-1"}`))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestService(t *testing.T) {
+	server := newFakeWechatServer(t)
+	svc := NewService(Config{
+		AppID:       "app",
+		AppSecret:   "secret",
+		RedirectURL: "http://localhost:8080/oauth2/wechat/callback",
+		AuthURL:     server.URL + "/connect/qrconnect",
+		BaseURL:     server.URL,
+	})
+	ctx := context.Background()
+
+	authURL, err := svc.AuthURL(ctx, "state-1")
+	require.NoError(t, err)
+	assert.Equal(t, server.URL+"/connect/qrconnect?appid=app"+
+		"&redirect_uri=http%3A%2F%2Flocalhost%3A8080%2Foauth2%2Fwechat%2Fcallback"+
+		"&response_type=code&scope=snsapi_login&state=state-1#wechat_redirect", authURL)
+
+	now := time.Now()
+	info, tok, err := svc.VerifyCode(ctx, "good-code")
+	require.NoError(t, err)
+	assert.Equal(t, domain.WechatInfo{OpenId: "open-1", UnionId: "union-1"}, info)
+	assert.Equal(t, "access-1", tok.AccessToken)
+	assert.Equal(t, "refresh-1", tok.RefreshToken)
+	assert.Equal(t, "open-1", tok.OpenId)
+	assert.WithinDuration(t, now.Add(2*time.Hour), tok.AccessExpireAt, time.Second)
+	assert.WithinDuration(t, now.Add(refreshTokenTTL), tok.RefreshExpireAt, time.Second)
+
+	_, _, err = svc.VerifyCode(ctx, "bad-code")
+	assert.Error(t, err)
+
+	tok, err = svc.RefreshToken(ctx, "refresh-1")
+	require.NoError(t, err)
+	assert.Equal(t, "access-2", tok.AccessToken)
+	assert.True(t, tok.RefreshExpireAt.IsZero())
+
+	_, err = svc.RefreshToken(ctx, "expired")
+	assert.Error(t, err)
+
+	profile, err := svc.UserInfo(ctx, "access-1", "open-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.WechatProfile{
+		Nickname: "小明",
+		Avatar:   "https://thirdwx.qlogo.cn/1.png",
+	}, profile)
+
+	_, err = svc.UserInfo(ctx, "access-2", "open-1")
+	assert.Error(t, err)
+}
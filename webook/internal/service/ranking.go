@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository"
+	"github.com/ecodeclub/ekit/queue"
+	"github.com/ecodeclub/ekit/slice"
+)
+
+type RankingService interface {
+	// TopN 计算热榜并且替换缓存里面的热榜，由定时任务调用
+	TopN(ctx context.Context) error
+	// GetTopN 读取计算好的热榜，算好之后撤回的文章会被过滤掉
+	GetTopN(ctx context.Context) ([]domain.Article, error)
+}
+
+// BatchRankingService 分批读取最近更新过的文章，按照点赞和阅读数随发表时间衰减之后的分数排序
+type BatchRankingService struct {
+	artSvc  ArticleService
+	intrSvc InteractiveService
+	repo    repository.RankingRepository
+	biz     string
+
+	batchSize int
+	n         int
+	// window 之前更新的文章就不参与排名了
+	window time.Duration
+	// scoreFunc ctime 是第一次发表的时间，后面再修改也不会重新变成新文章
+	scoreFunc func(likeCnt int64, readCnt int64, ctime time.Time) float64
+}
+
+func NewBatchRankingService(artSvc ArticleService, intrSvc InteractiveService,
+	repo repository.RankingRepository) RankingService {
+	return &BatchRankingService{
+		artSvc:    artSvc,
+		intrSvc:   intrSvc,
+		repo:      repo,
+		biz:       "article",
+		batchSize: 100,
+		n:         100,
+		window:    time.Hour * 24 * 7,
+		scoreFunc: func(likeCnt int64, readCnt int64, ctime time.Time) float64 {
+			// 点赞比阅读值钱，分数随发表时间按照小时衰减
+			hours := time.Since(ctime).Hours()
+			return float64(likeCnt*10+readCnt) / math.Pow(hours+2, 1.5)
+		},
+	}
+}
+
+func (svc *BatchRankingService) TopN(ctx context.Context) error {
+	arts, err := svc.topN(ctx)
+	if err != nil {
+		return err
+	}
+	return svc.repo.ReplaceTopN(ctx, arts)
+}
+
+func (svc *BatchRankingService) GetTopN(ctx context.Context) ([]domain.Article, error) {
+	arts, err := svc.repo.GetTopN(ctx)
+	if err != nil || len(arts) == 0 {
+		return arts, err
+	}
+	// 缓存里面的热榜要等下一次计算才会更新，这里按照主键查一下哪些还是发表状态
+	pubs, err := svc.artSvc.GetPublishedByIds(ctx, slice.Map(arts, func(idx int, src domain.Article) int64 {
+		return src.Id
+	}))
+	if err != nil {
+		return nil, err
+	}
+	published := make(map[int64]struct{}, len(pubs))
+	for _, art := range pubs {
+		published[art.Id] = struct{}{}
+	}
+	// 缓存里面存的是摘要，所以过滤缓存里面的，不直接用查出来的
+	return slice.FilterMap(arts, func(idx int, src domain.Article) (domain.Article, bool) {
+		_, ok := published[src.Id]
+		return src, ok
+	}), nil
+}
+
+func (svc *BatchRankingService) topN(ctx context.Context) ([]domain.Article, error) {
+	type Score struct {
+		art   domain.Article
+		score float64
+	}
+	// 小顶堆，堆顶是目前 top N 里面分数最低的
+	topN := queue.NewPriorityQueue[Score](svc.n, func(src Score, dst Score) int {
+		switch {
+		case src.score > dst.score:
+			return 1
+		case src.score < dst.score:
+			return -1
+		default:
+			return 0
+		}
+	})
+
+	now := time.Now()
+	ddl := now.Add(-svc.window)
+	offset := 0
+	for {
+		arts, err := svc.artSvc.ListPub(ctx, now, offset, svc.batchSize)
+		if err != nil {
+			return nil, err
+		}
+		if len(arts) == 0 {
+			break
+		}
+		ids := slice.Map(arts, func(idx int, src domain.Article) int64 {
+			return src.Id
+		})
+		intrs, err := svc.intrSvc.GetByIds(ctx, svc.biz, ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, art := range arts {
+			if art.Utime.Before(ddl) {
+				continue
+			}
+			intr := intrs[art.Id]
+			score := Score{
+				art:   art,
+				score: svc.scoreFunc(intr.LikeCnt, intr.ReadCnt, art.Ctime),
+			}
+			if topN.Len() < svc.n {
+				_ = topN.Enqueue(score)
+				continue
+			}
+			min, _ := topN.Peek()
+			if min.score < score.score {
+				_, _ = topN.Dequeue()
+				_ = topN.Enqueue(score)
+			}
+		}
+		// 按照 utime 倒序查的，这一批不够或者已经超出了时间窗口，后面的就不用看了
+		if len(arts) < svc.batchSize || arts[len(arts)-1].Utime.Before(ddl) {
+			break
+		}
+		offset += len(arts)
+	}
+
+	res := make([]domain.Article, topN.Len())
+	for i := len(res) - 1; i >= 0; i-- {
+		// 出队的顺序是分数从低到高
+		s, _ := topN.Dequeue()
+		res[i] = s.art
+	}
+	return res, nil
+}
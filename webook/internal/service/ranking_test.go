@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	repomocks "gitee.com/geekbang/basic-go/webook/internal/repository/mocks"
+	svcmocks "gitee.com/geekbang/basic-go/webook/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestBatchRankingService_TopN(t *testing.T) {
+	now := time.Now()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	artSvc := svcmocks.NewMockArticleService(ctrl)
+	intrSvc := svcmocks.NewMockInteractiveService(ctrl)
+	repo := repomocks.NewMockRankingRepository(ctrl)
+
+	artSvc.EXPECT().ListPub(gomock.Any(), gomock.Any(), 0, 2).
+		Return([]domain.Article{
+			{Id: 1, Utime: now},
+			{Id: 2, Utime: now},
+		}, nil)
+	artSvc.EXPECT().ListPub(gomock.Any(), gomock.Any(), 2, 2).
+		Return([]domain.Article{
+			{Id: 3, Utime: now},
+			// 超出时间窗口，不参与排名
+			{Id: 4, Utime: now.Add(-time.Hour * 24 * 8)},
+		}, nil)
+	intrSvc.EXPECT().GetByIds(gomock.Any(), "article", []int64{1, 2}).
+		Return(map[int64]domain.Interactive{
+			1: {BizId: 1, LikeCnt: 1},
+			2: {BizId: 2, LikeCnt: 3},
+		}, nil)
+	intrSvc.EXPECT().GetByIds(gomock.Any(), "article", []int64{3, 4}).
+		Return(map[int64]domain.Interactive{
+			3: {BizId: 3, LikeCnt: 2},
+			4: {BizId: 4, LikeCnt: 100},
+		}, nil)
+	repo.EXPECT().ReplaceTopN(gomock.Any(), []domain.Article{
+		{Id: 2, Utime: now},
+		{Id: 3, Utime: now},
+	}).Return(nil)
+
+	svc := NewBatchRankingService(artSvc, intrSvc, repo).(*BatchRankingService)
+	svc.batchSize = 2
+	svc.n = 2
+	svc.scoreFunc = func(likeCnt int64, readCnt int64, ctime time.Time) float64 {
+		return float64(likeCnt)
+	}
+	err := svc.TopN(context.Background())
+	require.NoError(t, err)
+}
+
+func TestBatchRankingService_TopNEmpty(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	artSvc := svcmocks.NewMockArticleService(ctrl)
+	repo := repomocks.NewMockRankingRepository(ctrl)
+	artSvc.EXPECT().ListPub(gomock.Any(), gomock.Any(), 0, 100).
+		Return([]domain.Article{}, nil)
+	repo.EXPECT().ReplaceTopN(gomock.Any(), []domain.Article{}).Return(nil)
+
+	svc := NewBatchRankingService(artSvc, nil, repo)
+	assert.NoError(t, svc.TopN(context.Background()))
+}
+
+func TestBatchRankingService_GetTopN(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	artSvc := svcmocks.NewMockArticleService(ctrl)
+	repo := repomocks.NewMockRankingRepository(ctrl)
+	repo.EXPECT().GetTopN(gomock.Any()).Return([]domain.Article{
+		{Id: 3, Content: "摘要3"},
+		{Id: 1, Content: "摘要1"},
+		{Id: 2, Content: "摘要2"},
+	}, nil)
+	// 1 算完热榜之后被撤回了
+	artSvc.EXPECT().GetPublishedByIds(gomock.Any(), []int64{3, 1, 2}).
+		Return([]domain.Article{
+			{Id: 2, Content: "全文2"},
+			{Id: 3, Content: "全文3"},
+		}, nil)
+
+	svc := NewBatchRankingService(artSvc, nil, repo)
+	arts, err := svc.GetTopN(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Article{
+		{Id: 3, Content: "摘要3"},
+		{Id: 2, Content: "摘要2"},
+	}, arts)
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository"
+)
+
+// ErrUnknownRole role_permissions 里面没有这个角色
+var ErrUnknownRole = errors.New("未知的角色")
+
+type RBACService interface {
+	// Authz 拿角色和权限，用户被封禁了返回 ErrUserBanned
+	Authz(ctx context.Context, uid int64) (domain.Authz, error)
+	// GrantRole operator 是管理员，已经有这个角色不报错，不能给封禁的用户授予角色
+	GrantRole(ctx context.Context, operator int64, uid int64, role domain.Role) error
+	RevokeRole(ctx context.Context, operator int64, uid int64, role domain.Role) error
+}
+
+type DefaultRBACService struct {
+	userRepo repository.UserRepository
+	roleRepo repository.RoleRepository
+}
+
+func NewRBACService(userRepo repository.UserRepository,
+	roleRepo repository.RoleRepository) RBACService {
+	return &DefaultRBACService{
+		userRepo: userRepo,
+		roleRepo: roleRepo,
+	}
+}
+
+func (svc *DefaultRBACService) Authz(ctx context.Context, uid int64) (domain.Authz, error) {
+	u, err := svc.userRepo.FindById(ctx, uid)
+	if err != nil {
+		return domain.Authz{}, err
+	}
+	if u.Banned() {
+		return domain.Authz{}, ErrUserBanned
+	}
+	return svc.roleRepo.FindAuthz(ctx, uid)
+}
+
+func (svc *DefaultRBACService) GrantRole(ctx context.Context, operator int64,
+	uid int64, role domain.Role) error {
+	perms, err := svc.roleRepo.FindPermissions(ctx, role)
+	if err != nil {
+		return err
+	}
+	if len(perms) == 0 {
+		return ErrUnknownRole
+	}
+	u, err := svc.userRepo.FindById(ctx, uid)
+	if err != nil {
+		return err
+	}
+	if u.Banned() {
+		return ErrUserBanned
+	}
+	return svc.roleRepo.GrantRole(ctx, uid, role, domain.UserAudit{
+		Action:   domain.UserAuditGrantRole,
+		After:    string(role),
+		Operator: operator,
+	})
+}
+
+func (svc *DefaultRBACService) RevokeRole(ctx context.Context, operator int64,
+	uid int64, role domain.Role) error {
+	return svc.roleRepo.RevokeRole(ctx, uid, role, domain.UserAudit{
+		Action:   domain.UserAuditRevokeRole,
+		Before:   string(role),
+		Operator: operator,
+	})
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository"
+	repomocks "gitee.com/geekbang/basic-go/webook/internal/repository/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDefaultRBACService_Authz(t *testing.T) {
+	adminAuthz := domain.Authz{
+		Roles:       []domain.Role{domain.RoleAdmin},
+		Permissions: []domain.Permission{domain.PermissionUserRead},
+	}
+	testCases := []struct {
+		name      string
+		mock      func(ctrl *gomock.Controller) (repository.UserRepository, repository.RoleRepository)
+		wantAuthz domain.Authz
+		wantErr   error
+	}{
+		{
+			name: "admin",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository, repository.RoleRepository) {
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				userRepo.EXPECT().FindById(gomock.Any(), int64(123)).
+					Return(domain.User{Id: 123}, nil)
+				roleRepo := repomocks.NewMockRoleRepository(ctrl)
+				roleRepo.EXPECT().FindAuthz(gomock.Any(), int64(123)).Return(adminAuthz, nil)
+				return userRepo, roleRepo
+			},
+			wantAuthz: adminAuthz,
+		},
+		{
+			name: "banned",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository, repository.RoleRepository) {
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				userRepo.EXPECT().FindById(gomock.Any(), int64(123)).
+					Return(domain.User{Id: 123, BannedAt: time.UnixMilli(100)}, nil)
+				return userRepo, repomocks.NewMockRoleRepository(ctrl)
+			},
+			wantErr: ErrUserBanned,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewRBACService(tc.mock(ctrl))
+			authz, err := svc.Authz(context.Background(), 123)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantAuthz, authz)
+		})
+	}
+}
+
+func TestDefaultRBACService_GrantRole(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) (repository.UserRepository, repository.RoleRepository)
+		role    domain.Role
+		wantErr error
+	}{
+		{
+			name: "granted",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository, repository.RoleRepository) {
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				userRepo.EXPECT().FindById(gomock.Any(), int64(123)).
+					Return(domain.User{Id: 123}, nil)
+				roleRepo := repomocks.NewMockRoleRepository(ctrl)
+				roleRepo.EXPECT().FindPermissions(gomock.Any(), domain.RoleAdmin).
+					Return([]domain.Permission{domain.PermissionUserRead}, nil)
+				roleRepo.EXPECT().GrantRole(gomock.Any(), int64(123), domain.RoleAdmin, domain.UserAudit{
+					Action:   domain.UserAuditGrantRole,
+					After:    "admin",
+					Operator: 1,
+				}).Return(nil)
+				return userRepo, roleRepo
+			},
+			role: domain.RoleAdmin,
+		},
+		{
+			name: "unknown role",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository, repository.RoleRepository) {
+				roleRepo := repomocks.NewMockRoleRepository(ctrl)
+				roleRepo.EXPECT().FindPermissions(gomock.Any(), domain.Role("root")).
+					Return([]domain.Permission{}, nil)
+				return repomocks.NewMockUserRepository(ctrl), roleRepo
+			},
+			role:    "root",
+			wantErr: ErrUnknownRole,
+		},
+		{
+			name: "no user",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository, repository.RoleRepository) {
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				userRepo.EXPECT().FindById(gomock.Any(), int64(123)).
+					Return(domain.User{}, repository.ErrUserNotFound)
+				roleRepo := repomocks.NewMockRoleRepository(ctrl)
+				roleRepo.EXPECT().FindPermissions(gomock.Any(), domain.RoleAdmin).
+					Return([]domain.Permission{domain.PermissionUserRead}, nil)
+				return userRepo, roleRepo
+			},
+			role:    domain.RoleAdmin,
+			wantErr: ErrUserNotFound,
+		},
+		{
+			name: "banned",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository, repository.RoleRepository) {
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				userRepo.EXPECT().FindById(gomock.Any(), int64(123)).
+					Return(domain.User{Id: 123, BannedAt: time.UnixMilli(100)}, nil)
+				roleRepo := repomocks.NewMockRoleRepository(ctrl)
+				roleRepo.EXPECT().FindPermissions(gomock.Any(), domain.RoleAdmin).
+					Return([]domain.Permission{domain.PermissionUserRead}, nil)
+				return userRepo, roleRepo
+			},
+			role:    domain.RoleAdmin,
+			wantErr: ErrUserBanned,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewRBACService(tc.mock(ctrl))
+			err := svc.GrantRole(context.Background(), 1, 123, tc.role)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository"
+	"go.uber.org/zap"
+)
+
+type readCntKey struct {
+	biz   string
+	bizId int64
+}
+
+// ReadCntBuffer 在内存里面合并阅读计数，攒够 batchSize 篇文章或者每隔 interval 批量写一次数据库。
+// 退出之前要调用 Close，把还没写进去的计数刷到数据库。
+type ReadCntBuffer struct {
+	repo      repository.InteractiveRepository
+	l         *zap.Logger
+	batchSize int
+	interval  time.Duration
+
+	mu     sync.Mutex
+	deltas map[readCntKey]int64
+
+	flushCh chan struct{}
+	closeCh chan struct{}
+	done    chan struct{}
+	once    sync.Once
+	// maxBackoff Close 里面重试的最长间隔
+	maxBackoff time.Duration
+}
+
+func NewReadCntBuffer(repo repository.InteractiveRepository, l *zap.Logger,
+	batchSize int, interval time.Duration) *ReadCntBuffer {
+	res := &ReadCntBuffer{
+		repo:      repo,
+		l:         l,
+		batchSize: batchSize,
+		interval:  interval,
+		deltas:    make(map[readCntKey]int64, batchSize),
+		flushCh:   make(chan struct{}, 1),
+		closeCh:   make(chan struct{}),
+		done:      make(chan struct{}),
+
+		maxBackoff: time.Second * 3,
+	}
+	go res.loop()
+	return res
+}
+
+func (b *ReadCntBuffer) Add(biz string, bizId int64) {
+	b.mu.Lock()
+	b.deltas[readCntKey{biz: biz, bizId: bizId}]++
+	full := len(b.deltas) >= b.batchSize
+	b.mu.Unlock()
+	if full {
+		// 已经有一个刷新信号在排队的话就不用再发了
+		select {
+		case b.flushCh <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Close 停止定时刷新，并且把剩下的计数写进数据库。
+// 写失败了会退避重试，直到 ctx 过期，这个时候还没写进去的计数就丢了
+func (b *ReadCntBuffer) Close(ctx context.Context) error {
+	b.once.Do(func() {
+		close(b.closeCh)
+	})
+	// 等后台的刷新停下来，免得两边同时写
+	select {
+	case <-b.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	backoff := time.Millisecond * 100
+	for {
+		err := b.flush(ctx)
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			b.mu.Lock()
+			lost := len(b.deltas)
+			b.mu.Unlock()
+			b.l.Error("read count lost on close", zap.Error(err), zap.Int("size", lost))
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, b.maxBackoff)
+	}
+}
+
+func (b *ReadCntBuffer) loop() {
+	defer close(b.done)
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			_ = b.flush(context.Background())
+		case <-b.flushCh:
+			_ = b.flush(context.Background())
+		case <-b.closeCh:
+			// 最后一次由 Close 来刷，它会重试
+			return
+		}
+	}
+}
+
+// flush 写失败的计数会放回去，下一次一起写
+func (b *ReadCntBuffer) flush(ctx context.Context) error {
+	b.mu.Lock()
+	if len(b.deltas) == 0 {
+		b.mu.Unlock()
+		return nil
+	}
+	deltas := b.deltas
+	b.deltas = make(map[readCntKey]int64, b.batchSize)
+	b.mu.Unlock()
+
+	batch := make([]domain.ReadCntDelta, 0, len(deltas))
+	for key, delta := range deltas {
+		batch = append(batch, domain.ReadCntDelta{
+			Biz:   key.biz,
+			BizId: key.bizId,
+			Delta: delta,
+		})
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Second*3)
+	defer cancel()
+	err := b.repo.BatchIncrReadCnt(ctx, batch)
+	if err == nil {
+		return nil
+	}
+	// 写失败了放回去，下一次一起写，不能丢
+	b.l.Error("batch incr read count failed", zap.Error(err), zap.Int("size", len(batch)))
+	b.mu.Lock()
+	for key, delta := range deltas {
+		b.deltas[key] += delta
+	}
+	b.mu.Unlock()
+	return err
+}
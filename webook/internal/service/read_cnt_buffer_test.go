@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	repomocks "gitee.com/geekbang/basic-go/webook/internal/repository/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+func TestReadCntBuffer_FlushOnBatchSize(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := repomocks.NewMockInteractiveRepository(ctrl)
+	flushed := make(chan []domain.ReadCntDelta, 1)
+	repo.EXPECT().BatchIncrReadCnt(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, deltas []domain.ReadCntDelta) error {
+			flushed <- deltas
+			return nil
+		})
+
+	// interval 足够长，只有攒够一批才会写
+	b := NewReadCntBuffer(repo, zap.NewNop(), 2, time.Hour)
+	b.Add("article", 1)
+	b.Add("article", 1)
+	b.Add("article", 2)
+
+	select {
+	case deltas := <-flushed:
+		sort.Slice(deltas, func(i, j int) bool {
+			return deltas[i].BizId < deltas[j].BizId
+		})
+		assert.Equal(t, []domain.ReadCntDelta{
+			{Biz: "article", BizId: 1, Delta: 2},
+			{Biz: "article", BizId: 2, Delta: 1},
+		}, deltas)
+	case <-time.After(time.Second):
+		t.Fatal("buffer was not flushed")
+	}
+	require.NoError(t, b.Close(context.Background()))
+}
+
+func TestReadCntBuffer_FlushOnClose(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := repomocks.NewMockInteractiveRepository(ctrl)
+	repo.EXPECT().BatchIncrReadCnt(gomock.Any(), []domain.ReadCntDelta{
+		{Biz: "article", BizId: 1, Delta: 3},
+	}).Return(nil)
+
+	b := NewReadCntBuffer(repo, zap.NewNop(), 100, time.Hour)
+	b.Add("article", 1)
+	b.Add("article", 1)
+	b.Add("article", 1)
+	require.NoError(t, b.Close(context.Background()))
+}
+
+func TestReadCntBuffer_KeepDeltasOnError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := repomocks.NewMockInteractiveRepository(ctrl)
+	gomock.InOrder(
+		repo.EXPECT().BatchIncrReadCnt(gomock.Any(), []domain.ReadCntDelta{
+			{Biz: "article", BizId: 1, Delta: 1},
+		}).Return(errors.New("db error")),
+		// 第一次失败的计数要带到下一次
+		repo.EXPECT().BatchIncrReadCnt(gomock.Any(), []domain.ReadCntDelta{
+			{Biz: "article", BizId: 1, Delta: 2},
+		}).Return(nil),
+	)
+
+	b := NewReadCntBuffer(repo, zap.NewNop(), 100, time.Hour)
+	b.Add("article", 1)
+	_ = b.flush(context.Background())
+	b.Add("article", 1)
+	require.NoError(t, b.Close(context.Background()))
+}
+
+func TestReadCntBuffer_RetryOnClose(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := repomocks.NewMockInteractiveRepository(ctrl)
+	gomock.InOrder(
+		repo.EXPECT().BatchIncrReadCnt(gomock.Any(), gomock.Any()).
+			Return(errors.New("db error")).Times(2),
+		// 数据库恢复之后写进去
+		repo.EXPECT().BatchIncrReadCnt(gomock.Any(), []domain.ReadCntDelta{
+			{Biz: "article", BizId: 1, Delta: 1},
+		}).Return(nil),
+	)
+
+	b := NewReadCntBuffer(repo, zap.NewNop(), 100, time.Hour)
+	b.Add("article", 1)
+	require.NoError(t, b.Close(context.Background()))
+}
+
+func TestReadCntBuffer_CloseTimeout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := repomocks.NewMockInteractiveRepository(ctrl)
+	repo.EXPECT().BatchIncrReadCnt(gomock.Any(), gomock.Any()).
+		Return(errors.New("db error")).AnyTimes()
+
+	b := NewReadCntBuffer(repo, zap.NewNop(), 100, time.Hour)
+	b.Add("article", 1)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*500)
+	defer cancel()
+	// 一直写不进去，等到 ctx 过期为止
+	assert.Equal(t, context.DeadlineExceeded, b.Close(ctx))
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository"
+)
+
+var ErrSessionNotFound = errors.New("session not found")
+
+type SessionService interface {
+	// Record 登录成功之后记录会话
+	Record(ctx context.Context, s domain.Session) error
+	List(ctx context.Context, uid int64) ([]domain.Session, error)
+	// Remove 删除会话记录，不是 uid 的会话返回 ErrSessionNotFound。
+	// 让会话的 token 失效是调用者的事情。
+	Remove(ctx context.Context, uid int64, ssid string) error
+	// RemoveOthers 删除 uid 除了 current 以外的会话，返回被删除的 ssid
+	RemoveOthers(ctx context.Context, uid int64, current string) ([]string, error)
+}
+
+type DefaultSessionService struct {
+	repo repository.SessionRepository
+}
+
+func NewSessionService(repo repository.SessionRepository) SessionService {
+	return &DefaultSessionService{
+		repo: repo,
+	}
+}
+
+func (svc *DefaultSessionService) Record(ctx context.Context, s domain.Session) error {
+	return svc.repo.Add(ctx, s)
+}
+
+func (svc *DefaultSessionService) List(ctx context.Context, uid int64) ([]domain.Session, error) {
+	return svc.repo.List(ctx, uid)
+}
+
+func (svc *DefaultSessionService) Remove(ctx context.Context, uid int64, ssid string) error {
+	ok, err := svc.repo.Remove(ctx, uid, ssid)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+func (svc *DefaultSessionService) RemoveOthers(ctx context.Context, uid int64, current string) ([]string, error) {
+	sessions, err := svc.repo.List(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]string, 0, len(sessions))
+	for _, s := range sessions {
+		if s.Ssid == current {
+			continue
+		}
+		if _, err = svc.repo.Remove(ctx, uid, s.Ssid); err != nil {
+			return res, err
+		}
+		res = append(res, s.Ssid)
+	}
+	return res, nil
+}
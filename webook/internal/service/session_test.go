@@ -0,0 +1,29 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	repomocks "gitee.com/geekbang/basic-go/webook/internal/repository/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDefaultSessionService_RemoveOthers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := repomocks.NewMockSessionRepository(ctrl)
+	repo.EXPECT().List(gomock.Any(), int64(123)).Return([]domain.Session{
+		{Ssid: "ssid-1", Uid: 123},
+		{Ssid: "ssid-2", Uid: 123},
+		{Ssid: "ssid-3", Uid: 123},
+	}, nil)
+	repo.EXPECT().Remove(gomock.Any(), int64(123), "ssid-1").Return(true, nil)
+	repo.EXPECT().Remove(gomock.Any(), int64(123), "ssid-3").Return(true, nil)
+
+	svc := NewSessionService(repo)
+	ssids, err := svc.RemoveOthers(context.Background(), 123, "ssid-2")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ssid-1", "ssid-3"}, ssids)
+}
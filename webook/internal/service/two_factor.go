@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository"
+	"gitee.com/geekbang/basic-go/webook/pkg/totp"
+	uuid "github.com/lithammer/shortuuid/v4"
+)
+
+var (
+	ErrTwoFactorEnabled     = errors.New("已经开启了两步验证")
+	ErrTwoFactorNotEnrolled = errors.New("还没有绑定认证器")
+	ErrInvalidTwoFactorCode = errors.New("两步验证的 code 不对")
+	ErrChallengeNotFound    = repository.ErrChallengeNotFound
+)
+
+type TwoFactorService interface {
+	// Enroll 生成新的密钥，返回密钥和 otpauth 链接，account 会显示在认证器里面
+	Enroll(ctx context.Context, uid int64, account string) (string, string, error)
+	// Confirm 用认证器上的第一个 code 确认绑定，返回恢复码明文，只有这一次能拿到
+	Confirm(ctx context.Context, uid int64, code string) ([]string, error)
+	Enabled(ctx context.Context, uid int64) (bool, error)
+	// Challenge 密码验证通过之后调用，返回挑战 token
+	Challenge(ctx context.Context, uid int64) (string, error)
+	// ChallengeUid 挑战对应的 uid，不算尝试次数。校验 code 之前用来查登录失败次数
+	ChallengeUid(ctx context.Context, token string) (int64, error)
+	// VerifyChallenge code 可以是 TOTP 也可以是恢复码，通过之后挑战作废，返回 uid
+	VerifyChallenge(ctx context.Context, token string, code string) (int64, error)
+}
+
+type TOTPTwoFactorService struct {
+	repo              repository.TwoFactorRepository
+	issuer            string
+	recoveryCodeCount int
+	now               func() time.Time
+}
+
+func NewTwoFactorService(repo repository.TwoFactorRepository) TwoFactorService {
+	return &TOTPTwoFactorService{
+		repo:              repo,
+		issuer:            "webook",
+		recoveryCodeCount: 10,
+		now:               time.Now,
+	}
+}
+
+func (svc *TOTPTwoFactorService) Enroll(ctx context.Context, uid int64, account string) (string, string, error) {
+	tf, err := svc.repo.FindByUid(ctx, uid)
+	switch err {
+	case nil:
+		if tf.Enabled {
+			return "", "", ErrTwoFactorEnabled
+		}
+	case repository.ErrTwoFactorNotFound:
+	default:
+		return "", "", err
+	}
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return "", "", err
+	}
+	err = svc.repo.Save(ctx, domain.TwoFactor{
+		Uid:    uid,
+		Secret: secret,
+	})
+	if err != nil {
+		return "", "", err
+	}
+	return secret, totp.URI(svc.issuer, account, secret), nil
+}
+
+func (svc *TOTPTwoFactorService) Confirm(ctx context.Context, uid int64, code string) ([]string, error) {
+	tf, err := svc.repo.FindByUid(ctx, uid)
+	if err == repository.ErrTwoFactorNotFound {
+		return nil, ErrTwoFactorNotEnrolled
+	}
+	if err != nil {
+		return nil, err
+	}
+	if tf.Enabled {
+		return nil, ErrTwoFactorEnabled
+	}
+	step, ok := totp.Validate(tf.Secret, code, svc.now())
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+	codes := make([]string, 0, svc.recoveryCodeCount)
+	hashes := make([]string, 0, svc.recoveryCodeCount)
+	for i := 0; i < svc.recoveryCodeCount; i++ {
+		c, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, c)
+		hashes = append(hashes, hashRecoveryCode(c))
+	}
+	if err = svc.repo.Enable(ctx, uid, step, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (svc *TOTPTwoFactorService) Enabled(ctx context.Context, uid int64) (bool, error) {
+	tf, err := svc.repo.FindByUid(ctx, uid)
+	if err == repository.ErrTwoFactorNotFound {
+		return false, nil
+	}
+	return tf.Enabled, err
+}
+
+func (svc *TOTPTwoFactorService) Challenge(ctx context.Context, uid int64) (string, error) {
+	token := uuid.New()
+	return token, svc.repo.SetChallenge(ctx, token, uid)
+}
+
+func (svc *TOTPTwoFactorService) ChallengeUid(ctx context.Context, token string) (int64, error) {
+	return svc.repo.PeekChallenge(ctx, token)
+}
+
+func (svc *TOTPTwoFactorService) VerifyChallenge(ctx context.Context, token string, code string) (int64, error) {
+	uid, err := svc.repo.GetChallenge(ctx, token)
+	if err != nil {
+		return 0, err
+	}
+	if err = svc.verify(ctx, uid, code); err != nil {
+		return 0, err
+	}
+	// 删不掉也没关系，过几分钟自己就过期了
+	_ = svc.repo.DelChallenge(ctx, token)
+	return uid, nil
+}
+
+func (svc *TOTPTwoFactorService) verify(ctx context.Context, uid int64, code string) error {
+	tf, err := svc.repo.FindByUid(ctx, uid)
+	if err == repository.ErrTwoFactorNotFound {
+		return ErrTwoFactorNotEnrolled
+	}
+	if err != nil {
+		return err
+	}
+	if !tf.Enabled {
+		return ErrTwoFactorNotEnrolled
+	}
+	code = normalizeCode(code)
+	if isTOTPCode(code) {
+		step, ok := totp.Validate(tf.Secret, code, svc.now())
+		if !ok {
+			return ErrInvalidTwoFactorCode
+		}
+		err = svc.repo.UseStep(ctx, uid, step)
+		if err == repository.ErrTOTPStepUsed {
+			return ErrInvalidTwoFactorCode
+		}
+		return err
+	}
+	err = svc.repo.UseRecoveryCode(ctx, uid, hashRecoveryCode(code))
+	if err == repository.ErrRecoveryCodeNotFound {
+		return ErrInvalidTwoFactorCode
+	}
+	return err
+}
+
+var recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// generateRecoveryCode 十个字符，中间用 - 隔开方便抄写，比如 abcde-fghij
+func generateRecoveryCode() (string, error) {
+	buf := make([]byte, 7)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	s := strings.ToLower(recoveryEncoding.EncodeToString(buf))[:10]
+	return s[:5] + "-" + s[5:], nil
+}
+
+func hashRecoveryCode(code string) string {
+	sum := sha256.Sum256([]byte(normalizeCode(code)))
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeCode 用户输入的时候可能带空格、横线或者大写
+func normalizeCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
+
+func isTOTPCode(code string) bool {
+	if len(code) != 6 {
+		return false
+	}
+	for _, c := range code {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
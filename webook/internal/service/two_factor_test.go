@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository"
+	repomocks "gitee.com/geekbang/basic-go/webook/internal/repository/mocks"
+	"gitee.com/geekbang/basic-go/webook/pkg/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+const testTOTPSecret = "JBSWY3DPEHPK3PXP"
+
+func TestTOTPTwoFactorService_Confirm(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	code, err := totp.Generate(testTOTPSecret, now)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name      string
+		mock      func(ctrl *gomock.Controller) repository.TwoFactorRepository
+		code      string
+		wantCodes int
+		wantErr   error
+	}{
+		{
+			name: "confirmed",
+			mock: func(ctrl *gomock.Controller) repository.TwoFactorRepository {
+				repo := repomocks.NewMockTwoFactorRepository(ctrl)
+				repo.EXPECT().FindByUid(gomock.Any(), int64(123)).
+					Return(domain.TwoFactor{Uid: 123, Secret: testTOTPSecret}, nil)
+				repo.EXPECT().Enable(gomock.Any(), int64(123), now.Unix()/30, gomock.Len(10)).Return(nil)
+				return repo
+			},
+			code:      code,
+			wantCodes: 10,
+		},
+		{
+			name: "wrong code",
+			mock: func(ctrl *gomock.Controller) repository.TwoFactorRepository {
+				repo := repomocks.NewMockTwoFactorRepository(ctrl)
+				repo.EXPECT().FindByUid(gomock.Any(), int64(123)).
+					Return(domain.TwoFactor{Uid: 123, Secret: testTOTPSecret}, nil)
+				return repo
+			},
+			code:    "000000",
+			wantErr: ErrInvalidTwoFactorCode,
+		},
+		{
+			name: "not enrolled",
+			mock: func(ctrl *gomock.Controller) repository.TwoFactorRepository {
+				repo := repomocks.NewMockTwoFactorRepository(ctrl)
+				repo.EXPECT().FindByUid(gomock.Any(), int64(123)).
+					Return(domain.TwoFactor{}, repository.ErrTwoFactorNotFound)
+				return repo
+			},
+			code:    code,
+			wantErr: ErrTwoFactorNotEnrolled,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewTwoFactorService(tc.mock(ctrl)).(*TOTPTwoFactorService)
+			svc.now = func() time.Time { return now }
+			codes, err := svc.Confirm(context.Background(), 123, tc.code)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Len(t, codes, tc.wantCodes)
+		})
+	}
+}
+
+func TestTOTPTwoFactorService_VerifyChallenge(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	code, err := totp.Generate(testTOTPSecret, now)
+	require.NoError(t, err)
+	enabled := domain.TwoFactor{Uid: 123, Secret: testTOTPSecret, Enabled: true}
+
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) repository.TwoFactorRepository
+		code    string
+		wantUid int64
+		wantErr error
+	}{
+		{
+			name: "totp code",
+			mock: func(ctrl *gomock.Controller) repository.TwoFactorRepository {
+				repo := repomocks.NewMockTwoFactorRepository(ctrl)
+				repo.EXPECT().GetChallenge(gomock.Any(), "challenge").Return(int64(123), nil)
+				repo.EXPECT().FindByUid(gomock.Any(), int64(123)).Return(enabled, nil)
+				repo.EXPECT().UseStep(gomock.Any(), int64(123), now.Unix()/30).Return(nil)
+				repo.EXPECT().DelChallenge(gomock.Any(), "challenge").Return(nil)
+				return repo
+			},
+			code:    code,
+			wantUid: 123,
+		},
+		{
+			name: "totp code replayed",
+			mock: func(ctrl *gomock.Controller) repository.TwoFactorRepository {
+				repo := repomocks.NewMockTwoFactorRepository(ctrl)
+				repo.EXPECT().GetChallenge(gomock.Any(), "challenge").Return(int64(123), nil)
+				repo.EXPECT().FindByUid(gomock.Any(), int64(123)).Return(enabled, nil)
+				repo.EXPECT().UseStep(gomock.Any(), int64(123), now.Unix()/30).
+					Return(repository.ErrTOTPStepUsed)
+				return repo
+			},
+			code:    code,
+			wantErr: ErrInvalidTwoFactorCode,
+		},
+		{
+			name: "recovery code",
+			mock: func(ctrl *gomock.Controller) repository.TwoFactorRepository {
+				repo := repomocks.NewMockTwoFactorRepository(ctrl)
+				repo.EXPECT().GetChallenge(gomock.Any(), "challenge").Return(int64(123), nil)
+				repo.EXPECT().FindByUid(gomock.Any(), int64(123)).Return(enabled, nil)
+				// 大小写和横线都不影响
+				repo.EXPECT().UseRecoveryCode(gomock.Any(), int64(123), hashRecoveryCode("abcdefghij")).
+					Return(nil)
+				repo.EXPECT().DelChallenge(gomock.Any(), "challenge").Return(nil)
+				return repo
+			},
+			code:    "ABCDE-FGHIJ",
+			wantUid: 123,
+		},
+		{
+			name: "recovery code used",
+			mock: func(ctrl *gomock.Controller) repository.TwoFactorRepository {
+				repo := repomocks.NewMockTwoFactorRepository(ctrl)
+				repo.EXPECT().GetChallenge(gomock.Any(), "challenge").Return(int64(123), nil)
+				repo.EXPECT().FindByUid(gomock.Any(), int64(123)).Return(enabled, nil)
+				repo.EXPECT().UseRecoveryCode(gomock.Any(), int64(123), gomock.Any()).
+					Return(repository.ErrRecoveryCodeNotFound)
+				return repo
+			},
+			code:    "abcde-fghij",
+			wantErr: ErrInvalidTwoFactorCode,
+		},
+		{
+			name: "challenge expired",
+			mock: func(ctrl *gomock.Controller) repository.TwoFactorRepository {
+				repo := repomocks.NewMockTwoFactorRepository(ctrl)
+				repo.EXPECT().GetChallenge(gomock.Any(), "challenge").
+					Return(int64(0), repository.ErrChallengeNotFound)
+				return repo
+			},
+			code:    code,
+			wantErr: ErrChallengeNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewTwoFactorService(tc.mock(ctrl)).(*TOTPTwoFactorService)
+			svc.now = func() time.Time { return now }
+			uid, err := svc.VerifyChallenge(context.Background(), "challenge", tc.code)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantUid, uid)
+		})
+	}
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"errors"
+	"time"
 
 	"gitee.com/geekbang/basic-go/webook/internal/domain"
 	"gitee.com/geekbang/basic-go/webook/internal/repository"
@@ -12,8 +13,18 @@ import (
 )
 
 var (
-	ErrDuplicateEmail        = repository.ErrDuplicateUser
+	ErrDuplicateEmail = repository.ErrDuplicateEmail
+	ErrDuplicatePhone = repository.ErrDuplicatePhone
+	// ErrDuplicateIdentity 第三方账号已经绑定了别的用户
+	ErrDuplicateIdentity = repository.ErrDuplicateIdentity
+	ErrDuplicateWechat   = repository.ErrDuplicateIdentity
+	// ErrLastLoginMethod 解绑之后就没法登录了
+	ErrLastLoginMethod       = errors.New("不能解绑最后一种登录方式")
 	ErrInvalidUserOrPassword = errors.New("用户不存在或者密码不对")
+	ErrUserNotFound          = repository.ErrUserNotFound
+	// ErrUserBanned 被管理员封禁了，不能登录
+	ErrUserBanned = errors.New("用户已被封禁")
+	ErrBanSelf    = errors.New("不能封禁自己")
 )
 
 type UserService interface {
@@ -24,8 +35,35 @@ type UserService interface {
 	FindById(ctx context.Context,
 		uid int64) (domain.User, error)
 	FindOrCreate(ctx context.Context, phone string) (domain.User, error)
-	FindOrCreateByWechat(ctx context.Context, info domain.WechatInfo) (domain.User, error)
+	// FindOrCreateByIdentity 第三方登录，只保证返回的用户有 Id
+	FindOrCreateByIdentity(ctx context.Context, identity domain.Identity) (domain.User, error)
 	GetUserIdFromSession(ctx *gin.Context) (int64, error)
+	FindByPhone(ctx context.Context, phone string) (domain.User, error)
+	FindByEmail(ctx context.Context, email string) (domain.User, error)
+	// ResetPassword 忘记密码的时候重置，调用之前要先校验发到 target 的验证码。返回用户 id
+	ResetPassword(ctx context.Context, channel domain.CodeChannel, target string, password string) (int64, error)
+	// ChangePassword 旧密码不对返回 ErrInvalidUserOrPassword
+	ChangePassword(ctx context.Context, uid int64, oldPassword string, password string) error
+	// ChangeEmail 调用之前要先校验发到新邮箱的验证码
+	ChangeEmail(ctx context.Context, uid int64, email string) error
+	// ConfirmEmail 调用之前要先校验发到这个邮箱的验证码，已经验证过的直接返回
+	ConfirmEmail(ctx context.Context, email string) error
+	// ChangePhone 调用之前要先校验发到新手机号的验证码
+	ChangePhone(ctx context.Context, uid int64, phone string) error
+	// UnbindEmail 解绑之后至少还要剩一种登录方式，否则返回 ErrLastLoginMethod
+	UnbindEmail(ctx context.Context, uid int64) error
+	UnbindPhone(ctx context.Context, uid int64) error
+	// BindWechat 微信已经绑定了别的账号返回 ErrDuplicateWechat
+	BindWechat(ctx context.Context, uid int64, info domain.WechatInfo) error
+	UnbindWechat(ctx context.Context, uid int64) error
+	// BindIdentity 第三方账号已经绑定了别的用户返回 ErrDuplicateIdentity
+	BindIdentity(ctx context.Context, uid int64, identity domain.Identity) error
+	UnbindIdentity(ctx context.Context, uid int64, provider string) error
+	// Search 管理后台用，返回这一页的用户和总数
+	Search(ctx context.Context, q domain.UserQuery) ([]domain.User, int64, error)
+	// Ban operator 是管理员。已经封禁的直接返回，封禁自己返回 ErrBanSelf
+	Ban(ctx context.Context, operator int64, uid int64) error
+	Unban(ctx context.Context, operator int64, uid int64) error
 }
 
 type RegularUserService struct {
@@ -86,28 +124,30 @@ func (svc *RegularUserService) FindOrCreate(ctx context.Context, phone string) (
 	err = svc.repo.Create(ctx, domain.User{
 		Phone: phone,
 	})
-	if err != nil && err != repository.ErrDuplicateUser {
+	if err != nil && err != repository.ErrDuplicatePhone {
 		return domain.User{}, err
 	}
 
 	return svc.repo.FindByPhone(ctx, phone)
 }
 
-func (svc *RegularUserService) FindOrCreateByWechat(ctx context.Context, info domain.WechatInfo) (domain.User, error) {
-
-	u, err := svc.repo.FindByWechat(ctx, info.OpenId)
+func (svc *RegularUserService) FindOrCreateByIdentity(ctx context.Context,
+	identity domain.Identity) (domain.User, error) {
+	u, err := svc.repo.FindByIdentity(ctx, identity.Provider, identity.Subject)
 	if err != repository.ErrUserNotFound {
 		return u, err
 	}
-	err = svc.repo.Create(ctx, domain.User{
-		WechatInfo: info,
-	})
-	if err != nil && err != repository.ErrDuplicateUser {
+	// 不按邮箱自动关联已有的用户，别的平台上的邮箱不一定可信，想关联就登录之后绑定
+	uid, err := svc.repo.CreateWithIdentity(ctx, domain.User{}, identity)
+	switch err {
+	case nil:
+		return domain.User{Id: uid}, nil
+	case repository.ErrDuplicateIdentity:
+		// 并发登录，别人先创建了
+		return svc.repo.FindByIdentity(ctx, identity.Provider, identity.Subject)
+	default:
 		return domain.User{}, err
 	}
-
-	return svc.repo.FindByWechat(ctx, info.OpenId)
-
 }
 
 func (svc *RegularUserService) GetUserIdFromSession(ctx *gin.Context) (int64, error) {
@@ -124,3 +164,271 @@ func (svc *RegularUserService) GetUserIdFromSession(ctx *gin.Context) (int64, er
 
 	return userIdInt64, nil
 }
+
+func (svc *RegularUserService) FindByPhone(ctx context.Context, phone string) (domain.User, error) {
+	return svc.repo.FindByPhone(ctx, phone)
+}
+
+func (svc *RegularUserService) FindByEmail(ctx context.Context, email string) (domain.User, error) {
+	return svc.repo.FindByEmail(ctx, email)
+}
+
+func (svc *RegularUserService) ResetPassword(ctx context.Context, channel domain.CodeChannel,
+	target string, password string) (int64, error) {
+	var (
+		u   domain.User
+		err error
+	)
+	if channel == domain.CodeChannelPhone {
+		u, err = svc.repo.FindByPhone(ctx, target)
+	} else {
+		u, err = svc.repo.FindByEmail(ctx, target)
+	}
+	if err != nil {
+		return 0, err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return 0, err
+	}
+	return u.Id, svc.repo.UpdatePassword(ctx, u.Id, string(hash), domain.UserAudit{
+		Action: domain.UserAuditResetPassword,
+	})
+}
+
+func (svc *RegularUserService) ChangePassword(ctx context.Context, uid int64,
+	oldPassword string, password string) error {
+	u, err := svc.repo.FindById(ctx, uid)
+	if err != nil {
+		return err
+	}
+	err = bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(oldPassword))
+	if err != nil {
+		return ErrInvalidUserOrPassword
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	return svc.repo.UpdatePassword(ctx, uid, string(hash), domain.UserAudit{
+		Action: domain.UserAuditChangePassword,
+	})
+}
+
+func (svc *RegularUserService) ChangeEmail(ctx context.Context, uid int64, email string) error {
+	u, err := svc.repo.FindById(ctx, uid)
+	if err != nil {
+		return err
+	}
+	if u.Email == email {
+		return nil
+	}
+	return svc.repo.UpdateEmail(ctx, uid, email, domain.UserAudit{
+		Action: domain.UserAuditChangeEmail,
+		Before: u.Email,
+		After:  email,
+	})
+}
+
+func (svc *RegularUserService) ConfirmEmail(ctx context.Context, email string) error {
+	u, err := svc.repo.FindByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	if u.EmailVerified {
+		return nil
+	}
+	return svc.repo.MarkEmailVerified(ctx, u.Id, domain.UserAudit{
+		Action: domain.UserAuditVerifyEmail,
+		After:  email,
+	})
+}
+
+func (svc *RegularUserService) ChangePhone(ctx context.Context, uid int64, phone string) error {
+	u, err := svc.repo.FindById(ctx, uid)
+	if err != nil {
+		return err
+	}
+	if u.Phone == phone {
+		return nil
+	}
+	return svc.repo.UpdatePhone(ctx, uid, phone, domain.UserAudit{
+		Action: domain.UserAuditChangePhone,
+		Before: u.Phone,
+		After:  phone,
+	})
+}
+
+func (svc *RegularUserService) UnbindEmail(ctx context.Context, uid int64) error {
+	u, err := svc.repo.FindById(ctx, uid)
+	if err != nil {
+		return err
+	}
+	if u.Email == "" {
+		return nil
+	}
+	before := u.Email
+	u.Email = ""
+	if loginMethods(u) == 0 {
+		return ErrLastLoginMethod
+	}
+	return svc.repo.UpdateEmail(ctx, uid, "", domain.UserAudit{
+		Action: domain.UserAuditUnbindEmail,
+		Before: before,
+	})
+}
+
+func (svc *RegularUserService) UnbindPhone(ctx context.Context, uid int64) error {
+	u, err := svc.repo.FindById(ctx, uid)
+	if err != nil {
+		return err
+	}
+	if u.Phone == "" {
+		return nil
+	}
+	before := u.Phone
+	u.Phone = ""
+	if loginMethods(u) == 0 {
+		return ErrLastLoginMethod
+	}
+	return svc.repo.UpdatePhone(ctx, uid, "", domain.UserAudit{
+		Action: domain.UserAuditUnbindPhone,
+		Before: before,
+	})
+}
+
+func (svc *RegularUserService) BindWechat(ctx context.Context, uid int64, info domain.WechatInfo) error {
+	return svc.bindIdentity(ctx, uid, wechatIdentity(info), domain.UserAuditBindWechat)
+}
+
+func (svc *RegularUserService) UnbindWechat(ctx context.Context, uid int64) error {
+	return svc.unbindIdentity(ctx, uid, domain.IdentityProviderWechat, domain.UserAuditUnbindWechat)
+}
+
+func (svc *RegularUserService) BindIdentity(ctx context.Context, uid int64, identity domain.Identity) error {
+	return svc.bindIdentity(ctx, uid, identity, domain.UserAuditBindIdentity)
+}
+
+func (svc *RegularUserService) UnbindIdentity(ctx context.Context, uid int64, provider string) error {
+	return svc.unbindIdentity(ctx, uid, provider, domain.UserAuditUnbindIdentity)
+}
+
+func (svc *RegularUserService) bindIdentity(ctx context.Context, uid int64,
+	identity domain.Identity, action domain.UserAuditAction) error {
+	u, err := svc.repo.FindById(ctx, uid)
+	if err != nil {
+		return err
+	}
+	before, ok := findIdentity(u, identity.Provider)
+	if ok && before.Subject == identity.Subject {
+		return nil
+	}
+	audit := domain.UserAudit{
+		Action: action,
+		After:  auditIdentity(identity),
+	}
+	if ok {
+		audit.Before = auditIdentity(before)
+	}
+	return svc.repo.BindIdentity(ctx, uid, identity, audit)
+}
+
+func (svc *RegularUserService) unbindIdentity(ctx context.Context, uid int64,
+	provider string, action domain.UserAuditAction) error {
+	u, err := svc.repo.FindById(ctx, uid)
+	if err != nil {
+		return err
+	}
+	before, ok := findIdentity(u, provider)
+	if !ok {
+		return nil
+	}
+	identities := make([]domain.Identity, 0, len(u.Identities))
+	for _, i := range u.Identities {
+		if i.Provider != provider {
+			identities = append(identities, i)
+		}
+	}
+	u.Identities = identities
+	if loginMethods(u) == 0 {
+		return ErrLastLoginMethod
+	}
+	return svc.repo.UnbindIdentity(ctx, uid, provider, domain.UserAudit{
+		Action: action,
+		Before: auditIdentity(before),
+	})
+}
+
+func wechatIdentity(info domain.WechatInfo) domain.Identity {
+	return domain.Identity{
+		Provider: domain.IdentityProviderWechat,
+		Subject:  info.OpenId,
+		UnionId:  info.UnionId,
+	}
+}
+
+func findIdentity(u domain.User, provider string) (domain.Identity, bool) {
+	for _, i := range u.Identities {
+		if i.Provider == provider {
+			return i, true
+		}
+	}
+	return domain.Identity{}, false
+}
+
+// auditIdentity 审计记录里面微信只记 openid，别的平台记 provider:subject
+func auditIdentity(i domain.Identity) string {
+	if i.Provider == domain.IdentityProviderWechat {
+		return i.Subject
+	}
+	return i.Provider + ":" + i.Subject
+}
+
+// loginMethods 还能用几种方式登录。邮箱要有密码才能登录，手机号和第三方账号绑了就能登录
+func loginMethods(u domain.User) int {
+	cnt := 0
+	if u.Email != "" && u.Password != "" {
+		cnt++
+	}
+	if u.Phone != "" {
+		cnt++
+	}
+	return cnt + len(u.Identities)
+}
+
+func (svc *RegularUserService) Search(ctx context.Context,
+	q domain.UserQuery) ([]domain.User, int64, error) {
+	return svc.repo.Search(ctx, q)
+}
+
+func (svc *RegularUserService) Ban(ctx context.Context, operator int64, uid int64) error {
+	if operator == uid {
+		return ErrBanSelf
+	}
+	u, err := svc.repo.FindById(ctx, uid)
+	if err != nil {
+		return err
+	}
+	if u.Banned() {
+		return nil
+	}
+	return svc.repo.UpdateBannedAt(ctx, uid, time.Now(), domain.UserAudit{
+		Action:   domain.UserAuditBan,
+		Operator: operator,
+	})
+}
+
+func (svc *RegularUserService) Unban(ctx context.Context, operator int64, uid int64) error {
+	u, err := svc.repo.FindById(ctx, uid)
+	if err != nil {
+		return err
+	}
+	if !u.Banned() {
+		return nil
+	}
+	return svc.repo.UpdateBannedAt(ctx, uid, time.Time{}, domain.UserAudit{
+		Action:   domain.UserAuditUnban,
+		Before:   u.BannedAt.Format(time.DateTime),
+		Operator: operator,
+	})
+}
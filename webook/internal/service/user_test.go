@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"gitee.com/geekbang/basic-go/webook/internal/domain"
 	"gitee.com/geekbang/basic-go/webook/internal/repository"
@@ -131,3 +132,420 @@ func TestRegularUserService_Login(t *testing.T) {
 		})
 	}
 }
+
+func TestRegularUserService_ChangePassword(t *testing.T) {
+	const hashed = "$2a$10$pzhe5saJTm7yQIU52dM5fu1ZzSjlUwI/RocB79zmqK1LytKx9IK8K"
+	testCases := []struct {
+		name        string
+		mock        func(ctrl *gomock.Controller) repository.UserRepository
+		oldPassword string
+		wantErr     error
+	}{
+		{
+			name: "password changed",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(123)).
+					Return(domain.User{Id: 123, Password: hashed}, nil)
+				repo.EXPECT().UpdatePassword(gomock.Any(), int64(123), gomock.Any(), domain.UserAudit{
+					Action: domain.UserAuditChangePassword,
+				}).DoAndReturn(func(ctx context.Context, uid int64, password string, audit domain.UserAudit) error {
+					// 存进去的必须是哈希过的新密码
+					return bcrypt.CompareHashAndPassword([]byte(password), []byte("new password"))
+				})
+				return repo
+			},
+			oldPassword: "12345678",
+		},
+		{
+			name: "wrong old password",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(123)).
+					Return(domain.User{Id: 123, Password: hashed}, nil)
+				return repo
+			},
+			oldPassword: "wrong password",
+			wantErr:     ErrInvalidUserOrPassword,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewUserService(tc.mock(ctrl))
+			err := svc.ChangePassword(context.Background(), 123, tc.oldPassword, "new password")
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestRegularUserService_ChangeEmail(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) repository.UserRepository
+		wantErr error
+	}{
+		{
+			name: "email changed",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(123)).
+					Return(domain.User{Id: 123, Email: "old@qq.com"}, nil)
+				repo.EXPECT().UpdateEmail(gomock.Any(), int64(123), "new@qq.com", domain.UserAudit{
+					Action: domain.UserAuditChangeEmail,
+					Before: "old@qq.com",
+					After:  "new@qq.com",
+				}).Return(nil)
+				return repo
+			},
+		},
+		{
+			name: "same email",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(123)).
+					Return(domain.User{Id: 123, Email: "new@qq.com"}, nil)
+				return repo
+			},
+		},
+		{
+			name: "email in use",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(123)).
+					Return(domain.User{Id: 123, Email: "old@qq.com"}, nil)
+				repo.EXPECT().UpdateEmail(gomock.Any(), int64(123), "new@qq.com", gomock.Any()).
+					Return(repository.ErrDuplicateEmail)
+				return repo
+			},
+			wantErr: ErrDuplicateEmail,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewUserService(tc.mock(ctrl))
+			err := svc.ChangeEmail(context.Background(), 123, "new@qq.com")
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestRegularUserService_ConfirmEmail(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) repository.UserRepository
+		wantErr error
+	}{
+		{
+			name: "email verified",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindByEmail(gomock.Any(), "123@qq.com").
+					Return(domain.User{Id: 123, Email: "123@qq.com"}, nil)
+				repo.EXPECT().MarkEmailVerified(gomock.Any(), int64(123), domain.UserAudit{
+					Action: domain.UserAuditVerifyEmail,
+					After:  "123@qq.com",
+				}).Return(nil)
+				return repo
+			},
+		},
+		{
+			name: "already verified",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindByEmail(gomock.Any(), "123@qq.com").
+					Return(domain.User{Id: 123, Email: "123@qq.com", EmailVerified: true}, nil)
+				return repo
+			},
+		},
+		{
+			name: "user not found",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindByEmail(gomock.Any(), "123@qq.com").
+					Return(domain.User{}, repository.ErrUserNotFound)
+				return repo
+			},
+			wantErr: ErrUserNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewUserService(tc.mock(ctrl))
+			err := svc.ConfirmEmail(context.Background(), "123@qq.com")
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestRegularUserService_UnbindPhone(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) repository.UserRepository
+		wantErr error
+	}{
+		{
+			name: "unbind with wechat left",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(123)).
+					Return(domain.User{Id: 123, Phone: "15212345678",
+						Identities: []domain.Identity{{Provider: "wechat", Subject: "open_id"}}}, nil)
+				repo.EXPECT().UpdatePhone(gomock.Any(), int64(123), "", domain.UserAudit{
+					Action: domain.UserAuditUnbindPhone,
+					Before: "15212345678",
+				}).Return(nil)
+				return repo
+			},
+		},
+		{
+			name: "email without password",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(123)).
+					Return(domain.User{Id: 123, Phone: "15212345678", Email: "123@qq.com"}, nil)
+				return repo
+			},
+			wantErr: ErrLastLoginMethod,
+		},
+		{
+			name: "not bound",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(123)).
+					Return(domain.User{Id: 123, Email: "123@qq.com", Password: "hash"}, nil)
+				return repo
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewUserService(tc.mock(ctrl))
+			err := svc.UnbindPhone(context.Background(), 123)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestRegularUserService_BindWechat(t *testing.T) {
+	info := domain.WechatInfo{OpenId: "open_id", UnionId: "union_id"}
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) repository.UserRepository
+		wantErr error
+	}{
+		{
+			name: "bound",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(123)).
+					Return(domain.User{Id: 123, Phone: "15212345678"}, nil)
+				repo.EXPECT().BindIdentity(gomock.Any(), int64(123), domain.Identity{
+					Provider: "wechat",
+					Subject:  "open_id",
+					UnionId:  "union_id",
+				}, domain.UserAudit{
+					Action: domain.UserAuditBindWechat,
+					After:  "open_id",
+				}).Return(nil)
+				return repo
+			},
+		},
+		{
+			name: "bound to another account",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(123)).
+					Return(domain.User{Id: 123, Phone: "15212345678"}, nil)
+				repo.EXPECT().BindIdentity(gomock.Any(), int64(123), gomock.Any(), gomock.Any()).
+					Return(repository.ErrDuplicateIdentity)
+				return repo
+			},
+			wantErr: ErrDuplicateWechat,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewUserService(tc.mock(ctrl))
+			err := svc.BindWechat(context.Background(), 123, info)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestRegularUserService_FindOrCreateByIdentity(t *testing.T) {
+	identity := domain.Identity{Provider: "github", Subject: "1234"}
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) repository.UserRepository
+		wantUser domain.User
+		wantErr  error
+	}{
+		{
+			name: "existing user",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindByIdentity(gomock.Any(), "github", "1234").
+					Return(domain.User{Id: 123}, nil)
+				return repo
+			},
+			wantUser: domain.User{Id: 123},
+		},
+		{
+			name: "new user",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindByIdentity(gomock.Any(), "github", "1234").
+					Return(domain.User{}, repository.ErrUserNotFound)
+				repo.EXPECT().CreateWithIdentity(gomock.Any(), domain.User{}, identity).
+					Return(int64(456), nil)
+				return repo
+			},
+			wantUser: domain.User{Id: 456},
+		},
+		{
+			name: "created concurrently",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindByIdentity(gomock.Any(), "github", "1234").
+					Return(domain.User{}, repository.ErrUserNotFound)
+				repo.EXPECT().CreateWithIdentity(gomock.Any(), domain.User{}, identity).
+					Return(int64(0), repository.ErrDuplicateIdentity)
+				repo.EXPECT().FindByIdentity(gomock.Any(), "github", "1234").
+					Return(domain.User{Id: 789}, nil)
+				return repo
+			},
+			wantUser: domain.User{Id: 789},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewUserService(tc.mock(ctrl))
+			u, err := svc.FindOrCreateByIdentity(context.Background(), identity)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantUser, u)
+		})
+	}
+}
+
+func TestRegularUserService_UnbindIdentity(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) repository.UserRepository
+		wantErr error
+	}{
+		{
+			name: "another identity left",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(123)).
+					Return(domain.User{Id: 123, Identities: []domain.Identity{
+						{Provider: "github", Subject: "1234"},
+						{Provider: "google", Subject: "5678"},
+					}}, nil)
+				repo.EXPECT().UnbindIdentity(gomock.Any(), int64(123), "github", domain.UserAudit{
+					Action: domain.UserAuditUnbindIdentity,
+					Before: "github:1234",
+				}).Return(nil)
+				return repo
+			},
+		},
+		{
+			name: "last login method",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(123)).
+					Return(domain.User{Id: 123, Identities: []domain.Identity{
+						{Provider: "github", Subject: "1234"},
+					}}, nil)
+				return repo
+			},
+			wantErr: ErrLastLoginMethod,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewUserService(tc.mock(ctrl))
+			err := svc.UnbindIdentity(context.Background(), 123, "github")
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestRegularUserService_Ban(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) repository.UserRepository
+		operator int64
+		wantErr  error
+	}{
+		{
+			name: "banned",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(123)).
+					Return(domain.User{Id: 123}, nil)
+				repo.EXPECT().UpdateBannedAt(gomock.Any(), int64(123), gomock.Any(), domain.UserAudit{
+					Action:   domain.UserAuditBan,
+					Operator: 1,
+				}).DoAndReturn(func(ctx context.Context, uid int64, bannedAt time.Time, audit domain.UserAudit) error {
+					assert.False(t, bannedAt.IsZero())
+					return nil
+				})
+				return repo
+			},
+			operator: 1,
+		},
+		{
+			name: "already banned",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(123)).
+					Return(domain.User{Id: 123, BannedAt: time.UnixMilli(100)}, nil)
+				return repo
+			},
+			operator: 1,
+		},
+		{
+			name: "ban self",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				return repomocks.NewMockUserRepository(ctrl)
+			},
+			operator: 123,
+			wantErr:  ErrBanSelf,
+		},
+		{
+			name: "no user",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(123)).
+					Return(domain.User{}, repository.ErrUserNotFound)
+				return repo
+			},
+			operator: 1,
+			wantErr:  ErrUserNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewUserService(tc.mock(ctrl))
+			err := svc.Ban(context.Background(), tc.operator, 123)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
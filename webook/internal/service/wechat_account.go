@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository"
+	"gitee.com/geekbang/basic-go/webook/internal/service/oauth2/wechat"
+	"go.uber.org/zap"
+)
+
+// ErrWechatTokenExpired refresh token 也过期了，只能让用户重新扫码授权
+var ErrWechatTokenExpired = errors.New("微信授权已经过期")
+
+// WechatAccountService 微信登录和绑定，顺便保存微信的 token，后面要用微信接口的时候用
+type WechatAccountService interface {
+	// Login 第一次登录的时候创建用户，用微信上的昵称和头像初始化资料
+	Login(ctx context.Context, info domain.WechatInfo, token domain.WechatToken) (domain.User, error)
+	// Bind 微信已经绑定了别的账号返回 ErrDuplicateWechat
+	Bind(ctx context.Context, uid int64, info domain.WechatInfo, token domain.WechatToken) error
+	Unbind(ctx context.Context, uid int64) error
+	// AccessToken 快过期的时候先刷新，没有授权过返回 repository.ErrWechatTokenNotFound
+	AccessToken(ctx context.Context, uid int64) (string, error)
+	// RefreshExpiring 定时任务调用，提前刷新快过期的 access token
+	RefreshExpiring(ctx context.Context) error
+}
+
+type OAuth2WechatAccountService struct {
+	svc       wechat.Service
+	userSvc   UserService
+	userRepo  repository.UserRepository
+	tokenRepo repository.WechatTokenRepository
+	// ahead 提前多久刷新，要比定时任务的间隔长
+	ahead     time.Duration
+	batchSize int
+	l         *zap.Logger
+}
+
+func NewWechatAccountService(svc wechat.Service, userSvc UserService,
+	userRepo repository.UserRepository, tokenRepo repository.WechatTokenRepository,
+	l *zap.Logger) WechatAccountService {
+	return &OAuth2WechatAccountService{
+		svc:       svc,
+		userSvc:   userSvc,
+		userRepo:  userRepo,
+		tokenRepo: tokenRepo,
+		ahead:     time.Minute * 15,
+		batchSize: 100,
+		l:         l,
+	}
+}
+
+func (a *OAuth2WechatAccountService) Login(ctx context.Context,
+	info domain.WechatInfo, token domain.WechatToken) (domain.User, error) {
+	u, err := a.userRepo.FindByIdentity(ctx, domain.IdentityProviderWechat, info.OpenId)
+	if err == repository.ErrUserNotFound {
+		u, err = a.create(ctx, info, token)
+	}
+	if err != nil {
+		return domain.User{}, err
+	}
+	a.saveToken(ctx, u.Id, token)
+	return u, nil
+}
+
+func (a *OAuth2WechatAccountService) create(ctx context.Context,
+	info domain.WechatInfo, token domain.WechatToken) (domain.User, error) {
+	// 拿不到资料也照样创建用户，用户自己可以改
+	profile, err := a.svc.UserInfo(ctx, token.AccessToken, info.OpenId)
+	if err != nil {
+		a.l.Warn("获取微信用户信息失败", zap.Error(err))
+	}
+	uid, err := a.userRepo.CreateWithIdentity(ctx, domain.User{
+		Nickname: profile.Nickname,
+		Avatar:   profile.Avatar,
+	}, wechatIdentity(info))
+	switch err {
+	case nil:
+		return domain.User{Id: uid}, nil
+	case repository.ErrDuplicateIdentity:
+		// 并发登录，别人先创建了
+		return a.userRepo.FindByIdentity(ctx, domain.IdentityProviderWechat, info.OpenId)
+	default:
+		return domain.User{}, err
+	}
+}
+
+func (a *OAuth2WechatAccountService) Bind(ctx context.Context, uid int64,
+	info domain.WechatInfo, token domain.WechatToken) error {
+	err := a.userSvc.BindWechat(ctx, uid, info)
+	if err != nil {
+		return err
+	}
+	a.saveToken(ctx, uid, token)
+	return nil
+}
+
+func (a *OAuth2WechatAccountService) Unbind(ctx context.Context, uid int64) error {
+	err := a.userSvc.UnbindWechat(ctx, uid)
+	if err != nil {
+		return err
+	}
+	return a.tokenRepo.Delete(ctx, uid)
+}
+
+// saveToken 存不下来不影响登录，最多后面要用的时候让用户重新授权
+func (a *OAuth2WechatAccountService) saveToken(ctx context.Context, uid int64, token domain.WechatToken) {
+	token.Uid = uid
+	if err := a.tokenRepo.Save(ctx, token); err != nil {
+		a.l.Error("保存微信 token 失败", zap.Int64("uid", uid), zap.Error(err))
+	}
+}
+
+func (a *OAuth2WechatAccountService) AccessToken(ctx context.Context, uid int64) (string, error) {
+	t, err := a.tokenRepo.FindByUid(ctx, uid)
+	if err != nil {
+		return "", err
+	}
+	if time.Now().Add(time.Minute).Before(t.AccessExpireAt) {
+		return t.AccessToken, nil
+	}
+	t, err = a.refresh(ctx, t)
+	if err != nil {
+		return "", err
+	}
+	return t.AccessToken, nil
+}
+
+func (a *OAuth2WechatAccountService) RefreshExpiring(ctx context.Context) error {
+	before := time.Now().Add(a.ahead)
+	var afterUid int64
+	for {
+		ts, err := a.tokenRepo.FindExpiring(ctx, before, afterUid, a.batchSize)
+		if err != nil {
+			return err
+		}
+		for _, t := range ts {
+			// 一个失败了不影响别的
+			if _, err = a.refresh(ctx, t); err != nil {
+				a.l.Warn("刷新微信 token 失败", zap.Int64("uid", t.Uid), zap.Error(err))
+			}
+		}
+		if len(ts) < a.batchSize {
+			return nil
+		}
+		afterUid = ts[len(ts)-1].Uid
+	}
+}
+
+func (a *OAuth2WechatAccountService) refresh(ctx context.Context, t domain.WechatToken) (domain.WechatToken, error) {
+	if !time.Now().Before(t.RefreshExpireAt) {
+		return domain.WechatToken{}, ErrWechatTokenExpired
+	}
+	nt, err := a.svc.RefreshToken(ctx, t.RefreshToken)
+	if err != nil {
+		return domain.WechatToken{}, err
+	}
+	nt.Uid = t.Uid
+	nt.OpenId = t.OpenId
+	nt.RefreshExpireAt = t.RefreshExpireAt
+	if nt.RefreshToken == "" {
+		nt.RefreshToken = t.RefreshToken
+	}
+	return nt, a.tokenRepo.Save(ctx, nt)
+}
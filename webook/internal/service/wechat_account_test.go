@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository"
+	repomocks "gitee.com/geekbang/basic-go/webook/internal/repository/mocks"
+	"gitee.com/geekbang/basic-go/webook/internal/service/oauth2/wechat"
+	wechatmocks "gitee.com/geekbang/basic-go/webook/internal/service/oauth2/wechat/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+func TestOAuth2WechatAccountService_Login(t *testing.T) {
+	info := domain.WechatInfo{OpenId: "open-1", UnionId: "union-1"}
+	token := domain.WechatToken{OpenId: "open-1", AccessToken: "access-1", RefreshToken: "refresh-1"}
+	identity := domain.Identity{Provider: "wechat", Subject: "open-1", UnionId: "union-1"}
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (wechat.Service,
+			repository.UserRepository, repository.WechatTokenRepository)
+		wantUser domain.User
+		wantErr  error
+	}{
+		{
+			name: "first login",
+			mock: func(ctrl *gomock.Controller) (wechat.Service,
+				repository.UserRepository, repository.WechatTokenRepository) {
+				svc := wechatmocks.NewMockService(ctrl)
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				tokenRepo := repomocks.NewMockWechatTokenRepository(ctrl)
+				userRepo.EXPECT().FindByIdentity(gomock.Any(), "wechat", "open-1").
+					Return(domain.User{}, repository.ErrUserNotFound)
+				svc.EXPECT().UserInfo(gomock.Any(), "access-1", "open-1").
+					Return(domain.WechatProfile{Nickname: "小明", Avatar: "https://a.com/1.png"}, nil)
+				userRepo.EXPECT().CreateWithIdentity(gomock.Any(), domain.User{
+					Nickname: "小明",
+					Avatar:   "https://a.com/1.png",
+				}, identity).Return(int64(123), nil)
+				tokenRepo.EXPECT().Save(gomock.Any(), domain.WechatToken{
+					Uid:          123,
+					OpenId:       "open-1",
+					AccessToken:  "access-1",
+					RefreshToken: "refresh-1",
+				}).Return(nil)
+				return svc, userRepo, tokenRepo
+			},
+			wantUser: domain.User{Id: 123},
+		},
+		{
+			name: "first login without profile",
+			mock: func(ctrl *gomock.Controller) (wechat.Service,
+				repository.UserRepository, repository.WechatTokenRepository) {
+				svc := wechatmocks.NewMockService(ctrl)
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				tokenRepo := repomocks.NewMockWechatTokenRepository(ctrl)
+				userRepo.EXPECT().FindByIdentity(gomock.Any(), "wechat", "open-1").
+					Return(domain.User{}, repository.ErrUserNotFound)
+				svc.EXPECT().UserInfo(gomock.Any(), "access-1", "open-1").
+					Return(domain.WechatProfile{}, errors.New("wechat error"))
+				userRepo.EXPECT().CreateWithIdentity(gomock.Any(), domain.User{}, identity).
+					Return(int64(123), nil)
+				tokenRepo.EXPECT().Save(gomock.Any(), gomock.Any()).Return(nil)
+				return svc, userRepo, tokenRepo
+			},
+			wantUser: domain.User{Id: 123},
+		},
+		{
+			name: "existing user, save token failed",
+			mock: func(ctrl *gomock.Controller) (wechat.Service,
+				repository.UserRepository, repository.WechatTokenRepository) {
+				svc := wechatmocks.NewMockService(ctrl)
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				tokenRepo := repomocks.NewMockWechatTokenRepository(ctrl)
+				userRepo.EXPECT().FindByIdentity(gomock.Any(), "wechat", "open-1").
+					Return(domain.User{Id: 123}, nil)
+				tokenRepo.EXPECT().Save(gomock.Any(), gomock.Any()).Return(errors.New("db error"))
+				return svc, userRepo, tokenRepo
+			},
+			wantUser: domain.User{Id: 123},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, userRepo, tokenRepo := tc.mock(ctrl)
+			a := NewWechatAccountService(svc, nil, userRepo, tokenRepo, zap.NewNop())
+			u, err := a.Login(context.Background(), info, token)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantUser, u)
+		})
+	}
+}
+
+func TestOAuth2WechatAccountService_AccessToken(t *testing.T) {
+	now := time.Now()
+	testCases := []struct {
+		name      string
+		mock      func(ctrl *gomock.Controller) (wechat.Service, repository.WechatTokenRepository)
+		wantToken string
+		wantErr   error
+	}{
+		{
+			name: "not expired",
+			mock: func(ctrl *gomock.Controller) (wechat.Service, repository.WechatTokenRepository) {
+				svc := wechatmocks.NewMockService(ctrl)
+				tokenRepo := repomocks.NewMockWechatTokenRepository(ctrl)
+				tokenRepo.EXPECT().FindByUid(gomock.Any(), int64(123)).Return(domain.WechatToken{
+					Uid:             123,
+					AccessToken:     "access-1",
+					AccessExpireAt:  now.Add(time.Hour),
+					RefreshExpireAt: now.Add(time.Hour * 24),
+				}, nil)
+				return svc, tokenRepo
+			},
+			wantToken: "access-1",
+		},
+		{
+			name: "refresh",
+			mock: func(ctrl *gomock.Controller) (wechat.Service, repository.WechatTokenRepository) {
+				svc := wechatmocks.NewMockService(ctrl)
+				tokenRepo := repomocks.NewMockWechatTokenRepository(ctrl)
+				old := domain.WechatToken{
+					Uid:             123,
+					OpenId:          "open-1",
+					AccessToken:     "access-1",
+					RefreshToken:    "refresh-1",
+					AccessExpireAt:  now.Add(time.Second),
+					RefreshExpireAt: now.Add(time.Hour * 24),
+				}
+				tokenRepo.EXPECT().FindByUid(gomock.Any(), int64(123)).Return(old, nil)
+				svc.EXPECT().RefreshToken(gomock.Any(), "refresh-1").Return(domain.WechatToken{
+					OpenId:         "open-1",
+					AccessToken:    "access-2",
+					RefreshToken:   "refresh-1",
+					AccessExpireAt: now.Add(time.Hour * 2),
+				}, nil)
+				tokenRepo.EXPECT().Save(gomock.Any(), domain.WechatToken{
+					Uid:             123,
+					OpenId:          "open-1",
+					AccessToken:     "access-2",
+					RefreshToken:    "refresh-1",
+					AccessExpireAt:  now.Add(time.Hour * 2),
+					RefreshExpireAt: now.Add(time.Hour * 24),
+				}).Return(nil)
+				return svc, tokenRepo
+			},
+			wantToken: "access-2",
+		},
+		{
+			name: "refresh token expired",
+			mock: func(ctrl *gomock.Controller) (wechat.Service, repository.WechatTokenRepository) {
+				svc := wechatmocks.NewMockService(ctrl)
+				tokenRepo := repomocks.NewMockWechatTokenRepository(ctrl)
+				tokenRepo.EXPECT().FindByUid(gomock.Any(), int64(123)).Return(domain.WechatToken{
+					Uid:             123,
+					AccessExpireAt:  now.Add(-time.Hour),
+					RefreshExpireAt: now.Add(-time.Minute),
+				}, nil)
+				return svc, tokenRepo
+			},
+			wantErr: ErrWechatTokenExpired,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, tokenRepo := tc.mock(ctrl)
+			a := NewWechatAccountService(svc, nil, nil, tokenRepo, zap.NewNop())
+			token, err := a.AccessToken(context.Background(), 123)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantToken, token)
+		})
+	}
+}
+
+func TestOAuth2WechatAccountService_RefreshExpiring(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	svc := wechatmocks.NewMockService(ctrl)
+	tokenRepo := repomocks.NewMockWechatTokenRepository(ctrl)
+	expire := time.Now().Add(time.Hour)
+	batch := func(uids ...int64) []domain.WechatToken {
+		res := make([]domain.WechatToken, 0, len(uids))
+		for _, uid := range uids {
+			res = append(res, domain.WechatToken{Uid: uid, RefreshToken: "refresh", RefreshExpireAt: expire})
+		}
+		return res
+	}
+	// 第一批满了还要接着查，第二批不满就结束
+	tokenRepo.EXPECT().FindExpiring(gomock.Any(), gomock.Any(), int64(0), 2).Return(batch(1, 2), nil)
+	tokenRepo.EXPECT().FindExpiring(gomock.Any(), gomock.Any(), int64(2), 2).Return(batch(3), nil)
+	// uid 2 刷新失败不影响 3
+	svc.EXPECT().RefreshToken(gomock.Any(), "refresh").Return(domain.WechatToken{AccessToken: "new"}, nil)
+	svc.EXPECT().RefreshToken(gomock.Any(), "refresh").Return(domain.WechatToken{}, errors.New("wechat error"))
+	svc.EXPECT().RefreshToken(gomock.Any(), "refresh").Return(domain.WechatToken{AccessToken: "new"}, nil)
+	tokenRepo.EXPECT().Save(gomock.Any(), gomock.Any()).Times(2).Return(nil)
+
+	a := NewWechatAccountService(svc, nil, nil, tokenRepo, zap.NewNop()).(*OAuth2WechatAccountService)
+	a.batchSize = 2
+	err := a.RefreshExpiring(context.Background())
+	assert.NoError(t, err)
+}
@@ -0,0 +1,290 @@
+package web
+
+import (
+	"net/http"
+	"time"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/service"
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// AdminHandler 管理后台。这里不检查权限，权限由 PermissionMiddlewareBuilder 按路径检查
+type AdminHandler struct {
+	userSvc     service.UserService
+	rbacSvc     service.RBACService
+	asyncSmsSvc service.AsyncSmsService
+	l           *zap.Logger
+}
+
+func NewAdminHandler(userSvc service.UserService, rbacSvc service.RBACService,
+	asyncSmsSvc service.AsyncSmsService, l *zap.Logger) *AdminHandler {
+	return &AdminHandler{
+		userSvc:     userSvc,
+		rbacSvc:     rbacSvc,
+		asyncSmsSvc: asyncSmsSvc,
+		l:           l,
+	}
+}
+
+func (h *AdminHandler) RegisterRoutes(server *gin.Engine) {
+	ug := server.Group("/admin/users")
+	ug.POST("/search", h.SearchUsers)
+	ug.POST("/ban", h.Ban)
+	ug.POST("/unban", h.Unban)
+	ug.POST("/grant_role", h.GrantRole)
+	ug.POST("/revoke_role", h.RevokeRole)
+
+	sg := server.Group("/admin/sms/async")
+	sg.GET("/stats", h.AsyncSmsStats)
+	sg.POST("/list", h.ListAsyncSms)
+}
+
+type AdminUserVO struct {
+	Id       int64  `json:"id"`
+	Email    string `json:"email"`
+	Phone    string `json:"phone"`
+	Nickname string `json:"nickname"`
+	Banned   bool   `json:"banned"`
+	BannedAt string `json:"bannedAt"`
+	Ctime    string `json:"ctime"`
+}
+
+func toAdminUserVO(u domain.User) AdminUserVO {
+	res := AdminUserVO{
+		Id:       u.Id,
+		Email:    u.Email,
+		Phone:    u.Phone,
+		Nickname: u.Nickname,
+		Banned:   u.Banned(),
+		Ctime:    u.Ctime.Format(time.DateTime),
+	}
+	if u.Banned() {
+		res.BannedAt = u.BannedAt.Format(time.DateTime)
+	}
+	return res
+}
+
+// SearchUsers keyword 可以是 id，或者邮箱、手机号、昵称的前缀
+func (h *AdminHandler) SearchUsers(ctx *gin.Context) {
+	type Req struct {
+		Keyword string `json:"keyword"`
+		Offset  int    `json:"offset"`
+		Limit   int    `json:"limit"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	if req.Offset < 0 || req.Limit <= 0 || req.Limit > 100 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Invalid offset or limit",
+		})
+		return
+	}
+	users, total, err := h.userSvc.Search(ctx, domain.UserQuery{
+		Keyword: req.Keyword,
+		Offset:  req.Offset,
+		Limit:   req.Limit,
+	})
+	if err != nil {
+		h.l.Error("search users failed", zap.Error(err), zap.String("keyword", req.Keyword))
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "System error",
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: map[string]any{
+			"total": total,
+			"users": slice.Map(users, func(idx int, src domain.User) AdminUserVO {
+				return toAdminUserVO(src)
+			}),
+		},
+	})
+}
+
+type adminUserReq struct {
+	Uid int64 `json:"uid"`
+}
+
+// Ban 封禁之后已经签发的 token 也会被登录校验拦住
+func (h *AdminHandler) Ban(ctx *gin.Context) {
+	var req adminUserReq
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	uc, ok := ctx.MustGet("user").(UserClaims)
+	if !ok {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	err := h.userSvc.Ban(ctx, uc.Uid, req.Uid)
+	h.writeUserResult(ctx, "ban user failed", uc.Uid, req.Uid, err)
+}
+
+func (h *AdminHandler) Unban(ctx *gin.Context) {
+	var req adminUserReq
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	uc, ok := ctx.MustGet("user").(UserClaims)
+	if !ok {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	err := h.userSvc.Unban(ctx, uc.Uid, req.Uid)
+	h.writeUserResult(ctx, "unban user failed", uc.Uid, req.Uid, err)
+}
+
+type adminRoleReq struct {
+	Uid  int64  `json:"uid"`
+	Role string `json:"role"`
+}
+
+// GrantRole 用户下一个请求就有新的权限，不用重新登录
+func (h *AdminHandler) GrantRole(ctx *gin.Context) {
+	var req adminRoleReq
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	uc, ok := ctx.MustGet("user").(UserClaims)
+	if !ok {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	err := h.rbacSvc.GrantRole(ctx, uc.Uid, req.Uid, domain.Role(req.Role))
+	h.writeUserResult(ctx, "grant role failed", uc.Uid, req.Uid, err)
+}
+
+func (h *AdminHandler) RevokeRole(ctx *gin.Context) {
+	var req adminRoleReq
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	uc, ok := ctx.MustGet("user").(UserClaims)
+	if !ok {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	err := h.rbacSvc.RevokeRole(ctx, uc.Uid, req.Uid, domain.Role(req.Role))
+	h.writeUserResult(ctx, "revoke role failed", uc.Uid, req.Uid, err)
+}
+
+func (h *AdminHandler) writeUserResult(ctx *gin.Context, logMsg string,
+	operator int64, uid int64, err error) {
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "OK",
+		})
+	case service.ErrUserNotFound:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "User not found",
+		})
+	case service.ErrBanSelf:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Cannot ban yourself",
+		})
+	case service.ErrUnknownRole:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Unknown role",
+		})
+	case service.ErrUserBanned:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "User banned",
+		})
+	default:
+		h.l.Error(logMsg, zap.Error(err),
+			zap.Int64("operator", operator),
+			zap.Int64("uid", uid))
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "System error",
+		})
+	}
+}
+
+// AsyncSmsStats 各个状态的异步短信有多少条
+func (h *AdminHandler) AsyncSmsStats(ctx *gin.Context) {
+	stats, err := h.asyncSmsSvc.Stats(ctx)
+	if err != nil {
+		h.l.Error("async sms stats failed", zap.Error(err))
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "System error",
+		})
+		return
+	}
+	res := make(map[string]int64, len(stats))
+	for status, cnt := range stats {
+		res[status.String()] = cnt
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: res,
+	})
+}
+
+type AsyncSmsVO struct {
+	Id       int64    `json:"id"`
+	TplId    string   `json:"tplId"`
+	Numbers  []string `json:"numbers"`
+	Status   string   `json:"status"`
+	RetryCnt int      `json:"retryCnt"`
+	RetryMax int      `json:"retryMax"`
+	Ctime    string   `json:"ctime"`
+	Utime    string   `json:"utime"`
+}
+
+// ListAsyncSms 按状态查，一般是看发送失败的。短信参数里面可能有验证码，不返回
+func (h *AdminHandler) ListAsyncSms(ctx *gin.Context) {
+	type Req struct {
+		Status uint8 `json:"status"`
+		Offset int   `json:"offset"`
+		Limit  int   `json:"limit"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	status := domain.AsyncSmsStatus(req.Status)
+	if !status.Valid() || req.Offset < 0 || req.Limit <= 0 || req.Limit > 100 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Invalid status, offset or limit",
+		})
+		return
+	}
+	list, err := h.asyncSmsSvc.List(ctx, status, req.Offset, req.Limit)
+	if err != nil {
+		h.l.Error("list async sms failed", zap.Error(err),
+			zap.Uint8("status", req.Status))
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "System error",
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: slice.Map(list, func(idx int, src domain.AsyncSms) AsyncSmsVO {
+			return AsyncSmsVO{
+				Id:       src.Id,
+				TplId:    src.TplId,
+				Numbers:  src.Numbers,
+				Status:   src.Status.String(),
+				RetryCnt: src.RetryCnt,
+				RetryMax: src.RetryMax,
+				Ctime:    src.Ctime.Format(time.DateTime),
+				Utime:    src.Utime.Format(time.DateTime),
+			}
+		}),
+	})
+}
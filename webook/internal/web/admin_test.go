@@ -0,0 +1,106 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gitee.com/geekbang/basic-go/webook/internal/service"
+	svcmocks "gitee.com/geekbang/basic-go/webook/internal/service/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+func TestAdminHandler_Ban(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) service.UserService
+		reqBody string
+		wantRes Result
+	}{
+		{
+			name: "banned",
+			mock: func(ctrl *gomock.Controller) service.UserService {
+				svc := svcmocks.NewMockUserService(ctrl)
+				svc.EXPECT().Ban(gomock.Any(), int64(1), int64(123)).Return(nil)
+				return svc
+			},
+			reqBody: `{"uid":123}`,
+			wantRes: Result{
+				Msg: "OK",
+			},
+		},
+		{
+			name: "ban self",
+			mock: func(ctrl *gomock.Controller) service.UserService {
+				svc := svcmocks.NewMockUserService(ctrl)
+				svc.EXPECT().Ban(gomock.Any(), int64(1), int64(1)).Return(service.ErrBanSelf)
+				return svc
+			},
+			reqBody: `{"uid":1}`,
+			wantRes: Result{
+				Code: 4,
+				Msg:  "Cannot ban yourself",
+			},
+		},
+		{
+			name: "no user",
+			mock: func(ctrl *gomock.Controller) service.UserService {
+				svc := svcmocks.NewMockUserService(ctrl)
+				svc.EXPECT().Ban(gomock.Any(), int64(1), int64(456)).Return(service.ErrUserNotFound)
+				return svc
+			},
+			reqBody: `{"uid":456}`,
+			wantRes: Result{
+				Code: 4,
+				Msg:  "User not found",
+			},
+		},
+		{
+			name: "system error",
+			mock: func(ctrl *gomock.Controller) service.UserService {
+				svc := svcmocks.NewMockUserService(ctrl)
+				svc.EXPECT().Ban(gomock.Any(), int64(1), int64(123)).Return(errors.New("db error"))
+				return svc
+			},
+			reqBody: `{"uid":123}`,
+			wantRes: Result{
+				Code: 5,
+				Msg:  "System error",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			hdl := NewAdminHandler(tc.mock(ctrl), svcmocks.NewMockRBACService(ctrl),
+				svcmocks.NewMockAsyncSmsService(ctrl), zap.NewNop())
+
+			server := gin.Default()
+			server.Use(func(ctx *gin.Context) {
+				ctx.Set("user", UserClaims{Uid: 1})
+			})
+			hdl.RegisterRoutes(server)
+
+			req, err := http.NewRequest(http.MethodPost,
+				"/admin/users/ban", bytes.NewReader([]byte(tc.reqBody)))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			var res Result
+			err = json.NewDecoder(recorder.Body).Decode(&res)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantRes, res)
+		})
+	}
+}
@@ -0,0 +1,395 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/service"
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type ArticleHandler struct {
+	svc        service.ArticleService
+	intrSvc    service.InteractiveService
+	rankingSvc service.RankingService
+	l          *zap.Logger
+	biz        string
+}
+
+func NewArticleHandler(svc service.ArticleService,
+	intrSvc service.InteractiveService,
+	rankingSvc service.RankingService, l *zap.Logger) *ArticleHandler {
+	return &ArticleHandler{
+		svc:        svc,
+		intrSvc:    intrSvc,
+		rankingSvc: rankingSvc,
+		l:          l,
+		biz:        "article",
+	}
+}
+
+func (h *ArticleHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/articles")
+	g.POST("/edit", h.Edit)
+	g.POST("/publish", h.Publish)
+	g.POST("/withdraw", h.Withdraw)
+	g.POST("/list", h.List)
+	g.GET("/detail/:id", h.Detail)
+	g.GET("/hot", h.HotList)
+
+	pub := g.Group("/pub")
+	pub.GET("/:id", h.PubDetail)
+	pub.POST("/like", h.Like)
+	pub.POST("/collect", h.Collect)
+}
+
+type ArticleReq struct {
+	Id      int64  `json:"id"`
+	Title   string `json:"title"`
+	Content string `json:"content"`
+}
+
+func (req ArticleReq) toDomain(uid int64) domain.Article {
+	return domain.Article{
+		Id:      req.Id,
+		Title:   req.Title,
+		Content: req.Content,
+		Author: domain.Author{
+			Id: uid,
+		},
+	}
+}
+
+func (h *ArticleHandler) Edit(ctx *gin.Context) {
+	var req ArticleReq
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	uc, ok := ctx.MustGet("user").(UserClaims)
+	if !ok {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	id, err := h.svc.Save(ctx, req.toDomain(uc.Uid))
+	h.writeSaveResult(ctx, "save article failed", uc.Uid, id, err)
+}
+
+func (h *ArticleHandler) Publish(ctx *gin.Context) {
+	var req ArticleReq
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	uc, ok := ctx.MustGet("user").(UserClaims)
+	if !ok {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	id, err := h.svc.Publish(ctx, req.toDomain(uc.Uid))
+	h.writeSaveResult(ctx, "publish article failed", uc.Uid, id, err)
+}
+
+func (h *ArticleHandler) writeSaveResult(ctx *gin.Context, logMsg string, uid int64, id int64, err error) {
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Data: id,
+		})
+	case service.ErrPossibleIncorrectAuthor:
+		// 有人在修改别人的文章，记录下来
+		h.l.Warn("author mismatch",
+			zap.Int64("uid", uid), zap.Int64("aid", id))
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Article not found",
+		})
+	default:
+		h.l.Error(logMsg, zap.Error(err),
+			zap.Int64("uid", uid), zap.Int64("aid", id))
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "System error",
+		})
+	}
+}
+
+func (h *ArticleHandler) Withdraw(ctx *gin.Context) {
+	type Req struct {
+		Id int64 `json:"id"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	uc, ok := ctx.MustGet("user").(UserClaims)
+	if !ok {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	err := h.svc.Withdraw(ctx, uc.Uid, req.Id)
+	h.writeSaveResult(ctx, "withdraw article failed", uc.Uid, req.Id, err)
+}
+
+type ListReq struct {
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+}
+
+// List 作者自己的文章列表，按照更新时间倒序
+func (h *ArticleHandler) List(ctx *gin.Context) {
+	var req ListReq
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	if req.Offset < 0 || req.Limit <= 0 || req.Limit > 100 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Invalid offset or limit",
+		})
+		return
+	}
+	uc, ok := ctx.MustGet("user").(UserClaims)
+	if !ok {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	arts, err := h.svc.List(ctx, uc.Uid, req.Offset, req.Limit)
+	if err != nil {
+		h.l.Error("list articles failed", zap.Error(err),
+			zap.Int64("uid", uc.Uid),
+			zap.Int("offset", req.Offset),
+			zap.Int("limit", req.Limit))
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "System error",
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: slice.Map(arts, func(idx int, src domain.Article) ArticleVO {
+			return toArticleListVO(src)
+		}),
+	})
+}
+
+// Detail 作者查看自己的草稿
+func (h *ArticleHandler) Detail(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Invalid article id",
+		})
+		return
+	}
+	uc, ok := ctx.MustGet("user").(UserClaims)
+	if !ok {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	art, err := h.svc.GetById(ctx, id)
+	switch err {
+	case nil:
+	case service.ErrArticleNotFound:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Article not found",
+		})
+		return
+	default:
+		h.l.Error("get article failed", zap.Error(err),
+			zap.Int64("uid", uc.Uid), zap.Int64("aid", id))
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "System error",
+		})
+		return
+	}
+	if art.Author.Id != uc.Uid {
+		// 有人在看别人的草稿，不告诉他文章存在
+		h.l.Warn("author mismatch",
+			zap.Int64("uid", uc.Uid), zap.Int64("aid", id))
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Article not found",
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: toArticleVO(art),
+	})
+}
+
+// PubDetail 读者看到的文章，只从线上库读取
+func (h *ArticleHandler) PubDetail(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Invalid article id",
+		})
+		return
+	}
+	uc, ok := ctx.MustGet("user").(UserClaims)
+	if !ok {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	art, err := h.svc.GetPublishedById(ctx, id)
+	switch err {
+	case nil:
+	case service.ErrArticleNotFound:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Article not found",
+		})
+		return
+	default:
+		h.l.Error("get published article failed",
+			zap.Error(err), zap.Int64("aid", id))
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "System error",
+		})
+		return
+	}
+
+	// 阅读计数只是记在内存里面，由 ReadCntBuffer 批量写到数据库
+	err = h.intrSvc.IncrReadCnt(ctx, h.biz, art.Id)
+	if err != nil {
+		h.l.Error("incr read count failed",
+			zap.Error(err), zap.Int64("aid", art.Id))
+	}
+
+	intr, err := h.intrSvc.Get(ctx, h.biz, art.Id, uc.Uid)
+	if err != nil {
+		// 计数拿不到也可以看文章
+		h.l.Error("get interactive failed",
+			zap.Error(err), zap.Int64("aid", art.Id), zap.Int64("uid", uc.Uid))
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: toPubArticleVO(art, intr),
+	})
+}
+
+func (h *ArticleHandler) Like(ctx *gin.Context) {
+	type Req struct {
+		Id int64 `json:"id"`
+		// true 是点赞，false 是取消点赞
+		Like bool `json:"like"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	uc, ok := ctx.MustGet("user").(UserClaims)
+	if !ok {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	var err error
+	if req.Like {
+		if !h.checkPublished(ctx, req.Id) {
+			return
+		}
+		err = h.intrSvc.Like(ctx, h.biz, req.Id, uc.Uid)
+	} else {
+		// 取消点赞不用检查，撤回之后也可以取消
+		err = h.intrSvc.CancelLike(ctx, h.biz, req.Id, uc.Uid)
+	}
+	if err != nil {
+		h.l.Error("like failed", zap.Error(err),
+			zap.Int64("aid", req.Id), zap.Int64("uid", uc.Uid),
+			zap.Bool("like", req.Like))
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "System error",
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Msg: "OK",
+	})
+}
+
+func (h *ArticleHandler) Collect(ctx *gin.Context) {
+	type Req struct {
+		Id  int64 `json:"id"`
+		Cid int64 `json:"cid"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	uc, ok := ctx.MustGet("user").(UserClaims)
+	if !ok {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	if !h.checkPublished(ctx, req.Id) {
+		return
+	}
+	err := h.intrSvc.Collect(ctx, h.biz, req.Id, req.Cid, uc.Uid)
+	if err == service.ErrCollectionNotFound {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Collection not found",
+		})
+		return
+	}
+	if err != nil {
+		h.l.Error("collect failed", zap.Error(err),
+			zap.Int64("aid", req.Id), zap.Int64("cid", req.Cid),
+			zap.Int64("uid", uc.Uid))
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "System error",
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Msg: "OK",
+	})
+}
+
+// checkPublished 只能给读者看得到的文章点赞和收藏，不是的话已经写好了响应
+func (h *ArticleHandler) checkPublished(ctx *gin.Context, id int64) bool {
+	_, err := h.svc.GetPublishedById(ctx, id)
+	switch err {
+	case nil:
+		return true
+	case service.ErrArticleNotFound:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Article not found",
+		})
+	default:
+		h.l.Error("get published article failed",
+			zap.Error(err), zap.Int64("aid", id))
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "System error",
+		})
+	}
+	return false
+}
+
+// HotList 热榜，定时任务算好放在缓存里面，这里只读缓存
+func (h *ArticleHandler) HotList(ctx *gin.Context) {
+	arts, err := h.rankingSvc.GetTopN(ctx)
+	if err != nil {
+		h.l.Error("get hot list failed", zap.Error(err))
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "System error",
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: slice.Map(arts, func(idx int, src domain.Article) ArticleVO {
+			return toArticleListVO(src)
+		}),
+	})
+}
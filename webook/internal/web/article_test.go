@@ -0,0 +1,211 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/service"
+	svcmocks "gitee.com/geekbang/basic-go/webook/internal/service/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+func TestArticleHandler_Publish(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) service.ArticleService
+		reqBody  string
+		wantCode int
+		wantRes  Result
+	}{
+		{
+			name: "new article published",
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				svc := svcmocks.NewMockArticleService(ctrl)
+				svc.EXPECT().Publish(gomock.Any(), domain.Article{
+					Title:   "my title",
+					Content: "my content",
+					Author: domain.Author{
+						Id: 123,
+					},
+				}).Return(int64(1), nil)
+				return svc
+			},
+			reqBody:  `{"title":"my title","content":"my content"}`,
+			wantCode: http.StatusOK,
+			wantRes: Result{
+				// json 反序列化数字的时候是 float64
+				Data: float64(1),
+			},
+		},
+		{
+			name: "existing article published",
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				svc := svcmocks.NewMockArticleService(ctrl)
+				svc.EXPECT().Publish(gomock.Any(), domain.Article{
+					Id:      2,
+					Title:   "my title",
+					Content: "my content",
+					Author: domain.Author{
+						Id: 123,
+					},
+				}).Return(int64(2), nil)
+				return svc
+			},
+			reqBody:  `{"id":2,"title":"my title","content":"my content"}`,
+			wantCode: http.StatusOK,
+			wantRes: Result{
+				Data: float64(2),
+			},
+		},
+		{
+			name: "not the author",
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				svc := svcmocks.NewMockArticleService(ctrl)
+				svc.EXPECT().Publish(gomock.Any(), gomock.Any()).
+					Return(int64(2), service.ErrPossibleIncorrectAuthor)
+				return svc
+			},
+			reqBody:  `{"id":2,"title":"my title","content":"my content"}`,
+			wantCode: http.StatusOK,
+			wantRes: Result{
+				Code: 4,
+				Msg:  "Article not found",
+			},
+		},
+		{
+			name: "publish failed",
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				svc := svcmocks.NewMockArticleService(ctrl)
+				svc.EXPECT().Publish(gomock.Any(), gomock.Any()).
+					Return(int64(0), errors.New("db error"))
+				return svc
+			},
+			reqBody:  `{"title":"my title","content":"my content"}`,
+			wantCode: http.StatusOK,
+			wantRes: Result{
+				Code: 5,
+				Msg:  "System error",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			hdl := NewArticleHandler(tc.mock(ctrl), nil, nil, zap.NewNop())
+
+			server := gin.Default()
+			server.Use(func(ctx *gin.Context) {
+				ctx.Set("user", UserClaims{
+					Uid: 123,
+				})
+			})
+			hdl.RegisterRoutes(server)
+
+			req, err := http.NewRequest(http.MethodPost,
+				"/articles/publish", bytes.NewReader([]byte(tc.reqBody)))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tc.wantCode, recorder.Code)
+			var res Result
+			err = json.NewDecoder(recorder.Body).Decode(&res)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantRes, res)
+		})
+	}
+}
+
+func TestArticleHandler_Like(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) (service.ArticleService, service.InteractiveService)
+		reqBody string
+		wantRes Result
+	}{
+		{
+			name: "liked",
+			mock: func(ctrl *gomock.Controller) (service.ArticleService, service.InteractiveService) {
+				svc := svcmocks.NewMockArticleService(ctrl)
+				svc.EXPECT().GetPublishedById(gomock.Any(), int64(1)).
+					Return(domain.Article{Id: 1}, nil)
+				intrSvc := svcmocks.NewMockInteractiveService(ctrl)
+				intrSvc.EXPECT().Like(gomock.Any(), "article", int64(1), int64(123)).Return(nil)
+				return svc, intrSvc
+			},
+			reqBody: `{"id":1,"like":true}`,
+			wantRes: Result{
+				Msg: "OK",
+			},
+		},
+		{
+			name: "withdrawn article",
+			mock: func(ctrl *gomock.Controller) (service.ArticleService, service.InteractiveService) {
+				svc := svcmocks.NewMockArticleService(ctrl)
+				svc.EXPECT().GetPublishedById(gomock.Any(), int64(2)).
+					Return(domain.Article{}, service.ErrArticleNotFound)
+				// 不会记点赞
+				return svc, svcmocks.NewMockInteractiveService(ctrl)
+			},
+			reqBody: `{"id":2,"like":true}`,
+			wantRes: Result{
+				Code: 4,
+				Msg:  "Article not found",
+			},
+		},
+		{
+			name: "cancel like without check",
+			mock: func(ctrl *gomock.Controller) (service.ArticleService, service.InteractiveService) {
+				intrSvc := svcmocks.NewMockInteractiveService(ctrl)
+				intrSvc.EXPECT().CancelLike(gomock.Any(), "article", int64(2), int64(123)).Return(nil)
+				return svcmocks.NewMockArticleService(ctrl), intrSvc
+			},
+			reqBody: `{"id":2,"like":false}`,
+			wantRes: Result{
+				Msg: "OK",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, intrSvc := tc.mock(ctrl)
+			hdl := NewArticleHandler(svc, intrSvc, nil, zap.NewNop())
+
+			server := gin.Default()
+			server.Use(func(ctx *gin.Context) {
+				ctx.Set("user", UserClaims{
+					Uid: 123,
+				})
+			})
+			hdl.RegisterRoutes(server)
+
+			req, err := http.NewRequest(http.MethodPost,
+				"/articles/pub/like", bytes.NewReader([]byte(tc.reqBody)))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			var res Result
+			err = json.NewDecoder(recorder.Body).Decode(&res)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantRes, res)
+		})
+	}
+}
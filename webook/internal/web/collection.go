@@ -0,0 +1,252 @@
+package web
+
+import (
+	"net/http"
+	"time"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/service"
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// CollectionHandler 收藏夹的增删改查，目前只能收藏文章
+type CollectionHandler struct {
+	svc    service.CollectionService
+	artSvc service.ArticleService
+	l      *zap.Logger
+	biz    string
+}
+
+func NewCollectionHandler(svc service.CollectionService,
+	artSvc service.ArticleService, l *zap.Logger) *CollectionHandler {
+	return &CollectionHandler{
+		svc:    svc,
+		artSvc: artSvc,
+		l:      l,
+		biz:    "article",
+	}
+}
+
+func (h *CollectionHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/collections")
+	g.POST("/create", h.Create)
+	g.POST("/rename", h.Rename)
+	g.POST("/delete", h.Delete)
+	g.GET("/list", h.List)
+	g.POST("/items", h.Items)
+	g.POST("/items/delete", h.RemoveItem)
+}
+
+type CollectionVO struct {
+	Id    int64  `json:"id"`
+	Name  string `json:"name"`
+	Ctime string `json:"ctime"`
+}
+
+type CollectionItemVO struct {
+	Id    int64  `json:"id"`
+	Title string `json:"title"`
+	// 收藏的时间
+	Ctime string `json:"ctime"`
+}
+
+func (h *CollectionHandler) Create(ctx *gin.Context) {
+	type Req struct {
+		Name string `json:"name"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	if req.Name == "" {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Please input collection name",
+		})
+		return
+	}
+	uc, ok := ctx.MustGet("user").(UserClaims)
+	if !ok {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	id, err := h.svc.Create(ctx, domain.Collection{
+		Uid:  uc.Uid,
+		Name: req.Name,
+	})
+	if err != nil {
+		h.l.Error("create collection failed", zap.Error(err), zap.Int64("uid", uc.Uid))
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "System error",
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: id,
+	})
+}
+
+func (h *CollectionHandler) Rename(ctx *gin.Context) {
+	type Req struct {
+		Id   int64  `json:"id"`
+		Name string `json:"name"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	if req.Name == "" {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Please input collection name",
+		})
+		return
+	}
+	uc, ok := ctx.MustGet("user").(UserClaims)
+	if !ok {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	err := h.svc.Rename(ctx, uc.Uid, req.Id, req.Name)
+	h.writeResult(ctx, "rename collection failed", uc.Uid, req.Id, err)
+}
+
+func (h *CollectionHandler) Delete(ctx *gin.Context) {
+	type Req struct {
+		Id int64 `json:"id"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	uc, ok := ctx.MustGet("user").(UserClaims)
+	if !ok {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	err := h.svc.Delete(ctx, uc.Uid, req.Id)
+	h.writeResult(ctx, "delete collection failed", uc.Uid, req.Id, err)
+}
+
+func (h *CollectionHandler) List(ctx *gin.Context) {
+	uc, ok := ctx.MustGet("user").(UserClaims)
+	if !ok {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	cs, err := h.svc.List(ctx, uc.Uid)
+	if err != nil {
+		h.l.Error("list collections failed", zap.Error(err), zap.Int64("uid", uc.Uid))
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "System error",
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: slice.Map(cs, func(idx int, src domain.Collection) CollectionVO {
+			return CollectionVO{
+				Id:    src.Id,
+				Name:  src.Name,
+				Ctime: src.Ctime.Format(time.DateTime),
+			}
+		}),
+	})
+}
+
+// Items 收藏夹里面的文章，按照收藏时间倒序
+func (h *CollectionHandler) Items(ctx *gin.Context) {
+	type Req struct {
+		Cid    int64 `json:"cid"`
+		Offset int   `json:"offset"`
+		Limit  int   `json:"limit"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	if req.Offset < 0 || req.Limit <= 0 || req.Limit > 100 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Invalid offset or limit",
+		})
+		return
+	}
+	uc, ok := ctx.MustGet("user").(UserClaims)
+	if !ok {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	items, err := h.svc.ListItems(ctx, uc.Uid, req.Cid, req.Offset, req.Limit)
+	if err != nil {
+		h.writeResult(ctx, "list collection items failed", uc.Uid, req.Cid, err)
+		return
+	}
+	ids := slice.Map(items, func(idx int, src domain.CollectionItem) int64 {
+		return src.BizId
+	})
+	var arts []domain.Article
+	if len(ids) > 0 {
+		arts, err = h.artSvc.GetPublishedByIds(ctx, ids)
+		if err != nil {
+			h.writeResult(ctx, "get collected articles failed", uc.Uid, req.Cid, err)
+			return
+		}
+	}
+	titles := make(map[int64]string, len(arts))
+	for _, art := range arts {
+		titles[art.Id] = art.Title
+	}
+	// 撤回的文章还留在收藏夹里，只是没有标题
+	ctx.JSON(http.StatusOK, Result{
+		Data: slice.Map(items, func(idx int, src domain.CollectionItem) CollectionItemVO {
+			return CollectionItemVO{
+				Id:    src.BizId,
+				Title: titles[src.BizId],
+				Ctime: src.Ctime.Format(time.DateTime),
+			}
+		}),
+	})
+}
+
+// RemoveItem 取消收藏
+func (h *CollectionHandler) RemoveItem(ctx *gin.Context) {
+	type Req struct {
+		Id int64 `json:"id"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	uc, ok := ctx.MustGet("user").(UserClaims)
+	if !ok {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	err := h.svc.RemoveItem(ctx, uc.Uid, h.biz, req.Id)
+	h.writeResult(ctx, "remove collection item failed", uc.Uid, req.Id, err)
+}
+
+func (h *CollectionHandler) writeResult(ctx *gin.Context, logMsg string, uid int64, id int64, err error) {
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "OK",
+		})
+	case service.ErrCollectionNotFound:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Collection not found",
+		})
+	default:
+		h.l.Error(logMsg, zap.Error(err),
+			zap.Int64("uid", uid), zap.Int64("id", id))
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "System error",
+		})
+	}
+}
@@ -1,37 +1,233 @@
 package web
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/service"
+	"gitee.com/geekbang/basic-go/webook/pkg/jwtx"
 	"github.com/gin-gonic/gin"
 	jwt "github.com/golang-jwt/jwt/v5"
+	uuid "github.com/lithammer/shortuuid/v4"
+	"github.com/redis/go-redis/v9"
 )
 
-type jwtHandler struct {
+// ErrSessionRevoked 会话已经退出登录
+var ErrSessionRevoked = errors.New("session revoked")
+
+// JWTHandler 负责下发 token，以及用 Redis 记录退出登录的会话
+type JWTHandler struct {
+	cmd        redis.Cmdable
+	sessionSvc service.SessionService
+	// rbacSvc 签 access token 的时候拿角色和权限，顺便拦住被封禁的用户
+	rbacSvc service.RBACService
+	// accessKeys 签 access token，公钥通过 JWKS 给别的服务验签
+	accessKeys *jwtx.KeySet
+	// refreshKeys 签 refresh token，和 access token 分开
+	refreshKeys *jwtx.KeySet
+	// allowWhenRedisDown Redis 出问题的时候是放行还是拒绝
+	allowWhenRedisDown bool
+}
+
+func NewJWTHandler(cmd redis.Cmdable, sessionSvc service.SessionService, rbacSvc service.RBACService,
+	accessKeys *jwtx.KeySet, refreshKeys *jwtx.KeySet, allowWhenRedisDown bool) *JWTHandler {
+	return &JWTHandler{
+		cmd:                cmd,
+		sessionSvc:         sessionSvc,
+		rbacSvc:            rbacSvc,
+		accessKeys:         accessKeys,
+		refreshKeys:        refreshKeys,
+		allowWhenRedisDown: allowWhenRedisDown,
+	}
 }
 
-const JWTKey = "jYe8vbdGFD7RRnIf8W7KArU2ehZJbbn8"
+const (
+	accessTokenExpiration  = time.Minute * 15
+	refreshTokenExpiration = time.Hour * 24 * 7
+)
 
 type UserClaims struct {
 	jwt.RegisteredClaims
 	Uid       int64
+	Ssid      string
 	UserAgent string
+	// Roles 和 Permissions 是签发时候的快照，只给前端决定展示哪些入口。
+	// 检查权限的时候 PermissionMiddlewareBuilder 会重新查
+	Roles       []domain.Role
+	Permissions []domain.Permission
 }
 
+// RefreshClaims 长 token 只用来换新的 access token
+type RefreshClaims struct {
+	jwt.RegisteredClaims
+	Uid  int64
+	Ssid string
+}
 
-func (h *jwtHandler) SetJWTToken(ctx *gin.Context, uid int64) {
-	uc := UserClaims{
+// SetLoginToken 登录成功之后开一个新的会话，同时下发 access token 和 refresh token。
+// 会话记录下来之后才下发 token，不然这个会话不在列表里面，改密码的时候也踢不掉。
+// 用户被封禁了返回 service.ErrUserBanned
+func (h *JWTHandler) SetLoginToken(ctx *gin.Context, uid int64, method domain.SessionMethod) error {
+	ssid := uuid.New()
+	accessToken, err := h.signAccessToken(ctx, uid, ssid)
+	if err != nil {
+		return err
+	}
+	refreshToken, err := h.signRefreshToken(uid, ssid)
+	if err != nil {
+		return err
+	}
+	err = h.sessionSvc.Record(ctx, domain.Session{
+		Ssid:      ssid,
 		Uid:       uid,
 		UserAgent: ctx.GetHeader("User-Agent"),
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute * 30))},
+		IP:        ctx.ClientIP(),
+		Method:    method,
+		Ctime:     time.Now(),
+	})
+	if err != nil {
+		return err
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS512, uc)
-	tokenStr, err := token.SignedString([]byte(JWTKey))
-	//log.Printf("login token str: %v", tokenStr)
+	ctx.Header("x-jwt-token", accessToken)
+	ctx.Header("x-refresh-token", refreshToken)
+	return nil
+}
+
+func (h *JWTHandler) SetJWTToken(ctx *gin.Context, uid int64, ssid string) error {
+	tokenStr, err := h.signAccessToken(ctx, uid, ssid)
 	if err != nil {
-		ctx.String(http.StatusOK, "系统错误: %v", err)
+		return err
 	}
 	ctx.Header("x-jwt-token", tokenStr)
+	return nil
+}
+
+func (h *JWTHandler) signAccessToken(ctx *gin.Context, uid int64, ssid string) (string, error) {
+	authz, err := h.rbacSvc.Authz(ctx, uid)
+	if err != nil {
+		return "", err
+	}
+	uc := UserClaims{
+		Uid:         uid,
+		Ssid:        ssid,
+		UserAgent:   ctx.GetHeader("User-Agent"),
+		Roles:       authz.Roles,
+		Permissions: authz.Permissions,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(accessTokenExpiration)),
+		},
+	}
+	return h.accessKeys.Sign(uc)
+}
+
+func (h *JWTHandler) signRefreshToken(uid int64, ssid string) (string, error) {
+	rc := RefreshClaims{
+		Uid:  uid,
+		Ssid: ssid,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(refreshTokenExpiration)),
+		},
+	}
+	return h.refreshKeys.Sign(rc)
+}
+
+// ParseAccessToken 校验签名和过期时间，会话是否退出登录要另外用 CheckSession 检查
+func (h *JWTHandler) ParseAccessToken(tokenStr string) (UserClaims, error) {
+	var uc UserClaims
+	token, err := h.accessKeys.Parse(tokenStr, &uc)
+	if err != nil {
+		return UserClaims{}, err
+	}
+	if token == nil || !token.Valid {
+		return UserClaims{}, errors.New("invalid token")
+	}
+	return uc, nil
+}
+
+func (h *JWTHandler) ParseRefreshToken(tokenStr string) (RefreshClaims, error) {
+	var rc RefreshClaims
+	token, err := h.refreshKeys.Parse(tokenStr, &rc)
+	if err != nil {
+		return RefreshClaims{}, err
+	}
+	if token == nil || !token.Valid {
+		return RefreshClaims{}, errors.New("invalid token")
+	}
+	return rc, nil
+}
+
+// JWKS access token 的公钥，别的服务用它验签
+func (h *JWTHandler) JWKS(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, h.accessKeys.JWKS())
+}
+
+// ClearToken 退出登录：清空前端的 token，并且把会话记到 Redis 里面。
+// 记录保留到 refresh token 过期，之后这个会话的 token 本来也用不了了。
+func (h *JWTHandler) ClearToken(ctx *gin.Context) error {
+	ctx.Header("x-jwt-token", "")
+	ctx.Header("x-refresh-token", "")
+	uc, ok := ctx.MustGet("user").(UserClaims)
+	if !ok {
+		return errors.New("user claims not found")
+	}
+	err := h.sessionSvc.Remove(ctx, uc.Uid, uc.Ssid)
+	if err != nil && err != service.ErrSessionNotFound {
+		return err
+	}
+	return h.RevokeSession(ctx, uc.Ssid)
+}
+
+// RevokeOtherSessions 让 uid 除了 except 以外的会话全部下线，except 为空就是全部下线
+func (h *JWTHandler) RevokeOtherSessions(ctx context.Context, uid int64, except string) error {
+	ssids, err := h.sessionSvc.RemoveOthers(ctx, uid, except)
+	// 部分删除成功的也要让它们下线
+	for _, ssid := range ssids {
+		if er := h.RevokeSession(ctx, ssid); er != nil {
+			err = er
+		}
+	}
+	return err
+}
+
+// RevokeSession 让会话的 token 马上失效
+func (h *JWTHandler) RevokeSession(ctx context.Context, ssid string) error {
+	return h.cmd.Set(ctx, h.ssidKey(ssid), "", refreshTokenExpiration).Err()
+}
+
+// CheckSession 会话退出登录了返回 ErrSessionRevoked。
+// Redis 出错的时候按照 allowWhenRedisDown 放行或者返回错误。
+func (h *JWTHandler) CheckSession(ctx context.Context, ssid string) error {
+	cnt, err := h.cmd.Exists(ctx, h.ssidKey(ssid)).Result()
+	if err != nil {
+		if h.allowWhenRedisDown {
+			return nil
+		}
+		return err
+	}
+	if cnt > 0 {
+		return ErrSessionRevoked
+	}
+	return nil
+}
+
+func (h *JWTHandler) ssidKey(ssid string) string {
+	return fmt.Sprintf("users:ssid:%s", ssid)
+}
+
+// ExtractToken 从 Authorization: Bearer xxx 里面拿 token，access token 和 refresh token 都是这么传的
+func ExtractToken(ctx *gin.Context) string {
+	authCode := ctx.GetHeader("Authorization")
+	if authCode == "" {
+		return ""
+	}
+	segs := strings.Split(authCode, " ")
+	if len(segs) != 2 {
+		return ""
+	}
+	return segs[1]
 }
@@ -0,0 +1,227 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/cache/redismocks"
+	"gitee.com/geekbang/basic-go/webook/internal/service"
+	svcmocks "gitee.com/geekbang/basic-go/webook/internal/service/mocks"
+	"gitee.com/geekbang/basic-go/webook/pkg/jwtx"
+	"github.com/gin-gonic/gin"
+	jwt "github.com/golang-jwt/jwt/v5"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+var (
+	testAccessKeys = mustKeySet(jwtx.NewKeySet("access-1",
+		jwtx.NewHMACKey("access-1", jwt.SigningMethodHS512, []byte("access-secret"))))
+	testRefreshKeys = mustKeySet(jwtx.NewKeySet("refresh-1",
+		jwtx.NewHMACKey("refresh-1", jwt.SigningMethodHS512, []byte("refresh-secret"))))
+)
+
+func mustKeySet(s *jwtx.KeySet, err error) *jwtx.KeySet {
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func newTestJWTHandler(cmd redis.Cmdable, sessionSvc service.SessionService, allow bool) *JWTHandler {
+	return NewJWTHandler(cmd, sessionSvc, stubRBACService{}, testAccessKeys, testRefreshKeys, allow)
+}
+
+// stubRBACService 大部分测试不关心角色，固定返回 authz 和 err
+type stubRBACService struct {
+	service.RBACService
+	authz domain.Authz
+	err   error
+}
+
+func (s stubRBACService) Authz(ctx context.Context, uid int64) (domain.Authz, error) {
+	return s.authz, s.err
+}
+
+func TestUserHandler_RefreshToken(t *testing.T) {
+	sign := func(t *testing.T, claims jwt.Claims, keys *jwtx.KeySet) string {
+		tokenStr, err := keys.Sign(claims)
+		require.NoError(t, err)
+		return tokenStr
+	}
+	validToken := func(t *testing.T) string {
+		return sign(t, RefreshClaims{
+			Uid:  123,
+			Ssid: "ssid-1",
+			RegisteredClaims: jwt.RegisteredClaims{
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			},
+		}, testRefreshKeys)
+	}
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) redis.Cmdable
+		allow    bool
+		token    func(t *testing.T) string
+		wantCode int
+		wantSsid string
+	}{
+		{
+			name: "refreshed",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				cmd.EXPECT().Exists(gomock.Any(), "users:ssid:ssid-1").
+					Return(redis.NewIntResult(0, nil))
+				return cmd
+			},
+			token:    validToken,
+			wantCode: http.StatusOK,
+			wantSsid: "ssid-1",
+		},
+		{
+			name: "session revoked",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				cmd.EXPECT().Exists(gomock.Any(), "users:ssid:ssid-1").
+					Return(redis.NewIntResult(1, nil))
+				return cmd
+			},
+			token:    validToken,
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name: "redis down, allow",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				cmd.EXPECT().Exists(gomock.Any(), "users:ssid:ssid-1").
+					Return(redis.NewIntResult(0, errors.New("redis down")))
+				return cmd
+			},
+			allow:    true,
+			token:    validToken,
+			wantCode: http.StatusOK,
+			wantSsid: "ssid-1",
+		},
+		{
+			name: "redis down, deny",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				cmd.EXPECT().Exists(gomock.Any(), "users:ssid:ssid-1").
+					Return(redis.NewIntResult(0, errors.New("redis down")))
+				return cmd
+			},
+			token:    validToken,
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name: "expired",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				return redismocks.NewMockCmdable(ctrl)
+			},
+			token: func(t *testing.T) string {
+				return sign(t, RefreshClaims{
+					Uid:  123,
+					Ssid: "ssid-1",
+					RegisteredClaims: jwt.RegisteredClaims{
+						ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Hour)),
+					},
+				}, testRefreshKeys)
+			},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			// access token 是另外一个 key 签的，不能拿来刷新
+			name: "access token",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				return redismocks.NewMockCmdable(ctrl)
+			},
+			token: func(t *testing.T) string {
+				return sign(t, UserClaims{
+					Uid:  123,
+					Ssid: "ssid-1",
+					RegisteredClaims: jwt.RegisteredClaims{
+						ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+					},
+				}, testAccessKeys)
+			},
+			wantCode: http.StatusUnauthorized,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			hdl := NewUserHandler(nil, nil, nil, nil, newTestJWTHandler(tc.mock(ctrl), nil, tc.allow))
+			server := gin.Default()
+			hdl.RegisterRoutes(server)
+			req, err := http.NewRequest(http.MethodPost, "/users/refresh_token", nil)
+			require.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer "+tc.token(t))
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tc.wantCode, recorder.Code)
+			if tc.wantCode != http.StatusOK {
+				return
+			}
+			assert.Empty(t, recorder.Header().Get("x-refresh-token"))
+			var uc UserClaims
+			_, err = testAccessKeys.Parse(recorder.Header().Get("x-jwt-token"), &uc)
+			require.NoError(t, err)
+			assert.Equal(t, int64(123), uc.Uid)
+			assert.Equal(t, tc.wantSsid, uc.Ssid)
+		})
+	}
+}
+
+func TestJWTHandler_SetLoginToken(t *testing.T) {
+	testCases := []struct {
+		name      string
+		mock      func(ctrl *gomock.Controller) service.SessionService
+		wantErr   error
+		wantToken bool
+	}{
+		{
+			name: "recorded",
+			mock: func(ctrl *gomock.Controller) service.SessionService {
+				sessionSvc := svcmocks.NewMockSessionService(ctrl)
+				sessionSvc.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil)
+				return sessionSvc
+			},
+			wantToken: true,
+		},
+		{
+			// 会话没记下来，就不能把 token 给出去
+			name: "record failed",
+			mock: func(ctrl *gomock.Controller) service.SessionService {
+				sessionSvc := svcmocks.NewMockSessionService(ctrl)
+				sessionSvc.EXPECT().Record(gomock.Any(), gomock.Any()).
+					Return(errors.New("redis down"))
+				return sessionSvc
+			},
+			wantErr: errors.New("redis down"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			hdl := newTestJWTHandler(nil, tc.mock(ctrl), false)
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Request = httptest.NewRequest(http.MethodPost, "/users/login", nil)
+
+			err := hdl.SetLoginToken(ctx, 123, domain.SessionMethodPassword)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantToken, recorder.Header().Get("x-jwt-token") != "")
+			assert.Equal(t, tc.wantToken, recorder.Header().Get("x-refresh-token") != "")
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+
+	"gitee.com/geekbang/basic-go/webook/internal/service"
+	"gitee.com/geekbang/basic-go/webook/internal/web"
+	"github.com/gin-gonic/gin"
+)
+
+// EmailVerifiedMiddlewareBuilder 有些操作要求邮箱已经验证过，比如发表文章。
+// 没有邮箱的用户（手机号、微信登录）不受影响。要放在登录校验后面
+type EmailVerifiedMiddlewareBuilder struct {
+	svc   service.UserService
+	paths map[string]struct{}
+}
+
+func NewEmailVerifiedMiddlewareBuilder(svc service.UserService) *EmailVerifiedMiddlewareBuilder {
+	return &EmailVerifiedMiddlewareBuilder{
+		svc:   svc,
+		paths: map[string]struct{}{},
+	}
+}
+
+func (b *EmailVerifiedMiddlewareBuilder) Paths(paths ...string) *EmailVerifiedMiddlewareBuilder {
+	for _, p := range paths {
+		b.paths[p] = struct{}{}
+	}
+	return b
+}
+
+func (b *EmailVerifiedMiddlewareBuilder) Build() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if _, ok := b.paths[ctx.Request.URL.Path]; !ok {
+			return
+		}
+		uc, ok := ctx.Get("user")
+		if !ok {
+			ctx.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		// 走缓存，验证之后缓存会被删掉
+		u, err := b.svc.FindById(ctx, uc.(web.UserClaims).Uid)
+		if err != nil {
+			log.Println("find user error", err)
+			ctx.AbortWithStatusJSON(http.StatusOK, web.Result{
+				Code: 5,
+				Msg:  "System error",
+			})
+			return
+		}
+		if u.Email != "" && !u.EmailVerified {
+			ctx.AbortWithStatusJSON(http.StatusOK, web.Result{
+				Code: 4,
+				Msg:  "Please verify your email first",
+			})
+			return
+		}
+	}
+}
@@ -4,14 +4,23 @@ import (
 	"log"
 	"net/http"
 	"strings"
-	"time"
 
+	"gitee.com/geekbang/basic-go/webook/internal/service"
 	"gitee.com/geekbang/basic-go/webook/internal/web"
 	"github.com/gin-gonic/gin"
-	jwt "github.com/golang-jwt/jwt/v5"
 )
 
 type LoginJWTMiddlewareBuiler struct {
+	jwtHdl *web.JWTHandler
+	// userSvc 检查用户是不是被封禁了，走的是用户缓存
+	userSvc service.UserService
+}
+
+func NewLoginJWTMiddlewareBuiler(jwtHdl *web.JWTHandler, userSvc service.UserService) *LoginJWTMiddlewareBuiler {
+	return &LoginJWTMiddlewareBuiler{
+		jwtHdl:  jwtHdl,
+		userSvc: userSvc,
+	}
 }
 
 func (m *LoginJWTMiddlewareBuiler) CheckLogin() gin.HandlerFunc {
@@ -21,62 +30,67 @@ func (m *LoginJWTMiddlewareBuiler) CheckLogin() gin.HandlerFunc {
 			path == "/users/login"||
 			path == "/users/login_sms/code/send" ||
 			path == "/users/login_sms" ||
-			path == "/oauth2/wechat/authurl" ||
-			path == "/oauth2/wechat/callback"  {
+			path == "/users/login_2fa" ||
+			path == "/users/refresh_token" ||
+			path == "/users/reset_pwd/code/send" ||
+			path == "/users/reset_pwd" ||
+			path == "/users/verify_email/code/send" ||
+			path == "/users/verify_email" ||
+			path == "/.well-known/jwks.json" ||
+			path == "/oauth2/providers" ||
+			isOAuth2LoginPath(path) {
 			// no need to verfiy jwt
 			return
 		}
-		authCode := ctx.GetHeader("Authorization")
-		if authCode == "" {
+		tokenStr := web.ExtractToken(ctx)
+		if tokenStr == "" {
 			log.Println("authcode empty")
 			ctx.AbortWithStatus(http.StatusUnauthorized)
 			return
 		}
-		//log.Println("authcode :", authCode)
-		segs := strings.Split(authCode, " ")
-		if len(segs) != 2 {
+		uc, err := m.jwtHdl.ParseAccessToken(tokenStr)
+		if err != nil {
+			log.Println("parse token error", err)
 			ctx.AbortWithStatus(http.StatusUnauthorized)
 			return
 		}
-		tokenStr := segs[1]
-		var uc web.UserClaims
-		token, err := jwt.ParseWithClaims(tokenStr, &uc, func(token *jwt.Token) (interface{}, error) {
-			return []byte(web.JWTKey), nil
-		})
-		if err != nil {
-			log.Println("parse token error")
+		if uc.UserAgent!=ctx.GetHeader("User-Agent"){
+			// Need to track events here
 			ctx.AbortWithStatus(http.StatusUnauthorized)
 			return
 		}
-		if token==nil || !token.Valid {
+
+		// 退出登录的会话，或者 Redis 出问题并且配置了拒绝
+		if err = m.jwtHdl.CheckSession(ctx, uc.Ssid); err != nil {
+			log.Println("check session error", err)
 			ctx.AbortWithStatus(http.StatusUnauthorized)
 			return
 		}
-		if uc.UserAgent!=ctx.GetHeader("User-Agent"){
-			// Need to track events here
+
+		// 封禁之后已经签发的 token 也不能用，封禁的时候会删缓存所以马上生效
+		u, err := m.userSvc.FindById(ctx, uc.Uid)
+		switch {
+		case err == service.ErrUserNotFound:
 			ctx.AbortWithStatus(http.StatusUnauthorized)
 			return
+		case err != nil:
+			log.Println("find user error", err)
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		case u.Banned():
+			ctx.AbortWithStatus(http.StatusForbidden)
+			return
 		}
 
-		expireTime := uc.ExpiresAt
-		//log.Println("expire time", expireTime.Time)
-		// if expireTime.Before(time.Now()){
-		// 	ctx.AbortWithStatus(http.StatusUnauthorized)
-		// 	return
-		// }
-		//log.Println("token str:", tokenStr)
-		if expireTime.Sub(time.Now()) < time.Minute*3 {
-			//刷新 jwt token
-			uc.ExpiresAt = jwt.NewNumericDate(time.Now().Add(time.Minute*30))
-			//token中有一个claim指针，所以修改uc.Expire就能直接对token生成tokenstr产生影响
-			tokenStr, err = token.SignedString([]byte(web.JWTKey))
-			ctx.Header("x-jwt-token", tokenStr)
-			if err != nil {
-				log.Println("refresh error", err)
-			}
-
-		}
+		// 不再自动续期，access token 过期之后前端用 refresh token 调 /users/refresh_token
 		// uc里面有uid
 		ctx.Set("user", uc)
 	}
 }
+
+// isOAuth2LoginPath /oauth2/{provider}/authurl 和 /oauth2/{provider}/callback，微信也在里面
+func isOAuth2LoginPath(path string) bool {
+	segs := strings.Split(path, "/")
+	return len(segs) == 4 && segs[1] == "oauth2" && segs[2] != "" &&
+		(segs[3] == "authurl" || segs[3] == "callback")
+}
@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/service"
+	"gitee.com/geekbang/basic-go/webook/internal/web"
+	"github.com/gin-gonic/gin"
+)
+
+// PermissionMiddlewareBuilder 按路径检查权限，要放在登录校验后面。
+// 配置了权限的路径每次都查数据库，授予和撤销角色马上生效，不看 token 里面的快照
+type PermissionMiddlewareBuilder struct {
+	svc   service.RBACService
+	perms map[string]domain.Permission
+	// denyPrefixes 这些前缀下面没有配置权限的路径一律拒绝，免得新加的接口忘了配
+	denyPrefixes []string
+}
+
+func NewPermissionMiddlewareBuilder(svc service.RBACService) *PermissionMiddlewareBuilder {
+	return &PermissionMiddlewareBuilder{
+		svc:   svc,
+		perms: map[string]domain.Permission{},
+	}
+}
+
+// Require 访问 paths 需要 perm
+func (b *PermissionMiddlewareBuilder) Require(perm domain.Permission, paths ...string) *PermissionMiddlewareBuilder {
+	for _, p := range paths {
+		b.perms[p] = perm
+	}
+	return b
+}
+
+func (b *PermissionMiddlewareBuilder) DenyByDefault(prefixes ...string) *PermissionMiddlewareBuilder {
+	b.denyPrefixes = append(b.denyPrefixes, prefixes...)
+	return b
+}
+
+func (b *PermissionMiddlewareBuilder) Build() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		path := ctx.Request.URL.Path
+		perm, ok := b.perms[path]
+		if !ok {
+			if b.denied(path) {
+				ctx.AbortWithStatus(http.StatusForbidden)
+			}
+			return
+		}
+		val, ok := ctx.Get("user")
+		if !ok {
+			ctx.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		uc, ok := val.(web.UserClaims)
+		if !ok {
+			ctx.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		authz, err := b.svc.Authz(ctx, uc.Uid)
+		switch err {
+		case nil:
+		case service.ErrUserBanned:
+			ctx.AbortWithStatus(http.StatusForbidden)
+			return
+		default:
+			log.Println("find authz error", err)
+			ctx.AbortWithStatusJSON(http.StatusOK, web.Result{
+				Code: 5,
+				Msg:  "System error",
+			})
+			return
+		}
+		if !authz.Has(perm) {
+			ctx.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+	}
+}
+
+func (b *PermissionMiddlewareBuilder) denied(path string) bool {
+	for _, prefix := range b.denyPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
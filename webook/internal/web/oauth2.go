@@ -0,0 +1,254 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/service"
+	"gitee.com/geekbang/basic-go/webook/internal/service/oauth2"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// OAuth2Handler 配置出来的第三方登录，GitHub、Google、Keycloak 这种。微信还是走 OAuth2WechatHandler
+type OAuth2Handler struct {
+	*JWTHandler
+	registry        *oauth2.Registry
+	userSvc         service.UserService
+	key             []byte
+	stateCookieName string
+}
+
+// OAuth2StateClaims 授权参数整个放在 cookie 里面，回调的时候校验
+type OAuth2StateClaims struct {
+	jwt.RegisteredClaims
+	Provider string
+	oauth2.AuthRequest
+	// Uid 绑定的时候是当前登录的用户，登录的时候是 0
+	Uid int64
+}
+
+// NewOAuth2Handler stateKey 只用来签 state，不要和 token 的 key 共用
+func NewOAuth2Handler(registry *oauth2.Registry, userSvc service.UserService,
+	jwtHdl *JWTHandler, stateKey []byte) *OAuth2Handler {
+	return &OAuth2Handler{
+		JWTHandler:      jwtHdl,
+		registry:        registry,
+		userSvc:         userSvc,
+		key:             stateKey,
+		stateCookieName: "oauth2-state",
+	}
+}
+
+func (h *OAuth2Handler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/oauth2")
+	g.GET("/providers", h.Providers)
+	g.GET("/:provider/authurl", h.AuthURL)
+	g.Any("/:provider/callback", h.Callback)
+	// 下面两个要登录
+	g.GET("/:provider/bind_url", h.BindURL)
+	g.POST("/:provider/unbind", h.Unbind)
+}
+
+// Providers 前端用来展示登录按钮
+func (h *OAuth2Handler) Providers(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, Result{
+		Data: h.registry.Names(),
+	})
+}
+
+func (h *OAuth2Handler) AuthURL(ctx *gin.Context) {
+	h.authURL(ctx, 0)
+}
+
+func (h *OAuth2Handler) BindURL(ctx *gin.Context) {
+	uc, ok := ctx.MustGet("user").(UserClaims)
+	if !ok {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	h.authURL(ctx, uc.Uid)
+}
+
+func (h *OAuth2Handler) authURL(ctx *gin.Context, uid int64) {
+	p, err := h.registry.Get(ctx.Param("provider"))
+	if err != nil {
+		ctx.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+	req, err := oauth2.NewAuthRequest()
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "System error",
+		})
+		return
+	}
+	val, err := p.AuthURL(ctx, req)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "System error",
+		})
+		return
+	}
+	if err = h.setStateCookie(ctx, p.Name(), req, uid); err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "System error",
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: val,
+	})
+}
+
+func (h *OAuth2Handler) Callback(ctx *gin.Context) {
+	p, err := h.registry.Get(ctx.Param("provider"))
+	if err != nil {
+		ctx.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+	sc, err := h.verifyState(ctx, p.Name())
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "illegal request",
+		})
+		return
+	}
+	identity, err := p.VerifyCode(ctx, ctx.Query("code"), sc.AuthRequest)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "authorization code error",
+		})
+		return
+	}
+
+	if sc.Uid != 0 {
+		h.bind(ctx, sc.Uid, identity)
+		return
+	}
+
+	u, err := h.userSvc.FindOrCreateByIdentity(ctx, identity)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+		return
+	}
+	err = h.SetLoginToken(ctx, u.Id, domain.SessionMethodOAuth2)
+	if err == service.ErrUserBanned {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Account banned",
+		})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Msg: "ok",
+	})
+}
+
+func (h *OAuth2Handler) bind(ctx *gin.Context, uid int64, identity domain.Identity) {
+	err := h.userSvc.BindIdentity(ctx, uid, identity)
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "ok",
+		})
+	case service.ErrDuplicateIdentity:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Account already bound to another user",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+	}
+}
+
+func (h *OAuth2Handler) Unbind(ctx *gin.Context) {
+	uc, ok := ctx.MustGet("user").(UserClaims)
+	if !ok {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	p, err := h.registry.Get(ctx.Param("provider"))
+	if err != nil {
+		ctx.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+	err = h.userSvc.UnbindIdentity(ctx, uc.Uid, p.Name())
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "OK",
+		})
+	case service.ErrLastLoginMethod:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Cannot unbind the last login method",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "System error",
+		})
+	}
+}
+
+func (h *OAuth2Handler) verifyState(ctx *gin.Context, provider string) (OAuth2StateClaims, error) {
+	ck, err := ctx.Cookie(h.stateCookieName)
+	if err != nil {
+		return OAuth2StateClaims{}, fmt.Errorf("cannot get cookie %w", err)
+	}
+	var sc OAuth2StateClaims
+	_, err = jwt.ParseWithClaims(ck, &sc, func(t *jwt.Token) (interface{}, error) {
+		return h.key, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS512.Alg()}))
+	if err != nil {
+		return OAuth2StateClaims{}, fmt.Errorf("parse token error, %w", err)
+	}
+	// 防止拿 A 平台的 state 去走 B 平台的回调
+	if sc.Provider != provider || sc.State == "" || sc.State != ctx.Query("state") {
+		return OAuth2StateClaims{}, errors.New("state not match")
+	}
+	return sc, nil
+}
+
+func (h *OAuth2Handler) setStateCookie(ctx *gin.Context, provider string,
+	req oauth2.AuthRequest, uid int64) error {
+	claims := OAuth2StateClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute * 10)),
+		},
+		Provider:    provider,
+		AuthRequest: req,
+		Uid:         uid,
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS512, claims)
+	tokenStr, err := token.SignedString(h.key)
+	if err != nil {
+		return err
+	}
+	// code_verifier 在 cookie 里面，必须 HttpOnly，只发给这个平台的回调
+	ctx.SetCookie(h.stateCookieName, tokenStr, 600,
+		"/oauth2/"+provider+"/callback", "", false, true)
+	return nil
+}
@@ -0,0 +1,132 @@
+package web
+
+import (
+	"net/http"
+	"time"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/service"
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// SessionHandler 用户查看自己在哪些设备上登录了，以及让某个设备下线
+type SessionHandler struct {
+	*JWTHandler
+	svc service.SessionService
+	l   *zap.Logger
+}
+
+func NewSessionHandler(svc service.SessionService, jwtHdl *JWTHandler, l *zap.Logger) *SessionHandler {
+	return &SessionHandler{
+		JWTHandler: jwtHdl,
+		svc:        svc,
+		l:          l,
+	}
+}
+
+func (h *SessionHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/users/sessions")
+	g.GET("", h.List)
+	g.POST("/revoke", h.Revoke)
+	g.POST("/revoke_others", h.RevokeOthers)
+}
+
+type SessionVO struct {
+	Ssid      string `json:"ssid"`
+	UserAgent string `json:"userAgent"`
+	IP        string `json:"ip"`
+	Method    string `json:"method"`
+	Ctime     string `json:"ctime"`
+	// Current 是不是发起请求的这个会话
+	Current bool `json:"current"`
+}
+
+func (h *SessionHandler) List(ctx *gin.Context) {
+	uc, ok := ctx.MustGet("user").(UserClaims)
+	if !ok {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	sessions, err := h.svc.List(ctx, uc.Uid)
+	if err != nil {
+		h.l.Error("list sessions failed", zap.Error(err), zap.Int64("uid", uc.Uid))
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "System error",
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: slice.Map(sessions, func(idx int, src domain.Session) SessionVO {
+			return SessionVO{
+				Ssid:      src.Ssid,
+				UserAgent: src.UserAgent,
+				IP:        src.IP,
+				Method:    string(src.Method),
+				Ctime:     src.Ctime.Format(time.DateTime),
+				Current:   src.Ssid == uc.Ssid,
+			}
+		}),
+	})
+}
+
+// Revoke 让某个会话下线，也可以是自己
+func (h *SessionHandler) Revoke(ctx *gin.Context) {
+	type Req struct {
+		Ssid string `json:"ssid"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	uc, ok := ctx.MustGet("user").(UserClaims)
+	if !ok {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	err := h.svc.Remove(ctx, uc.Uid, req.Ssid)
+	if err == service.ErrSessionNotFound {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Session not found",
+		})
+		return
+	}
+	if err == nil {
+		err = h.RevokeSession(ctx, req.Ssid)
+	}
+	if err != nil {
+		h.l.Error("revoke session failed", zap.Error(err), zap.Int64("uid", uc.Uid))
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "System error",
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Msg: "OK",
+	})
+}
+
+// RevokeOthers 除了当前会话，其余全部下线
+func (h *SessionHandler) RevokeOthers(ctx *gin.Context) {
+	uc, ok := ctx.MustGet("user").(UserClaims)
+	if !ok {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	err := h.RevokeOtherSessions(ctx, uc.Uid, uc.Ssid)
+	if err != nil {
+		h.l.Error("revoke other sessions failed", zap.Error(err), zap.Int64("uid", uc.Uid))
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "System error",
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Msg: "OK",
+	})
+}
@@ -0,0 +1,93 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gitee.com/geekbang/basic-go/webook/internal/repository/cache/redismocks"
+	"gitee.com/geekbang/basic-go/webook/internal/service"
+	svcmocks "gitee.com/geekbang/basic-go/webook/internal/service/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+func TestSessionHandler_Revoke(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) (service.SessionService, redis.Cmdable)
+		reqBody  string
+		wantCode int
+		wantRes  Result
+	}{
+		{
+			name: "revoked",
+			mock: func(ctrl *gomock.Controller) (service.SessionService, redis.Cmdable) {
+				svc := svcmocks.NewMockSessionService(ctrl)
+				svc.EXPECT().Remove(gomock.Any(), int64(123), "ssid-2").Return(nil)
+				cmd := redismocks.NewMockCmdable(ctrl)
+				// 记到 Redis 里面，那个设备的 token 马上失效
+				cmd.EXPECT().Set(gomock.Any(), "users:ssid:ssid-2", "", time.Hour*24*7).
+					Return(redis.NewStatusResult("OK", nil))
+				return svc, cmd
+			},
+			reqBody:  `{"ssid":"ssid-2"}`,
+			wantCode: http.StatusOK,
+			wantRes: Result{
+				Msg: "OK",
+			},
+		},
+		{
+			name: "someone else's session",
+			mock: func(ctrl *gomock.Controller) (service.SessionService, redis.Cmdable) {
+				svc := svcmocks.NewMockSessionService(ctrl)
+				svc.EXPECT().Remove(gomock.Any(), int64(123), "ssid-3").
+					Return(service.ErrSessionNotFound)
+				return svc, redismocks.NewMockCmdable(ctrl)
+			},
+			reqBody:  `{"ssid":"ssid-3"}`,
+			wantCode: http.StatusOK,
+			wantRes: Result{
+				Code: 4,
+				Msg:  "Session not found",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, cmd := tc.mock(ctrl)
+			hdl := NewSessionHandler(svc, newTestJWTHandler(cmd, svc, false), zap.NewNop())
+
+			server := gin.Default()
+			server.Use(func(ctx *gin.Context) {
+				ctx.Set("user", UserClaims{
+					Uid:  123,
+					Ssid: "ssid-1",
+				})
+			})
+			hdl.RegisterRoutes(server)
+
+			req, err := http.NewRequest(http.MethodPost,
+				"/users/sessions/revoke", bytes.NewReader([]byte(tc.reqBody)))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tc.wantCode, recorder.Code)
+			var res Result
+			err = json.NewDecoder(recorder.Body).Decode(&res)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantRes, res)
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/service"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// TwoFactorHandler 绑定 TOTP 认证器，以及开启两步验证之后的第二步登录
+type TwoFactorHandler struct {
+	*JWTHandler
+	svc           service.TwoFactorService
+	userSvc       service.UserService
+	loginGuardSvc service.LoginGuardService
+	l             *zap.Logger
+}
+
+func NewTwoFactorHandler(svc service.TwoFactorService, userSvc service.UserService,
+	loginGuardSvc service.LoginGuardService, jwtHdl *JWTHandler, l *zap.Logger) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		JWTHandler:    jwtHdl,
+		svc:           svc,
+		userSvc:       userSvc,
+		loginGuardSvc: loginGuardSvc,
+		l:             l,
+	}
+}
+
+func (h *TwoFactorHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/users")
+	g.POST("/2fa/enroll", h.Enroll)
+	g.POST("/2fa/confirm", h.Confirm)
+	g.POST("/login_2fa", h.Login)
+}
+
+func (h *TwoFactorHandler) Enroll(ctx *gin.Context) {
+	uc, ok := ctx.MustGet("user").(UserClaims)
+	if !ok {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	u, err := h.userSvc.FindById(ctx, uc.Uid)
+	if err != nil {
+		h.l.Error("find user failed", zap.Error(err), zap.Int64("uid", uc.Uid))
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "System error",
+		})
+		return
+	}
+	secret, uri, err := h.svc.Enroll(ctx, uc.Uid, accountName(u))
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Data: map[string]string{
+				"secret": secret,
+				"uri":    uri,
+			},
+		})
+	case service.ErrTwoFactorEnabled:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Two-factor authentication already enabled",
+		})
+	default:
+		h.l.Error("enroll two factor failed", zap.Error(err), zap.Int64("uid", uc.Uid))
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "System error",
+		})
+	}
+}
+
+// accountName 认证器里面显示的账号名
+func accountName(u domain.User) string {
+	switch {
+	case u.Email != "":
+		return u.Email
+	case u.Phone != "":
+		return u.Phone
+	default:
+		return fmt.Sprintf("user-%d", u.Id)
+	}
+}
+
+// Confirm 用认证器上的第一个 code 确认，恢复码只在这里返回一次
+func (h *TwoFactorHandler) Confirm(ctx *gin.Context) {
+	type Req struct {
+		Code string `json:"code"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	uc, ok := ctx.MustGet("user").(UserClaims)
+	if !ok {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	codes, err := h.svc.Confirm(ctx, uc.Uid, req.Code)
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Data: map[string]any{
+				"recoveryCodes": codes,
+			},
+		})
+	case service.ErrInvalidTwoFactorCode:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Wrong code",
+		})
+	case service.ErrTwoFactorNotEnrolled:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Please enroll first",
+		})
+	case service.ErrTwoFactorEnabled:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Two-factor authentication already enabled",
+		})
+	default:
+		h.l.Error("confirm two factor failed", zap.Error(err), zap.Int64("uid", uc.Uid))
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "System error",
+		})
+	}
+}
+
+// Login 用密码登录返回的挑战，加上 TOTP 或者恢复码，换真正的 token。
+// 和密码登录用同一套登录失败次数，锁定之后拿着之前的挑战也不能再猜 code
+func (h *TwoFactorHandler) Login(ctx *gin.Context) {
+	type Req struct {
+		ChallengeToken string `json:"challengeToken"`
+		Code           string `json:"code"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	uid, err := h.svc.ChallengeUid(ctx, req.ChallengeToken)
+	switch err {
+	case nil:
+	case service.ErrChallengeNotFound:
+		// 挑战过期了或者试错太多次，要重新输密码
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Challenge expired, please login again",
+		})
+		return
+	default:
+		h.l.Error("find two factor challenge failed", zap.Error(err))
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "System error",
+		})
+		return
+	}
+	// 登录失败次数是按邮箱记的，挑战里面只有 uid
+	u, err := h.userSvc.FindById(ctx, uid)
+	if err != nil {
+		h.l.Error("find user for login guard failed", zap.Error(err), zap.Int64("uid", uid))
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "System error",
+		})
+		return
+	}
+	ip := ctx.ClientIP()
+	wait, err := h.loginGuardSvc.Check(ctx, u.Email, ip)
+	switch err {
+	case nil:
+	case service.ErrLoginLocked, service.ErrLoginTooFrequent:
+		loginThrottled(ctx, wait)
+		return
+	default:
+		// Redis 出问题的时候不拦着登录
+		h.l.Error("check login guard failed", zap.Error(err), zap.Int64("uid", uid))
+	}
+	uid, err = h.svc.VerifyChallenge(ctx, req.ChallengeToken, req.Code)
+	switch err {
+	case nil:
+		if err = h.loginGuardSvc.Succeed(ctx, u.Email, ip); err != nil {
+			h.l.Error("reset login guard failed", zap.Error(err), zap.Int64("uid", uid))
+		}
+	case service.ErrChallengeNotFound:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Challenge expired, please login again",
+		})
+		return
+	case service.ErrInvalidTwoFactorCode:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Wrong code",
+		})
+		return
+	case service.ErrTwoFactorNotEnrolled:
+		// 拿到挑战之后两步验证被关掉了，重新登录就行
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Two-factor authentication not enabled, please login again",
+		})
+		return
+	default:
+		h.l.Error("verify two factor challenge failed", zap.Error(err))
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "System error",
+		})
+		return
+	}
+	err = h.SetLoginToken(ctx, uid, domain.SessionMethodPassword)
+	if err == service.ErrUserBanned {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Account banned",
+		})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "System error",
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Msg: "OK",
+	})
+}
@@ -0,0 +1,130 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/service"
+	svcmocks "gitee.com/geekbang/basic-go/webook/internal/service/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+func TestTwoFactorHandler_Login(t *testing.T) {
+	testCases := []struct {
+		name      string
+		mock      func(ctrl *gomock.Controller) (service.TwoFactorService, service.UserService, service.LoginGuardService)
+		wantCode  int
+		wantRetry string
+		wantRes   Result
+	}{
+		{
+			name: "wrong code",
+			mock: func(ctrl *gomock.Controller) (service.TwoFactorService, service.UserService, service.LoginGuardService) {
+				tfSvc := svcmocks.NewMockTwoFactorService(ctrl)
+				tfSvc.EXPECT().ChallengeUid(gomock.Any(), "challenge").Return(int64(123), nil)
+				tfSvc.EXPECT().VerifyChallenge(gomock.Any(), "challenge", "123456").
+					Return(int64(0), service.ErrInvalidTwoFactorCode)
+				userSvc := svcmocks.NewMockUserService(ctrl)
+				userSvc.EXPECT().FindById(gomock.Any(), int64(123)).
+					Return(domain.User{Id: 123, Email: "123@qq.com"}, nil)
+				// 失败在 Check 的时候已经记过了
+				guard := svcmocks.NewMockLoginGuardService(ctrl)
+				guard.EXPECT().Check(gomock.Any(), "123@qq.com", gomock.Any()).Return(time.Duration(0), nil)
+				return tfSvc, userSvc, guard
+			},
+			wantCode: http.StatusOK,
+			wantRes: Result{
+				Code: 4,
+				Msg:  "Wrong code",
+			},
+		},
+		{
+			name: "locked",
+			mock: func(ctrl *gomock.Controller) (service.TwoFactorService, service.UserService, service.LoginGuardService) {
+				tfSvc := svcmocks.NewMockTwoFactorService(ctrl)
+				tfSvc.EXPECT().ChallengeUid(gomock.Any(), "challenge").Return(int64(123), nil)
+				// 锁定期间根本不校验 code
+				tfSvc.EXPECT().VerifyChallenge(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				userSvc := svcmocks.NewMockUserService(ctrl)
+				userSvc.EXPECT().FindById(gomock.Any(), int64(123)).
+					Return(domain.User{Id: 123, Email: "123@qq.com"}, nil)
+				guard := svcmocks.NewMockLoginGuardService(ctrl)
+				guard.EXPECT().Check(gomock.Any(), "123@qq.com", gomock.Any()).
+					Return(time.Minute*15, service.ErrLoginLocked)
+				return tfSvc, userSvc, guard
+			},
+			wantCode:  http.StatusTooManyRequests,
+			wantRetry: "900",
+		},
+		{
+			name: "challenge expired",
+			mock: func(ctrl *gomock.Controller) (service.TwoFactorService, service.UserService, service.LoginGuardService) {
+				tfSvc := svcmocks.NewMockTwoFactorService(ctrl)
+				tfSvc.EXPECT().ChallengeUid(gomock.Any(), "challenge").
+					Return(int64(0), service.ErrChallengeNotFound)
+				return tfSvc, svcmocks.NewMockUserService(ctrl), svcmocks.NewMockLoginGuardService(ctrl)
+			},
+			wantCode: http.StatusOK,
+			wantRes: Result{
+				Code: 4,
+				Msg:  "Challenge expired, please login again",
+			},
+		},
+		{
+			name: "not enrolled",
+			mock: func(ctrl *gomock.Controller) (service.TwoFactorService, service.UserService, service.LoginGuardService) {
+				tfSvc := svcmocks.NewMockTwoFactorService(ctrl)
+				tfSvc.EXPECT().ChallengeUid(gomock.Any(), "challenge").Return(int64(123), nil)
+				tfSvc.EXPECT().VerifyChallenge(gomock.Any(), "challenge", "123456").
+					Return(int64(0), service.ErrTwoFactorNotEnrolled)
+				userSvc := svcmocks.NewMockUserService(ctrl)
+				userSvc.EXPECT().FindById(gomock.Any(), int64(123)).
+					Return(domain.User{Id: 123, Email: "123@qq.com"}, nil)
+				guard := svcmocks.NewMockLoginGuardService(ctrl)
+				guard.EXPECT().Check(gomock.Any(), "123@qq.com", gomock.Any()).Return(time.Duration(0), nil)
+				return tfSvc, userSvc, guard
+			},
+			wantCode: http.StatusOK,
+			wantRes: Result{
+				Code: 4,
+				Msg:  "Two-factor authentication not enabled, please login again",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			tfSvc, userSvc, guard := tc.mock(ctrl)
+			hdl := NewTwoFactorHandler(tfSvc, userSvc, guard, nil, zap.NewNop())
+			server := gin.Default()
+			hdl.RegisterRoutes(server)
+
+			req, err := http.NewRequest(http.MethodPost, "/users/login_2fa",
+				bytes.NewReader([]byte(`{"challengeToken":"challenge","code":"123456"}`)))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tc.wantCode, recorder.Code)
+			assert.Equal(t, tc.wantRetry, recorder.Header().Get("Retry-After"))
+			if tc.wantCode != http.StatusOK {
+				return
+			}
+			var res Result
+			err = json.NewDecoder(recorder.Body).Decode(&res)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantRes, res)
+		})
+	}
+}
@@ -2,7 +2,9 @@ package web
 
 import (
 	"log"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	"gitee.com/geekbang/basic-go/webook/internal/domain"
@@ -11,30 +13,40 @@ import (
 
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
-
 )
 
 const (
 	emailRegexPattern    = `^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`
 	passwordRegexPattern = `^.{8,}$`
 	bizLogin             = "Login"
+	bizResetPassword     = "reset_pwd"
+	bizChangeEmail       = "change_email"
+	bizChangePhone       = "change_phone"
+	bizVerifyEmail       = "verify_email"
 )
 
 type UserHandler struct {
-	jwtHandler
+	*JWTHandler
 	emailRexExp    *regexp.Regexp
 	passwordRexExp *regexp.Regexp
 	svc            service.UserService
 	codeSvc        service.CodeService
+	twoFactorSvc   service.TwoFactorService
+	loginGuardSvc  service.LoginGuardService
 }
 
 
-func NewUserHandler(svc service.UserService, codeSvc service.CodeService) *UserHandler {
+func NewUserHandler(svc service.UserService, codeSvc service.CodeService,
+	twoFactorSvc service.TwoFactorService, loginGuardSvc service.LoginGuardService,
+	jwtHdl *JWTHandler) *UserHandler {
 	return &UserHandler{
+		JWTHandler:     jwtHdl,
 		emailRexExp:    regexp.MustCompile(emailRegexPattern, regexp.None),
 		passwordRexExp: regexp.MustCompile(passwordRegexPattern, regexp.None),
 		svc:            svc,
 		codeSvc:        codeSvc,
+		twoFactorSvc:   twoFactorSvc,
+		loginGuardSvc:  loginGuardSvc,
 	}
 }
 
@@ -47,6 +59,507 @@ func (h *UserHandler) RegisterRoutes(server *gin.Engine) {
 	ug.POST("/edit", h.Edit)
 	ug.POST("/login_sms/code/send", h.SendSMSLoginCode)
 	ug.POST("/login_sms", h.LoginSMS)
+	ug.POST("/refresh_token", h.RefreshToken)
+	ug.POST("/logout", h.Logout)
+	ug.POST("/reset_pwd/code/send", h.SendResetPasswordCode)
+	ug.POST("/reset_pwd", h.ResetPassword)
+	ug.POST("/change_pwd", h.ChangePassword)
+	ug.POST("/change_email/code/send", h.SendChangeEmailCode)
+	ug.POST("/change_email", h.ChangeEmail)
+	ug.POST("/change_phone/code/send", h.SendChangePhoneCode)
+	ug.POST("/change_phone", h.ChangePhone)
+	// 没有绑定过的时候，改邮箱、改手机号就是绑定
+	ug.POST("/unbind_email", h.UnbindEmail)
+	ug.POST("/unbind_phone", h.UnbindPhone)
+	ug.POST("/verify_email/code/send", h.SendVerifyEmailCode)
+	ug.POST("/verify_email", h.VerifyEmail)
+
+	server.GET("/.well-known/jwks.json", h.JWKS)
+}
+
+func (h *UserHandler) Logout(ctx *gin.Context) {
+	if err := h.ClearToken(ctx); err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "System error",
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Msg: "OK",
+	})
+}
+
+// RefreshToken 用 Authorization 里面的 refresh token 换一个新的 access token，会话不变
+func (h *UserHandler) RefreshToken(ctx *gin.Context) {
+	rc, err := h.ParseRefreshToken(ExtractToken(ctx))
+	if err != nil {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	// 退出登录之后 refresh token 也不能用了
+	if err = h.CheckSession(ctx, rc.Ssid); err != nil {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	err = h.SetJWTToken(ctx, rc.Uid, rc.Ssid)
+	if err == service.ErrUserBanned {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "System error",
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Msg: "OK",
+	})
+}
+
+// SendResetPasswordCode 忘记密码，给手机号或者邮箱发验证码，两个都填了的时候用手机号。
+// 不管账号存不存在、是不是发送太频繁都返回一样的结果，避免被拿来探测账号
+func (h *UserHandler) SendResetPasswordCode(ctx *gin.Context) {
+	type Req struct {
+		Phone string `json:"phone"`
+		Email string `json:"email"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	channel, target := resetPasswordTarget(req.Phone, req.Email)
+	if target == "" {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Please input phone number or email",
+		})
+		return
+	}
+	var err error
+	if channel == domain.CodeChannelPhone {
+		_, err = h.svc.FindByPhone(ctx, target)
+	} else {
+		_, err = h.svc.FindByEmail(ctx, target)
+	}
+	switch err {
+	case nil:
+		err = h.codeSvc.Send(ctx, channel, bizResetPassword, target)
+		if err != nil {
+			log.Println("send reset password code error", err)
+		}
+	case service.ErrUserNotFound:
+	default:
+		log.Println("find user error", err)
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Msg: "If the account exists, a code has been sent",
+	})
+}
+
+func resetPasswordTarget(phone string, email string) (domain.CodeChannel, string) {
+	if phone != "" {
+		return domain.CodeChannelPhone, phone
+	}
+	return domain.CodeChannelEmail, email
+}
+
+// ResetPassword 校验验证码之后设置新密码，所有设备都要重新登录
+func (h *UserHandler) ResetPassword(ctx *gin.Context) {
+	type Req struct {
+		Phone           string `json:"phone"`
+		Email           string `json:"email"`
+		Code            string `json:"code"`
+		Password        string `json:"password"`
+		ConfirmPassword string `json:"confirmPassword"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	if req.Password != req.ConfirmPassword {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Passwords do not match",
+		})
+		return
+	}
+	isPassword, err := h.passwordRexExp.MatchString(req.Password)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "System error",
+		})
+		return
+	}
+	if !isPassword {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Password must be at least 8 characters",
+		})
+		return
+	}
+	channel, target := resetPasswordTarget(req.Phone, req.Email)
+	ok, err := h.codeSvc.Verify(ctx, channel, bizResetPassword, target, req.Code)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "System error",
+		})
+		return
+	}
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Wrong code",
+		})
+		return
+	}
+	uid, err := h.svc.ResetPassword(ctx, channel, target, req.Password)
+	if err == service.ErrUserNotFound {
+		// 验证码只发给存在的账号，走到这里说明账号刚被删掉了
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Wrong code",
+		})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "System error",
+		})
+		return
+	}
+	// 密码可能已经泄露了，之前的会话全部下线
+	if err = h.RevokeOtherSessions(ctx, uid, ""); err != nil {
+		log.Println("revoke sessions after reset password error", err)
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Msg: "OK",
+	})
+}
+
+// ChangePassword 登录之后用旧密码改密码，其他设备都要重新登录
+func (h *UserHandler) ChangePassword(ctx *gin.Context) {
+	type Req struct {
+		OldPassword     string `json:"oldPassword"`
+		Password        string `json:"password"`
+		ConfirmPassword string `json:"confirmPassword"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	uc, ok := ctx.MustGet("user").(UserClaims)
+	if !ok {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	if req.Password != req.ConfirmPassword {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Passwords do not match",
+		})
+		return
+	}
+	isPassword, err := h.passwordRexExp.MatchString(req.Password)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "System error",
+		})
+		return
+	}
+	if !isPassword {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Password must be at least 8 characters",
+		})
+		return
+	}
+	err = h.svc.ChangePassword(ctx, uc.Uid, req.OldPassword, req.Password)
+	switch err {
+	case nil:
+	case service.ErrInvalidUserOrPassword:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Wrong password",
+		})
+		return
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "System error",
+		})
+		return
+	}
+	if err = h.RevokeOtherSessions(ctx, uc.Uid, uc.Ssid); err != nil {
+		log.Println("revoke sessions after change password error", err)
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Msg: "OK",
+	})
+}
+
+// SendChangeEmailCode 验证码发到新邮箱，证明新邮箱是自己的
+func (h *UserHandler) SendChangeEmailCode(ctx *gin.Context) {
+	type Req struct {
+		Email string `json:"email"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	isEmail, err := h.emailRexExp.MatchString(req.Email)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "System error",
+		})
+		return
+	}
+	if !isEmail {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Illegal email",
+		})
+		return
+	}
+	h.sendCode(ctx, domain.CodeChannelEmail, bizChangeEmail, req.Email)
+}
+
+func (h *UserHandler) ChangeEmail(ctx *gin.Context) {
+	type Req struct {
+		Email string `json:"email"`
+		Code  string `json:"code"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	uc, ok := ctx.MustGet("user").(UserClaims)
+	if !ok {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	if !h.verifyCode(ctx, domain.CodeChannelEmail, bizChangeEmail, req.Email, req.Code) {
+		return
+	}
+	err := h.svc.ChangeEmail(ctx, uc.Uid, req.Email)
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "OK",
+		})
+	case service.ErrDuplicateEmail:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Email already bound to another account",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "System error",
+		})
+	}
+}
+
+// SendVerifyEmailCode 重发注册邮箱的验证码，不用登录。
+// 和找回密码一样，账号存不存在、验证过没有都返回一样的结果
+func (h *UserHandler) SendVerifyEmailCode(ctx *gin.Context) {
+	type Req struct {
+		Email string `json:"email"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	u, err := h.svc.FindByEmail(ctx, req.Email)
+	switch err {
+	case nil:
+		if u.EmailVerified {
+			break
+		}
+		err = h.codeSvc.Send(ctx, domain.CodeChannelEmail, bizVerifyEmail, req.Email)
+		if err != nil {
+			log.Println("send verify email code error", err)
+		}
+	case service.ErrUserNotFound:
+	default:
+		log.Println("find user by email error", err)
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Msg: "If the account exists, a code has been sent",
+	})
+}
+
+// VerifyEmail 用注册时发到邮箱的验证码确认邮箱，不用登录
+func (h *UserHandler) VerifyEmail(ctx *gin.Context) {
+	type Req struct {
+		Email string `json:"email"`
+		Code  string `json:"code"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	if !h.verifyCode(ctx, domain.CodeChannelEmail, bizVerifyEmail, req.Email, req.Code) {
+		return
+	}
+	err := h.svc.ConfirmEmail(ctx, req.Email)
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "OK",
+		})
+	case service.ErrUserNotFound:
+		// 验证码发出去之后邮箱被改掉了
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Wrong code",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "System error",
+		})
+	}
+}
+
+// SendChangePhoneCode 验证码发到新手机号
+func (h *UserHandler) SendChangePhoneCode(ctx *gin.Context) {
+	type Req struct {
+		Phone string `json:"phone"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	if req.Phone == "" {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Please input phone number",
+		})
+		return
+	}
+	h.sendCode(ctx, domain.CodeChannelPhone, bizChangePhone, req.Phone)
+}
+
+func (h *UserHandler) ChangePhone(ctx *gin.Context) {
+	type Req struct {
+		Phone string `json:"phone"`
+		Code  string `json:"code"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	uc, ok := ctx.MustGet("user").(UserClaims)
+	if !ok {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	if !h.verifyCode(ctx, domain.CodeChannelPhone, bizChangePhone, req.Phone, req.Code) {
+		return
+	}
+	err := h.svc.ChangePhone(ctx, uc.Uid, req.Phone)
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "OK",
+		})
+	case service.ErrDuplicatePhone:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Phone number already bound to another account",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "System error",
+		})
+	}
+}
+
+func (h *UserHandler) UnbindEmail(ctx *gin.Context) {
+	uc, ok := ctx.MustGet("user").(UserClaims)
+	if !ok {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	h.unbindResult(ctx, h.svc.UnbindEmail(ctx, uc.Uid))
+}
+
+func (h *UserHandler) UnbindPhone(ctx *gin.Context) {
+	uc, ok := ctx.MustGet("user").(UserClaims)
+	if !ok {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	h.unbindResult(ctx, h.svc.UnbindPhone(ctx, uc.Uid))
+}
+
+func (h *UserHandler) unbindResult(ctx *gin.Context, err error) {
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "OK",
+		})
+	case service.ErrLastLoginMethod:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Cannot unbind the last login method",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "System error",
+		})
+	}
+}
+
+func (h *UserHandler) sendCode(ctx *gin.Context, channel domain.CodeChannel, biz string, target string) {
+	err := h.codeSvc.Send(ctx, channel, biz, target)
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "Successfully send the code",
+		})
+	case service.ErrCodeSendTooMany:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Send too many",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "System error",
+		})
+	}
+}
+
+// verifyCode 验证码不对的时候已经写好了响应，调用方直接返回
+func (h *UserHandler) verifyCode(ctx *gin.Context, channel domain.CodeChannel,
+	biz string, target string, code string) bool {
+	ok, err := h.codeSvc.Verify(ctx, channel, biz, target, code)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "System error",
+		})
+		return false
+	}
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Wrong code",
+		})
+		return false
+	}
+	return true
 }
 
 func (h *UserHandler) SendSMSLoginCode(ctx *gin.Context) {
@@ -64,7 +577,7 @@ func (h *UserHandler) SendSMSLoginCode(ctx *gin.Context) {
 		})
 	}
 	//log.Println(bizLogin, req.Phone)
-	err := h.codeSvc.Send(ctx, bizLogin, req.Phone)
+	err := h.codeSvc.Send(ctx, domain.CodeChannelPhone, bizLogin, req.Phone)
 	//log.Println(err)
 	switch err {
 	case nil:
@@ -98,7 +611,7 @@ func (h *UserHandler) LoginSMS(ctx *gin.Context) {
 		return
 	}
 
-	ok, err := h.codeSvc.Verify(ctx, bizLogin, req.Phone, req.Code)
+	ok, err := h.codeSvc.Verify(ctx, domain.CodeChannelPhone, bizLogin, req.Phone, req.Code)
 	if err != nil {
 		ctx.JSON(http.StatusOK, Result{
 			Code: 5,
@@ -123,7 +636,21 @@ func (h *UserHandler) LoginSMS(ctx *gin.Context) {
 		})
 		return
 	}
-	h.SetJWTToken(ctx, u.Id)
+	err = h.SetLoginToken(ctx, u.Id, domain.SessionMethodSMS)
+	if err == service.ErrUserBanned {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Account banned",
+		})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "System error",
+		})
+		return
+	}
 	ctx.JSON(http.StatusOK, Result{
 		Msg: "Successfully login",
 	})
@@ -175,6 +702,11 @@ func (h *UserHandler) SignUp(ctx *gin.Context) {
 
 	switch err {
 	case nil:
+		// 发不出去也算注册成功，用户可以再让我们重发
+		err = h.codeSvc.Send(ctx, domain.CodeChannelEmail, bizVerifyEmail, req.Email)
+		if err != nil {
+			log.Println("send verify email code error", err)
+		}
 		ctx.String(http.StatusOK, "hello, successfully signing up")
 	case service.ErrDuplicateEmail:
 		ctx.String(http.StatusOK, "Email conflict, please use a different one.")
@@ -232,14 +764,58 @@ func (h *UserHandler) LoginJWT(ctx *gin.Context) {
 	if err := ctx.Bind(&req); err != nil {
 		return
 	}
+	ip := ctx.ClientIP()
+	wait, err := h.loginGuardSvc.Check(ctx, req.Email, ip)
+	switch err {
+	case nil:
+	case service.ErrLoginLocked, service.ErrLoginTooFrequent:
+		loginThrottled(ctx, wait)
+		return
+	default:
+		// Redis 出问题的时候不拦着登录
+		log.Println("check login guard error", err)
+	}
 	user, err := h.svc.Login(ctx, req.Email, req.Password)
 	switch err {
 	case nil:
-		h.SetJWTToken(ctx, user.Id)
+		enabled, err := h.twoFactorSvc.Enabled(ctx, user.Id)
+		if err != nil {
+			ctx.String(http.StatusOK, "系统错误: %v", err)
+			return
+		}
+		if enabled {
+			// 先不发 token，拿着挑战去 /users/login_2fa。
+			// 第二步通过之前不清登录失败次数，这一次也先算失败，不然密码对了就能无限次猜 code
+			challenge, err := h.twoFactorSvc.Challenge(ctx, user.Id)
+			if err != nil {
+				ctx.String(http.StatusOK, "系统错误: %v", err)
+				return
+			}
+			ctx.JSON(http.StatusOK, Result{
+				Msg: "Two-factor authentication required",
+				Data: map[string]string{
+					"challengeToken": challenge,
+				},
+			})
+			return
+		}
+		if err = h.loginGuardSvc.Succeed(ctx, req.Email, ip); err != nil {
+			log.Println("reset login guard error", err)
+		}
+		err = h.SetLoginToken(ctx, user.Id, domain.SessionMethodPassword)
+		if err == service.ErrUserBanned {
+			ctx.String(http.StatusOK, "账号已被封禁")
+			return
+		}
+		if err != nil {
+			ctx.String(http.StatusOK, "系统错误: %v", err)
+			return
+		}
 		//log.Println("登录成功， tokenStr")
 		ctx.String(http.StatusOK, "登录成功")
 
 	case service.ErrInvalidUserOrPassword:
+		// Check 的时候已经记过这一次失败了
 		ctx.String(http.StatusOK, "用户名或者密码不对")
 	default:
 		ctx.String(http.StatusOK, "系统错误: %v", err)
@@ -247,6 +823,12 @@ func (h *UserHandler) LoginJWT(ctx *gin.Context) {
 
 }
 
+// loginThrottled 登录失败太多次，告诉前端还要等多久
+func loginThrottled(ctx *gin.Context, wait time.Duration) {
+	ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	ctx.String(http.StatusTooManyRequests, "登录失败次数太多，请稍后再试")
+}
+
 func (h *UserHandler) Profile(ctx *gin.Context) {
 
 	// userId, err := h.svc.GetUserIdFromSession(ctx)
@@ -274,15 +856,19 @@ func (h *UserHandler) Profile(ctx *gin.Context) {
 	type User struct {
 		Nickname string `json:"Nickname"`
 		Email    string `json:"Email"`
+		EmailVerified bool `json:"EmailVerified"`
 		AboutMe  string `json:"AboutMe"`
 		Birthday string `json:"Birthday"`
+		Avatar   string `json:"Avatar"`
 	}
 
 	frontUserProfile := User{
 		Nickname: u.Nickname,
 		Email:    u.Email,
+		EmailVerified: u.EmailVerified,
 		AboutMe:  u.AboutMe,
 		Birthday: u.Birthday.Format(time.DateOnly),
+		Avatar:   u.Avatar,
 	}
 
 	ctx.JSON(http.StatusOK, frontUserProfile)
@@ -7,12 +7,16 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/cache/redismocks"
 	"gitee.com/geekbang/basic-go/webook/internal/service"
 	svcmocks "gitee.com/geekbang/basic-go/webook/internal/service/mocks"
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 )
 
@@ -32,7 +36,11 @@ func TestUserHandler_SignUp(t *testing.T){
 					Email:   "123@qq.com",
 					Password: "12345678",
 				}).Return(nil)
-				return userSvc,nil
+				// 注册成功之后给邮箱发验证码
+				codeSvc := svcmocks.NewMockCodeService(ctrl)
+				codeSvc.EXPECT().Send(gomock.Any(), domain.CodeChannelEmail, "verify_email", "123@qq.com").
+					Return(nil)
+				return userSvc, wrapCodeService(codeSvc)
 			},
 			reqBuilder: func(t *testing.T) *http.Request{
 				req,err := http.NewRequest(http.MethodPost,
@@ -196,7 +204,7 @@ func TestUserHandler_SignUp(t *testing.T){
 			// before t.Run finish, it will execute finish
 			defer ctrl.Finish()
 			userSvc, codeSvc := tc.mock(ctrl)
-			hdl := NewUserHandler(userSvc, codeSvc)
+			hdl := NewUserHandler(userSvc, codeSvc, nil, nil, nil)
 
 			server := gin.Default()
 			hdl.RegisterRoutes(server)
@@ -228,3 +236,232 @@ func TestMOck(t *testing.T) {
 	})
 	t.Log(err)
 }
+
+func TestUserHandler_SendResetPasswordCode(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) (service.UserService, service.CodeService)
+		reqBody string
+	}{
+		{
+			name: "account exists",
+			mock: func(ctrl *gomock.Controller) (service.UserService, service.CodeService) {
+				userSvc := svcmocks.NewMockUserService(ctrl)
+				userSvc.EXPECT().FindByPhone(gomock.Any(), "13800000000").
+					Return(domain.User{Id: 123, Phone: "13800000000"}, nil)
+				codeSvc := svcmocks.NewMockCodeService(ctrl)
+				codeSvc.EXPECT().Send(gomock.Any(), domain.CodeChannelPhone, "reset_pwd", "13800000000").Return(nil)
+				return userSvc, wrapCodeService(codeSvc)
+			},
+			reqBody: `{"phone":"13800000000"}`,
+		},
+		{
+			name: "account not found",
+			mock: func(ctrl *gomock.Controller) (service.UserService, service.CodeService) {
+				userSvc := svcmocks.NewMockUserService(ctrl)
+				userSvc.EXPECT().FindByPhone(gomock.Any(), "13800000000").
+					Return(domain.User{}, service.ErrUserNotFound)
+				return userSvc, wrapCodeService(svcmocks.NewMockCodeService(ctrl))
+			},
+			reqBody: `{"phone":"13800000000"}`,
+		},
+		{
+			name: "account exists by email",
+			mock: func(ctrl *gomock.Controller) (service.UserService, service.CodeService) {
+				userSvc := svcmocks.NewMockUserService(ctrl)
+				userSvc.EXPECT().FindByEmail(gomock.Any(), "123@qq.com").
+					Return(domain.User{Id: 123, Email: "123@qq.com"}, nil)
+				codeSvc := svcmocks.NewMockCodeService(ctrl)
+				codeSvc.EXPECT().Send(gomock.Any(), domain.CodeChannelEmail, "reset_pwd", "123@qq.com").Return(nil)
+				return userSvc, wrapCodeService(codeSvc)
+			},
+			reqBody: `{"email":"123@qq.com"}`,
+		},
+		{
+			name: "send too many",
+			mock: func(ctrl *gomock.Controller) (service.UserService, service.CodeService) {
+				userSvc := svcmocks.NewMockUserService(ctrl)
+				userSvc.EXPECT().FindByPhone(gomock.Any(), "13800000000").
+					Return(domain.User{Id: 123, Phone: "13800000000"}, nil)
+				codeSvc := svcmocks.NewMockCodeService(ctrl)
+				codeSvc.EXPECT().Send(gomock.Any(), domain.CodeChannelPhone, "reset_pwd", "13800000000").
+					Return(service.ErrCodeSendTooMany)
+				return userSvc, wrapCodeService(codeSvc)
+			},
+			reqBody: `{"phone":"13800000000"}`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			userSvc, codeSvc := tc.mock(ctrl)
+			hdl := NewUserHandler(userSvc, codeSvc, nil, nil, nil)
+			server := gin.Default()
+			hdl.RegisterRoutes(server)
+
+			req, err := http.NewRequest(http.MethodPost, "/users/reset_pwd/code/send",
+				bytes.NewReader([]byte(tc.reqBody)))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+
+			// 不管账号在不在，返回都一样
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			assert.Equal(t, `{"code":0,"msg":"If the account exists, a code has been sent","data":null}`,
+				recorder.Body.String())
+		})
+	}
+}
+
+func TestUserHandler_ResetPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	userSvc := svcmocks.NewMockUserService(ctrl)
+	codeSvc := svcmocks.NewMockCodeService(ctrl)
+	sessionSvc := svcmocks.NewMockSessionService(ctrl)
+	cmd := redismocks.NewMockCmdable(ctrl)
+	codeSvc.EXPECT().Verify(gomock.Any(), domain.CodeChannelPhone, "reset_pwd", "13800000000", "123456").Return(true, nil)
+	userSvc.EXPECT().ResetPassword(gomock.Any(), domain.CodeChannelPhone, "13800000000", "new password").Return(int64(123), nil)
+	// 所有会话都下线
+	sessionSvc.EXPECT().RemoveOthers(gomock.Any(), int64(123), "").
+		Return([]string{"ssid-1", "ssid-2"}, nil)
+	cmd.EXPECT().Set(gomock.Any(), "users:ssid:ssid-1", "", gomock.Any()).
+		Return(redis.NewStatusResult("OK", nil))
+	cmd.EXPECT().Set(gomock.Any(), "users:ssid:ssid-2", "", gomock.Any()).
+		Return(redis.NewStatusResult("OK", nil))
+
+	hdl := NewUserHandler(userSvc, wrapCodeService(codeSvc), nil, nil, newTestJWTHandler(cmd, sessionSvc, false))
+	server := gin.Default()
+	hdl.RegisterRoutes(server)
+	req, err := http.NewRequest(http.MethodPost, "/users/reset_pwd", bytes.NewReader([]byte(
+		`{"phone":"13800000000","code":"123456","password":"new password","confirmPassword":"new password"}`)))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, `{"code":0,"msg":"OK","data":null}`, recorder.Body.String())
+}
+
+// mockCodeService CodeService 有未导出的方法，mock 没法直接实现，这里转发一下
+type mockCodeService struct {
+	service.CodeService
+	mock *svcmocks.MockCodeService
+}
+
+func wrapCodeService(mock *svcmocks.MockCodeService) service.CodeService {
+	return mockCodeService{mock: mock}
+}
+
+func (m mockCodeService) Send(ctx context.Context, channel domain.CodeChannel, biz string, target string) error {
+	return m.mock.Send(ctx, channel, biz, target)
+}
+
+func (m mockCodeService) Verify(ctx context.Context, channel domain.CodeChannel,
+	biz string, target string, inputCode string) (bool, error) {
+	return m.mock.Verify(ctx, channel, biz, target, inputCode)
+}
+
+func TestUserHandler_LoginJWT_TwoFactor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	userSvc := svcmocks.NewMockUserService(ctrl)
+	userSvc.EXPECT().Login(gomock.Any(), "123@qq.com", "12345678").
+		Return(domain.User{Id: 123, Email: "123@qq.com"}, nil)
+	twoFactorSvc := svcmocks.NewMockTwoFactorService(ctrl)
+	twoFactorSvc.EXPECT().Enabled(gomock.Any(), int64(123)).Return(true, nil)
+	twoFactorSvc.EXPECT().Challenge(gomock.Any(), int64(123)).Return("challenge", nil)
+	loginGuardSvc := svcmocks.NewMockLoginGuardService(ctrl)
+	loginGuardSvc.EXPECT().Check(gomock.Any(), "123@qq.com", gomock.Any()).Return(time.Duration(0), nil)
+
+	// 开了两步验证，只返回挑战，不发 token，也不清登录失败次数
+	hdl := NewUserHandler(userSvc, nil, twoFactorSvc, loginGuardSvc, nil)
+	server := gin.Default()
+	hdl.RegisterRoutes(server)
+	req, err := http.NewRequest(http.MethodPost, "/users/login",
+		bytes.NewReader([]byte(`{"email":"123@qq.com","password":"12345678"}`)))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Empty(t, recorder.Header().Get("x-jwt-token"))
+	assert.Equal(t, `{"code":0,"msg":"Two-factor authentication required","data":{"challengeToken":"challenge"}}`,
+		recorder.Body.String())
+}
+
+func TestUserHandler_LoginJWT_Guard(t *testing.T) {
+	testCases := []struct {
+		name      string
+		mock      func(ctrl *gomock.Controller) (service.UserService, service.LoginGuardService)
+		wantCode  int
+		wantRetry string
+		wantBody  string
+	}{
+		{
+			name: "locked",
+			mock: func(ctrl *gomock.Controller) (service.UserService, service.LoginGuardService) {
+				guard := svcmocks.NewMockLoginGuardService(ctrl)
+				guard.EXPECT().Check(gomock.Any(), "123@qq.com", gomock.Any()).
+					Return(time.Minute*15, service.ErrLoginLocked)
+				// 锁定期间根本不校验密码
+				return svcmocks.NewMockUserService(ctrl), guard
+			},
+			wantCode:  http.StatusTooManyRequests,
+			wantRetry: "900",
+			wantBody:  "登录失败次数太多，请稍后再试",
+		},
+		{
+			name: "wrong password",
+			mock: func(ctrl *gomock.Controller) (service.UserService, service.LoginGuardService) {
+				userSvc := svcmocks.NewMockUserService(ctrl)
+				userSvc.EXPECT().Login(gomock.Any(), "123@qq.com", "12345678").
+					Return(domain.User{}, service.ErrInvalidUserOrPassword)
+				guard := svcmocks.NewMockLoginGuardService(ctrl)
+				// 失败在 Check 的时候已经记过了
+				guard.EXPECT().Check(gomock.Any(), "123@qq.com", gomock.Any()).Return(time.Duration(0), nil)
+				return userSvc, guard
+			},
+			wantCode: http.StatusOK,
+			wantBody: "用户名或者密码不对",
+		},
+		{
+			name: "redis down",
+			mock: func(ctrl *gomock.Controller) (service.UserService, service.LoginGuardService) {
+				userSvc := svcmocks.NewMockUserService(ctrl)
+				userSvc.EXPECT().Login(gomock.Any(), "123@qq.com", "12345678").
+					Return(domain.User{}, service.ErrInvalidUserOrPassword)
+				guard := svcmocks.NewMockLoginGuardService(ctrl)
+				guard.EXPECT().Check(gomock.Any(), "123@qq.com", gomock.Any()).
+					Return(time.Duration(0), errors.New("redis down"))
+				return userSvc, guard
+			},
+			wantCode: http.StatusOK,
+			wantBody: "用户名或者密码不对",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			userSvc, guard := tc.mock(ctrl)
+			hdl := NewUserHandler(userSvc, nil, nil, guard, nil)
+			server := gin.Default()
+			hdl.RegisterRoutes(server)
+			req, err := http.NewRequest(http.MethodPost, "/users/login",
+				bytes.NewReader([]byte(`{"email":"123@qq.com","password":"12345678"}`)))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tc.wantCode, recorder.Code)
+			assert.Equal(t, tc.wantRetry, recorder.Header().Get("Retry-After"))
+			assert.Equal(t, tc.wantBody, recorder.Body.String())
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package web
+
+import (
+	"time"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+)
+
+type ArticleVO struct {
+	Id       int64  `json:"id"`
+	Title    string `json:"title"`
+	Content  string `json:"content"`
+	Abstract string `json:"abstract"`
+	AuthorId int64  `json:"authorId"`
+	Status   uint8  `json:"status"`
+	Ctime    string `json:"ctime"`
+	Utime    string `json:"utime"`
+
+	ReadCnt    int64 `json:"readCnt"`
+	LikeCnt    int64 `json:"likeCnt"`
+	CollectCnt int64 `json:"collectCnt"`
+	Liked      bool  `json:"liked"`
+	Collected  bool  `json:"collected"`
+}
+
+func toArticleVO(art domain.Article) ArticleVO {
+	return ArticleVO{
+		Id:       art.Id,
+		Title:    art.Title,
+		Content:  art.Content,
+		Abstract: art.Abstract(),
+		AuthorId: art.Author.Id,
+		Status:   art.Status.ToUint8(),
+		Ctime:    art.Ctime.Format(time.DateTime),
+		Utime:    art.Utime.Format(time.DateTime),
+	}
+}
+
+// toArticleListVO 列表页不返回全文，只返回摘要
+func toArticleListVO(art domain.Article) ArticleVO {
+	vo := toArticleVO(art)
+	vo.Content = ""
+	return vo
+}
+
+func toPubArticleVO(art domain.Article, intr domain.Interactive) ArticleVO {
+	vo := toArticleVO(art)
+	vo.ReadCnt = intr.ReadCnt
+	vo.LikeCnt = intr.LikeCnt
+	vo.CollectCnt = intr.CollectCnt
+	vo.Liked = intr.Liked
+	vo.Collected = intr.Collected
+	return vo
+}
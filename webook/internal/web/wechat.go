@@ -3,7 +3,10 @@ package web
 import (
 	"fmt"
 	"net/http"
+	"net/url"
+	"time"
 
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
 	"gitee.com/geekbang/basic-go/webook/internal/service"
 	"gitee.com/geekbang/basic-go/webook/internal/service/oauth2/wechat"
 	"github.com/gin-gonic/gin"
@@ -11,53 +14,102 @@ import (
 	uuid "github.com/lithammer/shortuuid/v4"
 )
 
-
-type OAuth2WechatHandler struct{
-	jwtHandler
-	svc wechat.Service
-	userSvc service.UserService
-	key []byte
+type OAuth2WechatHandler struct {
+	*JWTHandler
+	svc             wechat.Service
+	accountSvc      service.WechatAccountService
+	key             []byte
 	stateCookieName string
+	// frontendURL 不为空的时候，回调处理完之后把浏览器重定向回前端，
+	// 结果放在 fragment 里面，避免 token 出现在服务端日志和 Referer 里
+	frontendURL string
 }
 
-type StateClaims struct{
+type StateClaims struct {
 	jwt.RegisteredClaims
 	State string
+	// Uid 绑定微信的时候是当前登录的用户，微信登录的时候是 0
+	Uid int64
 }
 
-func NewOAuth2WechatHandler(svc wechat.Service, userSvc service.UserService) *OAuth2WechatHandler{
+// NewOAuth2WechatHandler stateKey 只用来签 state，不要和 token 的 key 共用
+func NewOAuth2WechatHandler(svc wechat.Service, accountSvc service.WechatAccountService,
+	jwtHdl *JWTHandler, stateKey []byte, frontendURL string) *OAuth2WechatHandler {
 	return &OAuth2WechatHandler{
-		svc: svc,
-		userSvc: userSvc,
-		key: []byte("jYe8vbdGFD7RRnIf8W7KArU2ehZJbbn8"),
+		JWTHandler:      jwtHdl,
+		svc:             svc,
+		accountSvc:      accountSvc,
+		key:             stateKey,
 		stateCookieName: "jwt-state",
+		frontendURL:     frontendURL,
 	}
 }
 
-func (o *OAuth2WechatHandler) RegisterRoutes(server *gin.Engine){
+func (o *OAuth2WechatHandler) RegisterRoutes(server *gin.Engine) {
 	g := server.Group("/oauth2/wechat")
 	g.GET("/authurl", o.Auth2URL)
 	g.Any("/callback", o.Callback)
+	// 下面两个要登录
+	g.GET("/bind_url", o.BindURL)
+	g.POST("/unbind", o.Unbind)
 }
 
-func (o *OAuth2WechatHandler) Auth2URL(ctx *gin.Context){
+func (o *OAuth2WechatHandler) Auth2URL(ctx *gin.Context) {
+	o.authURL(ctx, 0)
+}
 
+// BindURL 已经登录的用户绑定微信，回调的时候根据 state 里面的 uid 绑定，而不是登录
+func (o *OAuth2WechatHandler) BindURL(ctx *gin.Context) {
+	uc, ok := ctx.MustGet("user").(UserClaims)
+	if !ok {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	o.authURL(ctx, uc.Uid)
+}
+
+func (o *OAuth2WechatHandler) Unbind(ctx *gin.Context) {
+	uc, ok := ctx.MustGet("user").(UserClaims)
+	if !ok {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	err := o.accountSvc.Unbind(ctx, uc.Uid)
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "OK",
+		})
+	case service.ErrLastLoginMethod:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Cannot unbind the last login method",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "System error",
+		})
+	}
+}
+
+func (o *OAuth2WechatHandler) authURL(ctx *gin.Context, uid int64) {
 	state := uuid.New()
 	// 为什么要传到这个svc里面
 	val, err := o.svc.AuthURL(ctx, state)
-	if err!=nil{
+	if err != nil {
 		ctx.JSON(http.StatusOK, Result{
-			Msg: "construct ulr error",
+			Msg:  "construct ulr error",
 			Code: 5,
 		})
 		return
 	}
 
 	//为什么这里state又被传了一遍
-	err = o.SetStateCookie(ctx, state)
-	if err!=nil{
+	err = o.SetStateCookie(ctx, state, uid)
+	if err != nil {
 		ctx.JSON(http.StatusOK, Result{
-			Msg: "service error",
+			Msg:  "service error",
 			Code: 5,
 		})
 		return
@@ -68,81 +120,145 @@ func (o *OAuth2WechatHandler) Auth2URL(ctx *gin.Context){
 
 }
 
-func (o *OAuth2WechatHandler) Callback(ctx *gin.Context){
+func (o *OAuth2WechatHandler) Callback(ctx *gin.Context) {
 
-	err:= o.VerifyState(ctx)
-	if err!=nil{
-		ctx.JSON(http.StatusOK, Result{
-			Msg: "illegal request",
+	sc, err := o.VerifyState(ctx)
+	if err != nil {
+		o.finish(ctx, Result{
+			Msg:  "illegal request",
 			Code: 4,
-		})
+		}, nil)
 		return
 	}
-	
+
 	// code为什么能从ctx拿出来？
 	code := ctx.Query("code")
 	//state := ctx.Query("state")
 
-	wechatInfo, err := o.svc.VerifyCode(ctx, code)
-	if err!=nil{
-		ctx.JSON(http.StatusOK, Result{
-			Msg: "authorization code error",
+	wechatInfo, token, err := o.svc.VerifyCode(ctx, code)
+	if err != nil {
+		o.finish(ctx, Result{
+			Msg:  "authorization code error",
 			Code: 4,
-		})
+		}, nil)
 		return
 	}
 
-	u, err := o.userSvc.FindOrCreateByWechat(ctx, wechatInfo)
-	if err!=nil{
-		ctx.JSON(http.StatusOK, Result{
-			Msg: "system error",
+	if sc.Uid != 0 {
+		o.bind(ctx, sc.Uid, wechatInfo, token)
+		return
+	}
+
+	u, err := o.accountSvc.Login(ctx, wechatInfo, token)
+	if err != nil {
+		o.finish(ctx, Result{
+			Msg:  "system error",
 			Code: 5,
-		})
+		}, nil)
 		return
 	}
-	o.SetJWTToken(ctx, u.Id)
-	ctx.JSON(http.StatusOK, Result{
+	err = o.SetLoginToken(ctx, u.Id, domain.SessionMethodWechat)
+	if err == service.ErrUserBanned {
+		o.finish(ctx, Result{
+			Msg:  "Account banned",
+			Code: 4,
+		}, nil)
+		return
+	}
+	if err != nil {
+		o.finish(ctx, Result{
+			Msg:  "system error",
+			Code: 5,
+		}, nil)
+		return
+	}
+	o.finish(ctx, Result{
 		Msg: "ok",
+	}, url.Values{
+		"access_token":  {ctx.Writer.Header().Get("x-jwt-token")},
+		"refresh_token": {ctx.Writer.Header().Get("x-refresh-token")},
 	})
-	return 
+	return
+}
+
+// finish 没有配置前端地址的时候直接返回 JSON，
+// 否则 302 回前端，code 和 msg 以及 extra 都放到 fragment 里面
+func (o *OAuth2WechatHandler) finish(ctx *gin.Context, res Result, extra url.Values) {
+	if o.frontendURL == "" {
+		ctx.JSON(http.StatusOK, res)
+		return
+	}
+	vals := url.Values{}
+	for k, v := range extra {
+		vals[k] = v
+	}
+	if res.Code != 0 {
+		vals.Set("error", res.Msg)
+		vals.Set("code", fmt.Sprintf("%d", res.Code))
+	}
+	ctx.Redirect(http.StatusFound, o.frontendURL+"#"+vals.Encode())
 }
 
+func (o *OAuth2WechatHandler) bind(ctx *gin.Context, uid int64,
+	info domain.WechatInfo, token domain.WechatToken) {
+	err := o.accountSvc.Bind(ctx, uid, info, token)
+	switch err {
+	case nil:
+		o.finish(ctx, Result{
+			Msg: "ok",
+		}, url.Values{"bind": {"ok"}})
+	case service.ErrDuplicateWechat:
+		o.finish(ctx, Result{
+			Code: 4,
+			Msg:  "WeChat already bound to another account",
+		}, nil)
+	default:
+		o.finish(ctx, Result{
+			Msg:  "system error",
+			Code: 5,
+		}, nil)
+	}
+}
 
-func (o *OAuth2WechatHandler) VerifyState(ctx *gin.Context) error{
+func (o *OAuth2WechatHandler) VerifyState(ctx *gin.Context) (StateClaims, error) {
 	state := ctx.Query("state")
 	ck, err := ctx.Cookie(o.stateCookieName)
-	if err!=nil{
-		return fmt.Errorf("cannot get cookie %w", err)
+	if err != nil {
+		return StateClaims{}, fmt.Errorf("cannot get cookie %w", err)
 
 	}
 
 	var sc StateClaims
+	// 只认签发时用的 HS512，防止算法混淆
 	_, err = jwt.ParseWithClaims(ck, &sc, func(t *jwt.Token) (interface{}, error) {
 		return o.key, nil
-	} )
-	if err!=nil{
-		return fmt.Errorf("parse token error, %w", err)
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS512.Alg()}))
+	if err != nil {
+		return StateClaims{}, fmt.Errorf("parse token error, %w", err)
 	}
-	if state!= sc.State{
-		return fmt.Errorf("state not match")
+	if state != sc.State {
+		return StateClaims{}, fmt.Errorf("state not match")
 	}
-	return nil
-
+	return sc, nil
 
 }
- 
 
 // 在哪里使用？在auth2url中使用
-func (o *OAuth2WechatHandler) SetStateCookie(ctx *gin.Context, state string) error{
+func (o *OAuth2WechatHandler) SetStateCookie(ctx *gin.Context, state string, uid int64) error {
 
 	claims := StateClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			// 和 cookie 一样十分钟
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute * 10)),
+		},
 		State: state,
+		Uid:   uid,
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS512, claims)
-	tokenStr, err :=  token.SignedString(o.key)
-	if err!=nil{
+	tokenStr, err := token.SignedString(o.key)
+	if err != nil {
 		return err
 	}
 	ctx.SetCookie(o.stateCookieName, tokenStr, 600, "/oauth2/wechat/callback", "", false, true)
 	return nil
-}
\ No newline at end of file
+}
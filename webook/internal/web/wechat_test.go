@@ -0,0 +1,183 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/service"
+	svcmocks "gitee.com/geekbang/basic-go/webook/internal/service/mocks"
+	"gitee.com/geekbang/basic-go/webook/internal/service/oauth2/wechat"
+	wechatmocks "gitee.com/geekbang/basic-go/webook/internal/service/oauth2/wechat/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestOAuth2WechatHandler_Callback(t *testing.T) {
+	const frontendURL = "http://localhost:3000/oauth2/wechat/done"
+	info := domain.WechatInfo{OpenId: "open-1", UnionId: "union-1"}
+	token := domain.WechatToken{OpenId: "open-1", AccessToken: "access-1"}
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (wechat.Service,
+			service.WechatAccountService, service.SessionService)
+		frontendURL string
+		// bindUid 不为 0 的时候是绑定流程
+		bindUid  int64
+		badState bool
+
+		wantCode int
+		// wantFragment 只比较列出来的 key
+		wantFragment url.Values
+		wantTokens   bool
+	}{
+		{
+			name: "login redirect",
+			mock: func(ctrl *gomock.Controller) (wechat.Service,
+				service.WechatAccountService, service.SessionService) {
+				svc := wechatmocks.NewMockService(ctrl)
+				svc.EXPECT().VerifyCode(gomock.Any(), "code-1").Return(info, token, nil)
+				accountSvc := svcmocks.NewMockWechatAccountService(ctrl)
+				accountSvc.EXPECT().Login(gomock.Any(), info, token).
+					Return(domain.User{Id: 123}, nil)
+				sessionSvc := svcmocks.NewMockSessionService(ctrl)
+				sessionSvc.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil)
+				return svc, accountSvc, sessionSvc
+			},
+			frontendURL: frontendURL,
+			wantCode:    http.StatusFound,
+			wantTokens:  true,
+		},
+		{
+			name: "bind redirect",
+			mock: func(ctrl *gomock.Controller) (wechat.Service,
+				service.WechatAccountService, service.SessionService) {
+				svc := wechatmocks.NewMockService(ctrl)
+				svc.EXPECT().VerifyCode(gomock.Any(), "code-1").Return(info, token, nil)
+				accountSvc := svcmocks.NewMockWechatAccountService(ctrl)
+				accountSvc.EXPECT().Bind(gomock.Any(), int64(123), info, token).Return(nil)
+				return svc, accountSvc, svcmocks.NewMockSessionService(ctrl)
+			},
+			frontendURL:  frontendURL,
+			bindUid:      123,
+			wantCode:     http.StatusFound,
+			wantFragment: url.Values{"bind": {"ok"}},
+		},
+		{
+			name: "bad state",
+			mock: func(ctrl *gomock.Controller) (wechat.Service,
+				service.WechatAccountService, service.SessionService) {
+				return wechatmocks.NewMockService(ctrl),
+					svcmocks.NewMockWechatAccountService(ctrl), svcmocks.NewMockSessionService(ctrl)
+			},
+			frontendURL:  frontendURL,
+			badState:     true,
+			wantCode:     http.StatusFound,
+			wantFragment: url.Values{"code": {"4"}, "error": {"illegal request"}},
+		},
+		{
+			name: "verify code failed",
+			mock: func(ctrl *gomock.Controller) (wechat.Service,
+				service.WechatAccountService, service.SessionService) {
+				svc := wechatmocks.NewMockService(ctrl)
+				svc.EXPECT().VerifyCode(gomock.Any(), "code-1").
+					Return(domain.WechatInfo{}, domain.WechatToken{}, errors.New("invalid code"))
+				return svc, svcmocks.NewMockWechatAccountService(ctrl), svcmocks.NewMockSessionService(ctrl)
+			},
+			frontendURL:  frontendURL,
+			wantCode:     http.StatusFound,
+			wantFragment: url.Values{"code": {"4"}, "error": {"authorization code error"}},
+		},
+		{
+			name: "no frontend url",
+			mock: func(ctrl *gomock.Controller) (wechat.Service,
+				service.WechatAccountService, service.SessionService) {
+				svc := wechatmocks.NewMockService(ctrl)
+				svc.EXPECT().VerifyCode(gomock.Any(), "code-1").Return(info, token, nil)
+				accountSvc := svcmocks.NewMockWechatAccountService(ctrl)
+				accountSvc.EXPECT().Login(gomock.Any(), info, token).
+					Return(domain.User{Id: 123}, nil)
+				sessionSvc := svcmocks.NewMockSessionService(ctrl)
+				sessionSvc.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil)
+				return svc, accountSvc, sessionSvc
+			},
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, accountSvc, sessionSvc := tc.mock(ctrl)
+			hdl := NewOAuth2WechatHandler(svc, accountSvc,
+				newTestJWTHandler(nil, sessionSvc, false), []byte("state-key"), tc.frontendURL)
+			server := gin.New()
+			hdl.RegisterRoutes(server)
+
+			// 先签一个 state cookie
+			ck := httptest.NewRecorder()
+			ckCtx, _ := gin.CreateTestContext(ck)
+			require.NoError(t, hdl.SetStateCookie(ckCtx, "state-1", tc.bindUid))
+			state := "state-1"
+			if tc.badState {
+				state = "state-2"
+			}
+
+			req, err := http.NewRequest(http.MethodGet,
+				"/oauth2/wechat/callback?code=code-1&state="+state, nil)
+			require.NoError(t, err)
+			for _, c := range ck.Result().Cookies() {
+				req.AddCookie(c)
+			}
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tc.wantCode, recorder.Code)
+			if tc.frontendURL == "" {
+				assert.NotEmpty(t, recorder.Header().Get("x-jwt-token"))
+				return
+			}
+			location := recorder.Header().Get("Location")
+			require.True(t, strings.HasPrefix(location, tc.frontendURL+"#"), location)
+			fragment, err := url.ParseQuery(strings.TrimPrefix(location, tc.frontendURL+"#"))
+			require.NoError(t, err)
+			for k := range tc.wantFragment {
+				assert.Equal(t, tc.wantFragment.Get(k), fragment.Get(k))
+			}
+			if tc.wantTokens {
+				assert.Equal(t, recorder.Header().Get("x-jwt-token"), fragment.Get("access_token"))
+				assert.NotEmpty(t, fragment.Get("access_token"))
+				assert.NotEmpty(t, fragment.Get("refresh_token"))
+			} else {
+				assert.Empty(t, fragment.Get("access_token"))
+			}
+		})
+	}
+}
+
+func TestOAuth2WechatHandler_VerifyStateRejectsOtherAlg(t *testing.T) {
+	key := []byte("state-key")
+	hdl := NewOAuth2WechatHandler(nil, nil, nil, key, "")
+	// 同一个 key，但是用 HS256 签名
+	tokenStr, err := jwt.NewWithClaims(jwt.SigningMethodHS256, StateClaims{
+		State: "state-1",
+	}).SignedString(key)
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodGet, "/oauth2/wechat/callback?state=state-1", nil)
+	require.NoError(t, err)
+	req.AddCookie(&http.Cookie{Name: hdl.stateCookieName, Value: tokenStr})
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = req
+
+	_, err = hdl.VerifyState(ctx)
+	assert.Error(t, err)
+}
@@ -2,6 +2,7 @@ package ioc
 
 import (
 	"gitee.com/geekbang/basic-go/webook/config"
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
 	"gitee.com/geekbang/basic-go/webook/internal/repository/dao"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
@@ -18,6 +19,20 @@ func InitDB() *gorm.DB {
 	if err != nil {
 		panic(err)
 	}
+	err = dao.InitRolePermissions(db, defaultRolePermissions())
+	if err != nil {
+		panic(err)
+	}
 	return db
 
 }
+
+func defaultRolePermissions() map[string][]string {
+	res := make(map[string][]string, len(domain.DefaultRolePermissions))
+	for role, perms := range domain.DefaultRolePermissions {
+		for _, p := range perms {
+			res[string(role)] = append(res[string(role)], string(p))
+		}
+	}
+	return res
+}
@@ -0,0 +1,32 @@
+package ioc
+
+import (
+	"gitee.com/geekbang/basic-go/webook/config"
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository"
+	"gitee.com/geekbang/basic-go/webook/internal/service"
+	"gitee.com/geekbang/basic-go/webook/internal/service/email"
+	"gitee.com/geekbang/basic-go/webook/internal/service/email/localemail"
+	"gitee.com/geekbang/basic-go/webook/internal/service/email/smtp"
+	"gitee.com/geekbang/basic-go/webook/internal/service/sms"
+)
+
+func InitEmailService() email.Service {
+	cfg := config.Config.Email
+	if cfg.Local {
+		return localemail.NewService()
+	}
+	// 没配 SMTP 就起不来，不能悄悄换成打日志，不然验证码都打到日志里面去了
+	if cfg.SMTPAddr == "" {
+		panic("Email.SMTPAddr 没有配置")
+	}
+	return smtp.NewService(cfg.SMTPAddr, cfg.Username, cfg.Password, cfg.From)
+}
+
+func InitCodeService(repo repository.CodeRepository, smsSvc sms.Service,
+	emailSvc email.Service) service.CodeService {
+	return service.NewCodeService(repo, map[domain.CodeChannel]service.CodeSender{
+		domain.CodeChannelPhone: service.NewSMSCodeSender(smsSvc),
+		domain.CodeChannelEmail: service.NewEmailCodeSender(emailSvc),
+	})
+}
@@ -0,0 +1,14 @@
+package ioc
+
+import (
+	"time"
+
+	"gitee.com/geekbang/basic-go/webook/internal/repository"
+	"gitee.com/geekbang/basic-go/webook/internal/service"
+	"go.uber.org/zap"
+)
+
+func InitReadCntBuffer(repo repository.InteractiveRepository, l *zap.Logger) *service.ReadCntBuffer {
+	// 攒够 100 篇文章或者每秒写一次数据库
+	return service.NewReadCntBuffer(repo, l, 100, time.Second)
+}
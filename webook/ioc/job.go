@@ -0,0 +1,49 @@
+package ioc
+
+import (
+	"time"
+
+	"gitee.com/geekbang/basic-go/webook/internal/job"
+	"gitee.com/geekbang/basic-go/webook/internal/service"
+	"gitee.com/geekbang/basic-go/webook/pkg/cronx"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+func InitRankingJob(svc service.RankingService) *job.RankingJob {
+	return job.NewRankingJob(svc)
+}
+
+func InitWechatTokenJob(svc service.WechatAccountService) *job.WechatTokenJob {
+	return job.NewWechatTokenJob(svc)
+}
+
+func InitJobs(cmd redis.Cmdable, l *zap.Logger, rankingJob *job.RankingJob,
+	wechatTokenJob *job.WechatTokenJob) *cronx.Scheduler {
+	// 锁租期 30 秒，单次最多执行 1 分钟
+	res := cronx.NewScheduler(cmd, l, time.Second*30, time.Minute)
+	// 每三分钟算一次热榜
+	err := res.Register("0 */3 * * * ?", rankingJob)
+	if err != nil {
+		panic(err)
+	}
+	// 每十分钟刷新一次快过期的微信 token，提前量是十五分钟
+	err = res.Register("0 */10 * * * ?", wechatTokenJob)
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+// InitLocalFuncExecutor 数据库里面 executor = local 的任务按照名字找到这里注册的方法。
+// 热榜已经由 InitJobs 里面的 cronx 任务计算了，不要在这里再注册一份
+func InitLocalFuncExecutor() *job.LocalFuncExecutor {
+	return job.NewLocalFuncExecutor()
+}
+
+func InitScheduler(svc service.CronJobService, l *zap.Logger, local *job.LocalFuncExecutor) *job.Scheduler {
+	// 一个节点最多同时执行 10 个任务
+	res := job.NewScheduler(svc, l, 10)
+	res.RegisterExecutor(local)
+	return res
+}
@@ -0,0 +1,60 @@
+package ioc
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"gitee.com/geekbang/basic-go/webook/config"
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/service"
+	"gitee.com/geekbang/basic-go/webook/internal/service/oauth2"
+	"gitee.com/geekbang/basic-go/webook/internal/web"
+)
+
+func InitOAuth2Registry() *oauth2.Registry {
+	client := &http.Client{Timeout: 10 * time.Second}
+	var providers []oauth2.Provider
+	for _, c := range config.Config.OAuth2.Providers {
+		if c.ClientID == "" {
+			continue
+		}
+		// 这两个和已有的路由冲突
+		if c.Name == domain.IdentityProviderWechat || c.Name == "providers" {
+			panic(fmt.Sprintf("oauth2 provider name %s is reserved", c.Name))
+		}
+		cfg := oauth2.Config{
+			Name:         c.Name,
+			ClientID:     c.ClientID,
+			ClientSecret: c.ClientSecret,
+			RedirectURL:  c.RedirectURL,
+			Scopes:       c.Scopes,
+		}
+		if c.Issuer != "" {
+			providers = append(providers, oauth2.NewOIDCProvider(cfg, c.Issuer, client))
+			continue
+		}
+		providers = append(providers, oauth2.NewGenericProvider(cfg, oauth2.Endpoints{
+			AuthURL:      c.AuthURL,
+			TokenURL:     c.TokenURL,
+			UserInfoURL:  c.UserInfoURL,
+			SubjectField: c.SubjectField,
+			EmailField:   c.EmailField,
+		}, client))
+	}
+	return oauth2.NewRegistry(providers...)
+}
+
+func InitOAuth2Handler(registry *oauth2.Registry, userSvc service.UserService,
+	jwtHdl *web.JWTHandler) *web.OAuth2Handler {
+	return web.NewOAuth2Handler(registry, userSvc, jwtHdl, stateKey())
+}
+
+// stateKey 微信和 OAuth2 的 state 都用它签名，state 里面带着 uid，key 太短就能伪造绑定
+func stateKey() []byte {
+	key := config.Config.JWT.StateKey
+	if len(key) < 32 {
+		panic("JWT.StateKey 至少要 32 个字节")
+	}
+	return []byte(key)
+}
@@ -0,0 +1,18 @@
+package ioc
+
+import (
+	"gitee.com/geekbang/basic-go/webook/config"
+	"gitee.com/geekbang/basic-go/webook/internal/repository"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/cache"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/dao"
+	"gitee.com/geekbang/basic-go/webook/pkg/cryptox"
+)
+
+// InitTwoFactorRepository 两步验证是所有用户都能开的，没有 key 直接起不来
+func InitTwoFactorRepository(d dao.TwoFactorDAO, c cache.TwoFactorCache) repository.TwoFactorRepository {
+	cipher, err := cryptox.NewAESGCMFromBase64(config.Config.TwoFactor.SecretKey)
+	if err != nil {
+		panic(err)
+	}
+	return repository.NewTwoFactorRepository(d, c, cipher)
+}
@@ -5,10 +5,15 @@ import (
 	"strings"
 	"time"
 
+	"gitee.com/geekbang/basic-go/webook/config"
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/service"
 	"gitee.com/geekbang/basic-go/webook/internal/web"
 	login "gitee.com/geekbang/basic-go/webook/internal/web/middleware"
 	"gitee.com/geekbang/basic-go/webook/pkg/ginx/middleware/ratelimit"
+	"gitee.com/geekbang/basic-go/webook/pkg/jwtx"
 	"gitee.com/geekbang/basic-go/webook/pkg/limiter"
+	"github.com/ecodeclub/ekit/slice"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
@@ -18,23 +23,61 @@ func InitContext() context.Context{
 	return context.Background()
 }
 
-func InitWebServer(mdls []gin.HandlerFunc, userHdl *web.UserHandler, wechatHdl *web.OAuth2WechatHandler) *gin.Engine {
+func InitWebServer(mdls []gin.HandlerFunc, userHdl *web.UserHandler,
+	wechatHdl *web.OAuth2WechatHandler, artHdl *web.ArticleHandler,
+	collectionHdl *web.CollectionHandler, sessionHdl *web.SessionHandler,
+	twoFactorHdl *web.TwoFactorHandler, oauth2Hdl *web.OAuth2Handler,
+	adminHdl *web.AdminHandler) *gin.Engine {
 	server := gin.Default()
 	server.Use(mdls...)
 	userHdl.RegisterRoutes(server)
 	wechatHdl.RegisterRoutes(server)
+	oauth2Hdl.RegisterRoutes(server)
+	artHdl.RegisterRoutes(server)
+	collectionHdl.RegisterRoutes(server)
+	sessionHdl.RegisterRoutes(server)
+	twoFactorHdl.RegisterRoutes(server)
+	adminHdl.RegisterRoutes(server)
 	return server
 
 }
 
-func InitGinMiddlewares(redisLimiter limiter.Limiter) []gin.HandlerFunc {
+func InitJWTHandler(cmd redis.Cmdable, sessionSvc service.SessionService,
+	rbacSvc service.RBACService) *web.JWTHandler {
+	cfg := config.Config.JWT
+	accessKeys, err := initKeySet(cfg.Access)
+	if err != nil {
+		panic(err)
+	}
+	refreshKeys, err := initKeySet(cfg.Refresh)
+	if err != nil {
+		panic(err)
+	}
+	return web.NewJWTHandler(cmd, sessionSvc, rbacSvc, accessKeys, refreshKeys,
+		config.Config.Session.AllowWhenRedisDown)
+}
+
+func initKeySet(cfg config.JWTKeySetConfig) (*jwtx.KeySet, error) {
+	return jwtx.LoadKeySet(cfg.Current, slice.Map(cfg.Keys, func(idx int, src config.JWTKeyConfig) jwtx.KeyConfig {
+		return jwtx.KeyConfig{
+			Kid:            src.Kid,
+			Alg:            src.Alg,
+			Secret:         src.Secret,
+			PrivateKeyFile: src.PrivateKeyFile,
+			PublicKeyFile:  src.PublicKeyFile,
+		}
+	}))
+}
+
+func InitGinMiddlewares(redisLimiter limiter.Limiter, jwtHdl *web.JWTHandler,
+	userSvc service.UserService, rbacSvc service.RBACService) []gin.HandlerFunc {
 	return []gin.HandlerFunc{
 		cors.New(cors.Config{
 			//AllowAllOrigins: true,
 			//AllowOrigins:     []string{"http://localhost:3000"},
 			AllowCredentials: true,
 			AllowHeaders:     []string{"Content-Type", "Authorization"},
-			ExposeHeaders:    []string{"x-jwt-token"},
+			ExposeHeaders:    []string{"x-jwt-token", "x-refresh-token"},
 			AllowOriginFunc: func(origin string) bool {
 				if strings.HasPrefix(origin, "http://localhost") {
 					//if strings.Contains(origin, "localhost") {
@@ -45,8 +88,16 @@ func InitGinMiddlewares(redisLimiter limiter.Limiter) []gin.HandlerFunc {
 			MaxAge: 12 * time.Hour,
 		}),
 		ratelimit.NewBuilder(redisLimiter).Build(),
-		(&login.LoginJWTMiddlewareBuiler{}).CheckLogin(),
-		
+		login.NewLoginJWTMiddlewareBuiler(jwtHdl, userSvc).CheckLogin(),
+		login.NewEmailVerifiedMiddlewareBuilder(userSvc).
+			Paths(config.Config.Verification.EmailRequiredPaths...).Build(),
+		// 和 AdminHandler 的路由一一对应
+		login.NewPermissionMiddlewareBuilder(rbacSvc).
+			Require(domain.PermissionUserRead, "/admin/users/search").
+			Require(domain.PermissionUserBan, "/admin/users/ban", "/admin/users/unban").
+			Require(domain.PermissionRoleManage, "/admin/users/grant_role", "/admin/users/revoke_role").
+			Require(domain.PermissionSMSRead, "/admin/sms/async/stats", "/admin/sms/async/list").
+			DenyByDefault("/admin/").Build(),
 	}
 }
 
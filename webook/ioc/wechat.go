@@ -1,17 +1,52 @@
 package ioc
 
 import (
-	//"os"
+	"crypto/rand"
 
+	"gitee.com/geekbang/basic-go/webook/config"
+	"gitee.com/geekbang/basic-go/webook/internal/repository"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/dao"
+	"gitee.com/geekbang/basic-go/webook/internal/service"
 	"gitee.com/geekbang/basic-go/webook/internal/service/oauth2/wechat"
+	"gitee.com/geekbang/basic-go/webook/internal/web"
+	"gitee.com/geekbang/basic-go/webook/pkg/cryptox"
 )
 
 func InitWechatService() wechat.Service{
-	// appID, ok := os.LookupEnv("WECHAT_APP_ID")
-	// if !ok{
-	// 	panic("cannot find global environment variable wechat")
-	// }
-	appID := "wxbdc5610cc59c1631"
-	appSecret := "abcdefg"
-	return wechat.NewService(appID, appSecret)
-}
\ No newline at end of file
+	cfg := config.Config.Wechat
+	return wechat.NewService(wechat.Config{
+		AppID:       cfg.AppID,
+		AppSecret:   cfg.AppSecret,
+		RedirectURL: cfg.RedirectURL,
+		AuthURL:     cfg.AuthURL,
+		BaseURL:     cfg.APIBaseURL,
+	})
+}
+
+// InitWechatTokenRepository AppID 为空就是没有启用微信登录，这个时候可以不配 key
+func InitWechatTokenRepository(d dao.WechatTokenDAO) repository.WechatTokenRepository {
+	cfg := config.Config.Wechat
+	cipher, err := cryptox.NewAESGCMFromBase64(cfg.TokenKey)
+	if err != nil && cfg.AppID == "" {
+		// 不会有新的 token 写进来，随机一个 key 就行，以前存的解密失败会被跳过
+		cipher, err = randomAESGCM()
+	}
+	if err != nil {
+		panic(err)
+	}
+	return repository.NewWechatTokenRepository(d, cipher)
+}
+
+func randomAESGCM() (*cryptox.AESGCM, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return cryptox.NewAESGCM(key)
+}
+
+func InitOAuth2WechatHandler(svc wechat.Service, accountSvc service.WechatAccountService,
+	jwtHdl *web.JWTHandler) *web.OAuth2WechatHandler {
+	return web.NewOAuth2WechatHandler(svc, accountSvc, jwtHdl,
+		stateKey(), config.Config.Wechat.FrontendURL)
+}
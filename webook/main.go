@@ -1,7 +1,13 @@
 package main
 
 import (
+	"context"
+	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	login "gitee.com/geekbang/basic-go/webook/internal/web/middleware"
 	"github.com/gin-contrib/sessions"
@@ -20,13 +26,55 @@ func main() {
 	// codeSvc := initCodeSvc(redisClient, smsSvc)
 	// initUserHdl(db, redisClient, codeSvc,server)
 
-	server := InitWebServer()
+	app := InitApp()
+	server := app.server
 	server.GET("/hello", func(ctx *gin.Context) {
 		ctx.String(http.StatusOK, "Hello World")
 	})
 
-	server.Run(":8080")
+	app.scheduler.Start()
+	schedCtx, schedCancel := context.WithCancel(context.Background())
+	schedDone := make(chan struct{})
+	go func() {
+		defer close(schedDone)
+		_ = app.jobScheduler.Schedule(schedCtx)
+	}()
 
+	srv := &http.Server{
+		Addr:    ":8080",
+		Handler: server,
+	}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			panic(err)
+		}
+	}()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	<-ctx.Done()
+
+	// 先停止接收请求，再把内存里面的阅读计数刷到数据库
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Println("shutdown server error", err)
+	}
+	if err := app.readCntBuffer.Close(shutdownCtx); err != nil {
+		log.Println("flush read count error", err)
+	}
+	// 等正在执行的定时任务跑完
+	schedCancel()
+	select {
+	case <-app.scheduler.Stop().Done():
+	case <-shutdownCtx.Done():
+		log.Println("wait jobs timeout")
+	}
+	select {
+	case <-schedDone:
+	case <-shutdownCtx.Done():
+		log.Println("wait preempted jobs timeout")
+	}
 }
 
 func useSession(server *gin.Engine) {
@@ -0,0 +1,7 @@
+-- 记下最后执行完的是哪一次触发，只往后推，不会被晚到的实例改回去
+local last = tonumber(redis.call("get", KEYS[1]) or "0")
+local tick = tonumber(ARGV[1])
+if tick > last then
+    redis.call("set", KEYS[1], ARGV[1])
+end
+return 0
@@ -0,0 +1,194 @@
+package cronx
+
+import (
+	"context"
+	_ "embed"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gitee.com/geekbang/basic-go/webook/pkg/redislock"
+	"github.com/redis/go-redis/v9"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+//go:embed lua/mark_done.lua
+var luaMarkDone string
+
+// Job 定时任务。ctx 在任务超时或者锁丢了的时候会被取消
+type Job interface {
+	Name() string
+	Run(ctx context.Context) error
+}
+
+// Scheduler 按照 cron 表达式调度任务。每个任务一把分布式锁，抢到锁的实例执行，执行期间不停续约。
+// Redis 里面还记着每个任务最后执行完的是哪一次触发，时钟慢一点的实例晚一点触发，
+// 看到这一次已经执行过了就跳过。
+// 没抢到锁的实例会隔一会儿再看一下，持有锁的实例挂了，锁过期之后就由它来补上这一次，
+// 一直到下一次触发为止。
+// timeout 要比触发的间隔短，不然上一次没执行完下一次就开始了。
+type Scheduler struct {
+	cron   *cron.Cron
+	cmd    redis.Cmdable
+	client *redislock.Client
+	l      *zap.Logger
+
+	// expiration 锁的租期，续约间隔是它的三分之一。
+	// 持有锁的实例挂了，别的实例最多等这么久就能接手
+	expiration time.Duration
+	// timeout 单次执行的最长时间
+	timeout time.Duration
+	// retryInterval 锁被别人拿着的时候，隔多久再看一下
+	retryInterval time.Duration
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+func NewScheduler(cmd redis.Cmdable, l *zap.Logger,
+	expiration time.Duration, timeout time.Duration) *Scheduler {
+	return &Scheduler{
+		cron:          cron.New(cron.WithSeconds()),
+		cmd:           cmd,
+		client:        redislock.NewClient(cmd),
+		l:             l,
+		expiration:    expiration,
+		timeout:       timeout,
+		retryInterval: expiration / 3,
+		stopCh:        make(chan struct{}),
+	}
+}
+
+// parser 和 cron.WithSeconds 用的一样
+var parser = cron.NewParser(cron.Second | cron.Minute | cron.Hour |
+	cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// Register 注册任务，spec 支持秒级的 cron 表达式
+func (s *Scheduler) Register(spec string, job Job) error {
+	sched, err := parser.Parse(spec)
+	if err != nil {
+		return err
+	}
+	s.cron.Schedule(sched, cron.FuncJob(func() {
+		// cron 是在整秒触发的，截断之后就是这一次触发的时间，各个实例算出来的一样
+		tick := time.Now().Truncate(time.Second)
+		s.run(job, tick, sched.Next(tick))
+	}))
+	return nil
+}
+
+func (s *Scheduler) Start() {
+	s.cron.Start()
+}
+
+// Stop 停止调度，返回的 ctx 在正在执行的任务都结束之后会被取消
+func (s *Scheduler) Stop() context.Context {
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+	})
+	return s.cron.Stop()
+}
+
+// run 一直试到 tick 这一次执行完，不管是自己执行的还是别的实例执行的。
+// 到了 next 还没执行就放弃，交给下一次触发
+func (s *Scheduler) run(job Job, tick time.Time, next time.Time) {
+	name := job.Name()
+	for {
+		done, err := s.tryRun(job, tick)
+		if err != nil {
+			s.l.Error("job lock failed", zap.String("name", name), zap.Error(err))
+		}
+		if done {
+			return
+		}
+		if !time.Now().Add(s.retryInterval).Before(next) {
+			s.l.Warn("job missed", zap.String("name", name), zap.Time("tick", tick))
+			return
+		}
+		select {
+		case <-time.After(s.retryInterval):
+		case <-s.stopCh:
+			return
+		}
+	}
+}
+
+// tryRun 抢到锁并且 tick 这一次还没执行过就执行。
+// 返回 true 表示这一次已经处理完了，不用再试
+func (s *Scheduler) tryRun(job Job, tick time.Time) (bool, error) {
+	name := job.Name()
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	lock, err := s.client.TryLock(ctx, s.lockKey(name), s.expiration)
+	switch err {
+	case nil:
+	case redislock.ErrFailedToPreemptLock:
+		// 别的实例正在执行，或者它已经挂了，等锁过期
+		s.l.Debug("job lock held by others", zap.String("name", name))
+		return false, nil
+	default:
+		return false, err
+	}
+	defer func() {
+		// 任务超时了 ctx 也过期了，换一个
+		uctx, ucancel := context.WithTimeout(context.Background(), time.Second)
+		defer ucancel()
+		if er := lock.Unlock(uctx); er != nil {
+			s.l.Error("job unlock failed", zap.String("name", name), zap.Error(er))
+		}
+	}()
+
+	last, err := s.cmd.Get(ctx, s.doneKey(name)).Int64()
+	if err != nil && err != redis.Nil {
+		return false, err
+	}
+	if last >= tick.Unix() {
+		s.l.Debug("job already done by others", zap.String("name", name))
+		return true, nil
+	}
+
+	var lost atomic.Bool
+	go func() {
+		er := lock.AutoRefresh(s.expiration/3, time.Second)
+		if er != nil {
+			// 锁丢了，别的实例可能已经开始执行，这边赶紧停下来
+			s.l.Error("job lock lost", zap.String("name", name), zap.Error(er))
+			lost.Store(true)
+			cancel()
+		}
+	}()
+
+	start := time.Now()
+	s.l.Info("job start", zap.String("name", name))
+	err = job.Run(ctx)
+	if err != nil {
+		s.l.Error("job failed", zap.String("name", name),
+			zap.Duration("duration", time.Since(start)), zap.Error(err))
+	} else {
+		s.l.Info("job end", zap.String("name", name),
+			zap.Duration("duration", time.Since(start)))
+	}
+	if lost.Load() {
+		// 锁已经是别人的了，这一次交给它
+		return true, nil
+	}
+	// 失败了也算执行过，不重试
+	mctx, mcancel := context.WithTimeout(context.Background(), time.Second)
+	defer mcancel()
+	err = s.cmd.Eval(mctx, luaMarkDone, []string{s.doneKey(name)}, tick.Unix()).Err()
+	if err != nil {
+		// 记不下来的话，时钟慢的实例可能会再执行一次
+		s.l.Error("job mark done failed", zap.String("name", name), zap.Error(err))
+	}
+	return true, nil
+}
+
+func (s *Scheduler) lockKey(name string) string {
+	return "cronx:lock:" + name
+}
+
+func (s *Scheduler) doneKey(name string) string {
+	return "cronx:done:" + name
+}
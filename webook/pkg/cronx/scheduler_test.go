@@ -0,0 +1,144 @@
+package cronx
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gitee.com/geekbang/basic-go/webook/pkg/redislock"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestScheduler_TakeOver(t *testing.T) {
+	rdb := newFakeRedis(t)
+	// 另外一个实例拿到锁之后就挂了，不再续约
+	_, err := redislock.NewClient(rdb).TryLock(context.Background(), "cronx:lock:test", time.Millisecond*300)
+	require.NoError(t, err)
+
+	job := &countJob{}
+	tick := time.Now().Truncate(time.Second)
+	s := NewScheduler(rdb, zap.NewNop(), time.Millisecond*300, time.Second)
+	// 等锁过期之后接手
+	s.run(job, tick, time.Now().Add(time.Second*5))
+	assert.Equal(t, int64(1), job.cnt.Load())
+
+	// 时钟慢的实例晚一点触发同一次，不会再执行
+	other := NewScheduler(rdb, zap.NewNop(), time.Millisecond*300, time.Second)
+	other.run(job, tick, time.Now().Add(time.Second*5))
+	assert.Equal(t, int64(1), job.cnt.Load())
+
+	// 下一次触发照常执行
+	other.run(job, tick.Add(time.Second), time.Now().Add(time.Second*5))
+	assert.Equal(t, int64(2), job.cnt.Load())
+}
+
+func TestScheduler_Missed(t *testing.T) {
+	rdb := newFakeRedis(t)
+	// 锁一直被别人拿着，到下一次触发就放弃
+	_, err := redislock.NewClient(rdb).TryLock(context.Background(), "cronx:lock:test", time.Minute)
+	require.NoError(t, err)
+
+	job := &countJob{}
+	s := NewScheduler(rdb, zap.NewNop(), time.Millisecond*300, time.Second)
+	s.run(job, time.Now().Truncate(time.Second), time.Now().Add(time.Millisecond*500))
+	assert.Equal(t, int64(0), job.cnt.Load())
+}
+
+type countJob struct {
+	cnt atomic.Int64
+}
+
+func (j *countJob) Name() string {
+	return "test"
+}
+
+func (j *countJob) Run(ctx context.Context) error {
+	j.cnt.Add(1)
+	return nil
+}
+
+// fakeRedis 只实现了 redislock 和 Scheduler 用到的命令，脚本按照内容认出来，用 Go 模拟
+type fakeRedis struct {
+	redis.Cmdable
+	mu       sync.Mutex
+	vals     map[string]string
+	expireAt map[string]time.Time
+	scripts  map[string]string
+}
+
+func newFakeRedis(t *testing.T) *fakeRedis {
+	scripts := map[string]string{luaMarkDone: "mark_done"}
+	for _, name := range []string{"lock", "refresh", "unlock"} {
+		script, err := os.ReadFile("../redislock/lua/" + name + ".lua")
+		require.NoError(t, err)
+		scripts[string(script)] = name
+	}
+	return &fakeRedis{
+		vals:     map[string]string{},
+		expireAt: map[string]time.Time{},
+		scripts:  scripts,
+	}
+}
+
+func (f *fakeRedis) get(key string) (string, bool) {
+	if exp, ok := f.expireAt[key]; ok && !time.Now().Before(exp) {
+		delete(f.vals, key)
+		delete(f.expireAt, key)
+	}
+	val, ok := f.vals[key]
+	return val, ok
+}
+
+func (f *fakeRedis) Get(ctx context.Context, key string) *redis.StringCmd {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	val, ok := f.get(key)
+	if !ok {
+		return redis.NewStringResult("", redis.Nil)
+	}
+	return redis.NewStringResult(val, nil)
+}
+
+func (f *fakeRedis) Eval(ctx context.Context, script string, keys []string, args ...any) *redis.Cmd {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := keys[0]
+	val, ok := f.get(key)
+	switch f.scripts[script] {
+	case "lock":
+		if ok && val != args[0].(string) {
+			return redis.NewCmdResult("", nil)
+		}
+		f.vals[key] = args[0].(string)
+		f.expireAt[key] = time.Now().Add(time.Duration(args[1].(int64)) * time.Millisecond)
+		return redis.NewCmdResult("OK", nil)
+	case "refresh":
+		if !ok || val != args[0].(string) {
+			return redis.NewCmdResult(int64(0), nil)
+		}
+		f.expireAt[key] = time.Now().Add(time.Duration(args[1].(int64)) * time.Millisecond)
+		return redis.NewCmdResult(int64(1), nil)
+	case "unlock":
+		if !ok || val != args[0].(string) {
+			return redis.NewCmdResult(int64(0), nil)
+		}
+		delete(f.vals, key)
+		delete(f.expireAt, key)
+		return redis.NewCmdResult(int64(1), nil)
+	case "mark_done":
+		last, _ := strconv.ParseInt(val, 10, 64)
+		if tick := args[0].(int64); tick > last {
+			f.vals[key] = strconv.FormatInt(tick, 10)
+		}
+		return redis.NewCmdResult(int64(0), nil)
+	default:
+		return redis.NewCmdResult(nil, redis.Nil)
+	}
+}
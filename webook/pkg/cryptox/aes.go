@@ -0,0 +1,65 @@
+// Package cryptox 加密存到数据库里面的敏感数据，比如第三方平台的 token
+package cryptox
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+)
+
+var ErrInvalidCiphertext = errors.New("invalid ciphertext")
+
+// AESGCM 密文是 base64(nonce + 密文 + tag)，每次加密的 nonce 都是随机的
+type AESGCM struct {
+	aead cipher.AEAD
+}
+
+// NewAESGCM key 16、24 或者 32 字节，分别对应 AES-128、AES-192、AES-256
+func NewAESGCM(key []byte) (*AESGCM, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &AESGCM{aead: aead}, nil
+}
+
+// NewAESGCMFromBase64 配置里面的 key 是 base64 编码的
+func NewAESGCMFromBase64(key string) (*AESGCM, error) {
+	raw, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, err
+	}
+	return NewAESGCM(raw)
+}
+
+func (a *AESGCM) Encrypt(plaintext string) (string, error) {
+	nonce := make([]byte, a.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := a.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt 被改过或者 key 不对都返回错误
+func (a *AESGCM) Decrypt(ciphertext string) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", ErrInvalidCiphertext
+	}
+	n := a.aead.NonceSize()
+	if len(raw) < n {
+		return "", ErrInvalidCiphertext
+	}
+	plain, err := a.aead.Open(nil, raw[:n], raw[n:], nil)
+	if err != nil {
+		return "", ErrInvalidCiphertext
+	}
+	return string(plain), nil
+}
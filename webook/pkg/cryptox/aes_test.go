@@ -0,0 +1,39 @@
+package cryptox
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAESGCM(t *testing.T) {
+	a, err := NewAESGCM([]byte("0123456789abcdef0123456789abcdef"))
+	require.NoError(t, err)
+
+	c1, err := a.Encrypt("access_token")
+	require.NoError(t, err)
+	c2, err := a.Encrypt("access_token")
+	require.NoError(t, err)
+	// nonce 随机，同样的明文密文也不一样
+	assert.NotEqual(t, c1, c2)
+
+	plain, err := a.Decrypt(c1)
+	require.NoError(t, err)
+	assert.Equal(t, "access_token", plain)
+
+	raw, err := base64.StdEncoding.DecodeString(c1)
+	require.NoError(t, err)
+	raw[len(raw)-1] ^= 1
+	_, err = a.Decrypt(base64.StdEncoding.EncodeToString(raw))
+	assert.Equal(t, ErrInvalidCiphertext, err)
+
+	other, err := NewAESGCM([]byte("fedcba9876543210fedcba9876543210"))
+	require.NoError(t, err)
+	_, err = other.Decrypt(c1)
+	assert.Equal(t, ErrInvalidCiphertext, err)
+
+	_, err = a.Decrypt("short")
+	assert.Equal(t, ErrInvalidCiphertext, err)
+}
@@ -0,0 +1,126 @@
+package jwtx
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"os"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+)
+
+// Key 一个带 kid 的密钥。signKey 为空的 key 只能用来验签，轮换的时候旧 key 就是这样
+type Key struct {
+	Kid       string
+	Method    jwt.SigningMethod
+	signKey   any
+	verifyKey any
+}
+
+// KeyConfig 从配置加载 key。HS* 用 Secret，RS256 和 EdDSA 用 PEM 文件
+type KeyConfig struct {
+	Kid string
+	// Alg HS256、HS512、RS256、EdDSA
+	Alg    string
+	Secret string
+	// PrivateKeyFile 有私钥就能签名，公钥从私钥里面推出来
+	PrivateKeyFile string
+	// PublicKeyFile 只有公钥的 key 只能验签
+	PublicKeyFile string
+}
+
+func NewHMACKey(kid string, method *jwt.SigningMethodHMAC, secret []byte) *Key {
+	return &Key{
+		Kid:       kid,
+		Method:    method,
+		signKey:   secret,
+		verifyKey: secret,
+	}
+}
+
+// NewRSAKey priv 为 nil 的时候只能验签
+func NewRSAKey(kid string, priv *rsa.PrivateKey, pub *rsa.PublicKey) *Key {
+	k := &Key{
+		Kid:       kid,
+		Method:    jwt.SigningMethodRS256,
+		verifyKey: pub,
+	}
+	if priv != nil {
+		k.signKey = priv
+		k.verifyKey = &priv.PublicKey
+	}
+	return k
+}
+
+// NewEdDSAKey priv 为 nil 的时候只能验签
+func NewEdDSAKey(kid string, priv ed25519.PrivateKey, pub ed25519.PublicKey) *Key {
+	k := &Key{
+		Kid:       kid,
+		Method:    jwt.SigningMethodEdDSA,
+		verifyKey: pub,
+	}
+	if priv != nil {
+		k.signKey = priv
+		k.verifyKey = priv.Public()
+	}
+	return k
+}
+
+func LoadKey(cfg KeyConfig) (*Key, error) {
+	if cfg.Kid == "" {
+		return nil, errors.New("jwtx: kid 不能为空")
+	}
+	switch cfg.Alg {
+	case "HS256", "HS384", "HS512":
+		if cfg.Secret == "" {
+			return nil, fmt.Errorf("jwtx: key %s 没有 secret", cfg.Kid)
+		}
+		method := jwt.GetSigningMethod(cfg.Alg).(*jwt.SigningMethodHMAC)
+		return NewHMACKey(cfg.Kid, method, []byte(cfg.Secret)), nil
+	case "RS256":
+		if cfg.PrivateKeyFile != "" {
+			data, err := os.ReadFile(cfg.PrivateKeyFile)
+			if err != nil {
+				return nil, err
+			}
+			priv, err := jwt.ParseRSAPrivateKeyFromPEM(data)
+			if err != nil {
+				return nil, err
+			}
+			return NewRSAKey(cfg.Kid, priv, nil), nil
+		}
+		data, err := os.ReadFile(cfg.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		pub, err := jwt.ParseRSAPublicKeyFromPEM(data)
+		if err != nil {
+			return nil, err
+		}
+		return NewRSAKey(cfg.Kid, nil, pub), nil
+	case "EdDSA":
+		if cfg.PrivateKeyFile != "" {
+			data, err := os.ReadFile(cfg.PrivateKeyFile)
+			if err != nil {
+				return nil, err
+			}
+			priv, err := jwt.ParseEdPrivateKeyFromPEM(data)
+			if err != nil {
+				return nil, err
+			}
+			return NewEdDSAKey(cfg.Kid, priv.(ed25519.PrivateKey), nil), nil
+		}
+		data, err := os.ReadFile(cfg.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		pub, err := jwt.ParseEdPublicKeyFromPEM(data)
+		if err != nil {
+			return nil, err
+		}
+		return NewEdDSAKey(cfg.Kid, nil, pub.(ed25519.PublicKey)), nil
+	default:
+		return nil, fmt.Errorf("jwtx: 不支持的算法 %s", cfg.Alg)
+	}
+}
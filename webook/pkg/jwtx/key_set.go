@@ -0,0 +1,136 @@
+package jwtx
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+)
+
+var ErrUnknownKid = errors.New("jwtx: 未知的 kid")
+
+// KeySet 用 current 签名，用所有的 key 验签。
+// 轮换的时候先把新 key 加进来再切 current，旧 key 等它签的 token 都过期了再删掉。
+type KeySet struct {
+	current *Key
+	keys    map[string]*Key
+	methods []string
+}
+
+func NewKeySet(current string, keys ...*Key) (*KeySet, error) {
+	s := &KeySet{
+		keys: make(map[string]*Key, len(keys)),
+	}
+	seen := make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		if _, ok := s.keys[k.Kid]; ok {
+			return nil, fmt.Errorf("jwtx: kid %s 重复", k.Kid)
+		}
+		s.keys[k.Kid] = k
+		alg := k.Method.Alg()
+		if _, ok := seen[alg]; !ok {
+			seen[alg] = struct{}{}
+			s.methods = append(s.methods, alg)
+		}
+	}
+	cur, ok := s.keys[current]
+	if !ok {
+		return nil, fmt.Errorf("jwtx: 当前 key %s 不存在", current)
+	}
+	if cur.signKey == nil {
+		return nil, fmt.Errorf("jwtx: 当前 key %s 没有私钥，不能签名", current)
+	}
+	s.current = cur
+	return s, nil
+}
+
+func LoadKeySet(current string, cfgs []KeyConfig) (*KeySet, error) {
+	keys := make([]*Key, 0, len(cfgs))
+	for _, cfg := range cfgs {
+		k, err := LoadKey(cfg)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return NewKeySet(current, keys...)
+}
+
+// Sign 用当前 key 签名，header 里面带上 kid
+func (s *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(s.current.Method, claims)
+	token.Header["kid"] = s.current.Kid
+	return token.SignedString(s.current.signKey)
+}
+
+// Parse 按照 header 里面的 kid 找验签的 key，算法必须和 key 一致
+func (s *KeySet) Parse(tokenStr string, claims jwt.Claims) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(tokenStr, claims, s.keyFunc, jwt.WithValidMethods(s.methods))
+}
+
+func (s *KeySet) keyFunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	k, ok := s.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKid
+	}
+	// 防止拿公钥当 HMAC secret 之类的算法混淆
+	if token.Method.Alg() != k.Method.Alg() {
+		return nil, fmt.Errorf("jwtx: kid %s 的算法是 %s", kid, k.Method.Alg())
+	}
+	return k.verifyKey, nil
+}
+
+// JWK 公钥，字段按照 RFC 7517 / RFC 8037
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS 所有非对称 key 的公钥，HMAC 的 key 不能公开
+func (s *KeySet) JWKS() JWKS {
+	res := JWKS{Keys: []JWK{}}
+	for _, k := range s.keys {
+		switch pub := k.verifyKey.(type) {
+		case *rsa.PublicKey:
+			res.Keys = append(res.Keys, JWK{
+				Kty: "RSA",
+				Kid: k.Kid,
+				Alg: k.Method.Alg(),
+				Use: "sig",
+				N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			res.Keys = append(res.Keys, JWK{
+				Kty: "OKP",
+				Kid: k.Kid,
+				Alg: k.Method.Alg(),
+				Use: "sig",
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(pub),
+			})
+		}
+	}
+	sort.Slice(res.Keys, func(i, j int) bool {
+		return res.Keys[i].Kid < res.Keys[j].Kid
+	})
+	return res
+}
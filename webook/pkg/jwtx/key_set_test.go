@@ -0,0 +1,110 @@
+package jwtx
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testClaims struct {
+	jwt.RegisteredClaims
+	Uid int64
+}
+
+func newTestClaims() testClaims {
+	return testClaims{
+		Uid: 123,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	}
+}
+
+func TestKeySet_Rotation(t *testing.T) {
+	oldKey := NewHMACKey("old", jwt.SigningMethodHS512, []byte("old secret"))
+	_, edPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	newKey := NewEdDSAKey("new", edPriv, nil)
+
+	before, err := NewKeySet("old", oldKey)
+	require.NoError(t, err)
+	oldToken, err := before.Sign(newTestClaims())
+	require.NoError(t, err)
+
+	// 轮换：新 key 签名，旧 key 还能验签
+	after, err := NewKeySet("new", oldKey, newKey)
+	require.NoError(t, err)
+	newToken, err := after.Sign(newTestClaims())
+	require.NoError(t, err)
+
+	for _, tokenStr := range []string{oldToken, newToken} {
+		var c testClaims
+		token, err := after.Parse(tokenStr, &c)
+		require.NoError(t, err)
+		assert.True(t, token.Valid)
+		assert.Equal(t, int64(123), c.Uid)
+	}
+	var c testClaims
+	token, err := jwt.NewParser().ParseWithClaims(newToken, &c, func(token *jwt.Token) (any, error) {
+		return edPriv.Public(), nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "new", token.Header["kid"])
+
+	// 旧 key 删掉之后，它签的 token 就不能用了
+	removed, err := NewKeySet("new", newKey)
+	require.NoError(t, err)
+	_, err = removed.Parse(oldToken, &testClaims{})
+	assert.Error(t, err)
+}
+
+func TestKeySet_RejectAlgMismatch(t *testing.T) {
+	rsaPriv, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ks, err := NewKeySet("rsa", NewRSAKey("rsa", rsaPriv, nil),
+		NewHMACKey("hmac", jwt.SigningMethodHS256, []byte("secret")))
+	require.NoError(t, err)
+
+	// 用 HMAC 伪造一个声称是 rsa 这个 kid 的 token
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, newTestClaims())
+	token.Header["kid"] = "rsa"
+	tokenStr, err := token.SignedString([]byte("secret"))
+	require.NoError(t, err)
+	_, err = ks.Parse(tokenStr, &testClaims{})
+	assert.Error(t, err)
+}
+
+func TestKeySet_VerifyOnlyCurrent(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	_, err = NewKeySet("pub", NewEdDSAKey("pub", nil, pub))
+	assert.Error(t, err)
+}
+
+func TestKeySet_JWKS(t *testing.T) {
+	rsaPriv, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	edPub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	ks, err := NewKeySet("a-rsa",
+		NewRSAKey("a-rsa", rsaPriv, nil),
+		NewEdDSAKey("b-ed", nil, edPub),
+		NewHMACKey("c-hmac", jwt.SigningMethodHS512, []byte("secret")))
+	require.NoError(t, err)
+
+	jwks := ks.JWKS()
+	// HMAC 的 key 不能公开
+	require.Len(t, jwks.Keys, 2)
+	assert.Equal(t, "RSA", jwks.Keys[0].Kty)
+	assert.Equal(t, "RS256", jwks.Keys[0].Alg)
+	assert.Equal(t, "AQAB", jwks.Keys[0].E)
+	assert.Equal(t, "OKP", jwks.Keys[1].Kty)
+	assert.Equal(t, "Ed25519", jwks.Keys[1].Crv)
+	assert.Equal(t, "EdDSA", jwks.Keys[1].Alg)
+}
@@ -0,0 +1,137 @@
+package redislock
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"time"
+
+	uuid "github.com/lithammer/shortuuid/v4"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	//go:embed lua/lock.lua
+	luaLock string
+	//go:embed lua/refresh.lua
+	luaRefresh string
+	//go:embed lua/unlock.lua
+	luaUnlock string
+
+	// ErrFailedToPreemptLock 锁被别人拿着
+	ErrFailedToPreemptLock = errors.New("redislock: 抢锁失败")
+	// ErrLockNotHold 锁已经过期或者被别人拿走了
+	ErrLockNotHold = errors.New("redislock: 没有持有锁")
+)
+
+// Client 基于 Redis 的租约锁，锁有过期时间，持有者要定时续约
+type Client struct {
+	cmd redis.Cmdable
+}
+
+func NewClient(cmd redis.Cmdable) *Client {
+	return &Client{
+		cmd: cmd,
+	}
+}
+
+// TryLock 只尝试一次，锁被别人拿着的时候返回 ErrFailedToPreemptLock
+func (c *Client) TryLock(ctx context.Context, key string, expiration time.Duration) (*Lock, error) {
+	val := uuid.New()
+	res, err := c.cmd.Eval(ctx, luaLock, []string{key}, val, expiration.Milliseconds()).Result()
+	if err != nil {
+		return nil, err
+	}
+	if res != "OK" {
+		return nil, ErrFailedToPreemptLock
+	}
+	return newLock(c.cmd, key, val, expiration), nil
+}
+
+type Lock struct {
+	cmd        redis.Cmdable
+	key        string
+	value      string
+	expiration time.Duration
+	unlockCh   chan struct{}
+}
+
+func newLock(cmd redis.Cmdable, key string, value string, expiration time.Duration) *Lock {
+	return &Lock{
+		cmd:        cmd,
+		key:        key,
+		value:      value,
+		expiration: expiration,
+		unlockCh:   make(chan struct{}, 1),
+	}
+}
+
+func (l *Lock) Key() string {
+	return l.key
+}
+
+// Refresh 续约一次，锁已经不是自己的就返回 ErrLockNotHold
+func (l *Lock) Refresh(ctx context.Context) error {
+	res, err := l.cmd.Eval(ctx, luaRefresh, []string{l.key}, l.value, l.expiration.Milliseconds()).Int64()
+	if err != nil {
+		return err
+	}
+	if res != 1 {
+		return ErrLockNotHold
+	}
+	return nil
+}
+
+// maxRefreshRetries 续约连续超时这么多次就放弃，再试下去锁多半也过期了
+const maxRefreshRetries = 3
+
+// AutoRefresh 每隔 interval 续约一次，直到 Unlock、StopRefresh 或者续约失败。
+// 单次续约超时会重试，连续超时太多次、锁丢了或者 Redis 出错就返回错误，调用者应该停止手上的工作。
+func (l *Lock) AutoRefresh(interval time.Duration, timeout time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	// 超时了马上重试，不用等下一个 interval
+	retryCh := make(chan struct{}, 1)
+	retries := 0
+	for {
+		select {
+		case <-ticker.C:
+		case <-retryCh:
+		case <-l.unlockCh:
+			return nil
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		err := l.Refresh(ctx)
+		cancel()
+		if err == context.DeadlineExceeded && retries < maxRefreshRetries {
+			retries++
+			retryCh <- struct{}{}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		retries = 0
+	}
+}
+
+// StopRefresh 停止 AutoRefresh，但是不释放锁，锁到期之后自己过期
+func (l *Lock) StopRefresh() {
+	select {
+	case l.unlockCh <- struct{}{}:
+	default:
+	}
+}
+
+// Unlock 释放锁，同时停止 AutoRefresh
+func (l *Lock) Unlock(ctx context.Context) error {
+	l.StopRefresh()
+	res, err := l.cmd.Eval(ctx, luaUnlock, []string{l.key}, l.value).Int64()
+	if err != nil {
+		return err
+	}
+	if res != 1 {
+		return ErrLockNotHold
+	}
+	return nil
+}
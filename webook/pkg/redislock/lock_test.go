@@ -0,0 +1,123 @@
+package redislock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gitee.com/geekbang/basic-go/webook/internal/repository/cache/redismocks"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestClient_TryLock(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) redis.Cmdable
+		wantErr error
+	}{
+		{
+			name: "locked",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				cmd.EXPECT().Eval(gomock.Any(), luaLock, []string{"job"}, gomock.Any()).
+					Return(redis.NewCmdResult("OK", nil))
+				return cmd
+			},
+		},
+		{
+			name: "held by others",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				cmd.EXPECT().Eval(gomock.Any(), luaLock, []string{"job"}, gomock.Any()).
+					Return(redis.NewCmdResult("", nil))
+				return cmd
+			},
+			wantErr: ErrFailedToPreemptLock,
+		},
+		{
+			name: "redis error",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				cmd.EXPECT().Eval(gomock.Any(), luaLock, []string{"job"}, gomock.Any()).
+					Return(redis.NewCmdResult(nil, errors.New("redis error")))
+				return cmd
+			},
+			wantErr: errors.New("redis error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			c := NewClient(tc.mock(ctrl))
+			l, err := c.TryLock(context.Background(), "job", time.Minute)
+			assert.Equal(t, tc.wantErr, err)
+			if err == nil {
+				assert.Equal(t, "job", l.Key())
+			}
+		})
+	}
+}
+
+func TestLock_AutoRefresh(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cmd := redismocks.NewMockCmdable(ctrl)
+	// 第一次续约成功，第二次发现锁已经不是自己的了
+	cmd.EXPECT().Eval(gomock.Any(), luaRefresh, []string{"job"}, []any{"val", int64(60000)}).
+		Return(redis.NewCmdResult(int64(1), nil))
+	cmd.EXPECT().Eval(gomock.Any(), luaRefresh, []string{"job"}, []any{"val", int64(60000)}).
+		Return(redis.NewCmdResult(int64(0), nil))
+
+	l := newLock(cmd, "job", "val", time.Minute)
+	err := l.AutoRefresh(time.Millisecond*10, time.Second)
+	assert.Equal(t, ErrLockNotHold, err)
+}
+
+func TestLock_AutoRefreshTimeout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cmd := redismocks.NewMockCmdable(ctrl)
+	// 第一次加上重试一共 maxRefreshRetries+1 次，都超时就放弃
+	cmd.EXPECT().Eval(gomock.Any(), luaRefresh, []string{"job"}, []any{"val", int64(60000)}).
+		Return(redis.NewCmdResult(nil, context.DeadlineExceeded)).Times(maxRefreshRetries + 1)
+
+	l := newLock(cmd, "job", "val", time.Minute)
+	err := l.AutoRefresh(time.Millisecond*10, time.Second)
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestLock_Unlock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cmd := redismocks.NewMockCmdable(ctrl)
+	cmd.EXPECT().Eval(gomock.Any(), luaUnlock, []string{"job"}, []any{"val"}).
+		Return(redis.NewCmdResult(int64(1), nil))
+
+	l := newLock(cmd, "job", "val", time.Minute)
+	done := make(chan error, 1)
+	go func() {
+		done <- l.AutoRefresh(time.Minute, time.Second)
+	}()
+	assert.NoError(t, l.Unlock(context.Background()))
+	// Unlock 之后 AutoRefresh 正常退出
+	assert.NoError(t, <-done)
+}
+
+func TestLock_StopRefresh(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	// 不续约也不释放，不应该有任何 Redis 调用
+	cmd := redismocks.NewMockCmdable(ctrl)
+
+	l := newLock(cmd, "job", "val", time.Minute)
+	done := make(chan error, 1)
+	go func() {
+		done <- l.AutoRefresh(time.Minute, time.Second)
+	}()
+	l.StopRefresh()
+	assert.NoError(t, <-done)
+}
@@ -0,0 +1,10 @@
+-- 没有人持有锁，或者锁本来就是自己的（上一次加锁超时但是其实成功了）
+local val = redis.call("GET", KEYS[1])
+if val == false then
+    return redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+elseif val == ARGV[1] then
+    redis.call("PEXPIRE", KEYS[1], ARGV[2])
+    return "OK"
+else
+    return ""
+end
@@ -0,0 +1,6 @@
+-- 确认锁还是自己的才续约
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+    return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+else
+    return 0
+end
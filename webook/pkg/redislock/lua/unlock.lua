@@ -0,0 +1,6 @@
+-- 确认锁还是自己的才删除，避免删掉别人的锁
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+    return redis.call("DEL", KEYS[1])
+else
+    return 0
+end
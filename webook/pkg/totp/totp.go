@@ -0,0 +1,84 @@
+// Package totp 实现 RFC 6238 的基于时间的一次性密码，和 Google Authenticator 之类的应用兼容：
+// HMAC-SHA1，30 秒一个时间片，6 位数字
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	period = 30
+	digits = 6
+	// skew 前后各允许一个时间片，应付手机时间不准
+	skew = 1
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret 生成 160 位的随机密钥，base32 编码，可以直接让用户手动输入
+func GenerateSecret() (string, error) {
+	buf := make([]byte, 20)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return encoding.EncodeToString(buf), nil
+}
+
+// URI 生成 otpauth:// 链接，前端转成二维码给认证器扫
+func URI(issuer string, account string, secret string) string {
+	label := url.PathEscape(issuer + ":" + account)
+	q := url.Values{}
+	q.Set("secret", secret)
+	q.Set("issuer", issuer)
+	q.Set("algorithm", "SHA1")
+	q.Set("digits", fmt.Sprint(digits))
+	q.Set("period", fmt.Sprint(period))
+	return "otpauth://totp/" + label + "?" + q.Encode()
+}
+
+// Validate 校验 code，通过的时候返回匹配上的时间片。
+// 调用方要记住用过的时间片，同一个时间片的 code 不能用第二次
+func Validate(secret string, code string, t time.Time) (int64, bool) {
+	key, err := encoding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil || len(code) != digits {
+		return 0, false
+	}
+	step := t.Unix() / period
+	for i := -skew; i <= skew; i++ {
+		s := step + int64(i)
+		if subtle.ConstantTimeCompare([]byte(generate(key, s)), []byte(code)) == 1 {
+			return s, true
+		}
+	}
+	return 0, false
+}
+
+// Generate 当前时间的 code，测试和调试用
+func Generate(secret string, t time.Time) (string, error) {
+	key, err := encoding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil {
+		return "", err
+	}
+	return generate(key, t.Unix()/period), nil
+}
+
+func generate(key []byte, step int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	// RFC 4226 的动态截断
+	offset := sum[len(sum)-1] & 0x0f
+	bin := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", digits, bin%1000000)
+}
@@ -0,0 +1,60 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RFC 6238 附录 B 的 SHA1 测试向量，取后 6 位
+func TestGenerate_RFC6238(t *testing.T) {
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	testCases := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1111111111, want: "050471"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+	}
+	for _, tc := range testCases {
+		code, err := Generate(secret, time.Unix(tc.unix, 0))
+		require.NoError(t, err)
+		assert.Equal(t, tc.want, code)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+	now := time.Unix(1700000000, 0)
+	code, err := Generate(secret, now)
+	require.NoError(t, err)
+
+	step, ok := Validate(secret, code, now)
+	assert.True(t, ok)
+	assert.Equal(t, now.Unix()/period, step)
+
+	// 手机慢了一个时间片也能过
+	_, ok = Validate(secret, code, now.Add(period*time.Second))
+	assert.True(t, ok)
+	// 差太多就不行
+	_, ok = Validate(secret, code, now.Add(3*period*time.Second))
+	assert.False(t, ok)
+	// 认证器显示的小写密钥也能用
+	_, ok = Validate(strings.ToLower(secret), code, now)
+	assert.True(t, ok)
+	_, ok = Validate(secret, "12345", now)
+	assert.False(t, ok)
+}
+
+func TestURI(t *testing.T) {
+	uri := URI("webook", "123@qq.com", "JBSWY3DPEHPK3PXP")
+	assert.Equal(t, "otpauth://totp/webook:123@qq.com?algorithm=SHA1&digits=6&issuer=webook&period=30&secret=JBSWY3DPEHPK3PXP", uri)
+}
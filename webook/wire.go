@@ -8,11 +8,10 @@ import (
 	"gitee.com/geekbang/basic-go/webook/internal/service"
 	"gitee.com/geekbang/basic-go/webook/internal/web"
 	"gitee.com/geekbang/basic-go/webook/ioc"
-	"github.com/gin-gonic/gin"
 	"github.com/google/wire"
 )
 
-func InitWebServer() *gin.Engine {
+func InitApp() *App {
 
 	wire.Build(
 		//context
@@ -21,35 +20,93 @@ func InitWebServer() *gin.Engine {
 		//third party
 		ioc.InitDB,
 		ioc.InitRedis,
+		ioc.InitLogger,
 		//ioc.InitBigCache,
 
 		//dao
 		dao.NewUserDao,
+		dao.NewArticleDAO,
+		dao.NewInteractiveDAO,
+		dao.NewCollectionDAO,
+		dao.NewCronJobDAO,
+		dao.NewTwoFactorDAO,
+		dao.NewIdentityDAO,
+		dao.NewWechatTokenDAO,
+		dao.NewRoleDAO,
+		dao.NewGORMAsyncSmsDAO,
 
 		//cache
 		cache.NewRedisCodeCache, 
 		//cache.NewBigCacheCodeCache,
 		cache.NewRedisUserCache,
+		cache.NewRedisArticleCache,
+		cache.NewRedisInteractiveCache,
+		cache.NewRedisRankingCache,
+		cache.NewRedisSessionCache,
+		cache.NewRedisTwoFactorCache,
+		cache.NewRedisLoginAttemptCache,
+		cache.NewRankingLocalCache,
 		
 
 		//repository
 		repository.NewCodeRepository,
 		repository.NewUserRepository,
+		ioc.InitWechatTokenRepository,
+		repository.NewArticleRepository,
+		repository.NewInteractiveRepository,
+		repository.NewCollectionRepository,
+		repository.NewRankingRepository,
+		repository.NewSessionRepository,
+		ioc.InitTwoFactorRepository,
+		repository.NewRoleRepository,
+		repository.NewAsyncSMSRepository,
+		repository.NewLoginAttemptRepository,
+		repository.NewCronJobRepository,
 
 		//service
 		ioc.InitSMSService,
+		ioc.InitEmailService,
 		ioc.InitWechatService,
 		service.NewUserService,
-		service.NewCodeService,
+		service.NewWechatAccountService,
+		ioc.InitCodeService,
+		service.NewArticleService,
+		ioc.InitReadCntBuffer,
+		service.NewInteractiveService,
+		service.NewCollectionService,
+		service.NewBatchRankingService,
+		service.NewSessionService,
+		service.NewTwoFactorService,
+		service.NewRBACService,
+		service.NewAsyncSmsService,
+		service.NewLoginGuardService,
+		service.NewCronJobService,
+
+		//job
+		ioc.InitRankingJob,
+		ioc.InitWechatTokenJob,
+		ioc.InitJobs,
+		ioc.InitLocalFuncExecutor,
+		ioc.InitScheduler,
 		
 
 		//handler
 		web.NewUserHandler,
-		web.NewOAuth2WechatHandler,
+		ioc.InitOAuth2WechatHandler,
+		ioc.InitOAuth2Registry,
+		ioc.InitOAuth2Handler,
+		web.NewArticleHandler,
+		web.NewCollectionHandler,
+		web.NewSessionHandler,
+		web.NewTwoFactorHandler,
+		web.NewAdminHandler,
 
+		ioc.InitJWTHandler,
 		ioc.NewLimiter,
 		ioc.InitGinMiddlewares,
 		ioc.InitWebServer,
+
+		wire.Struct(new(App), "*"),
 	)
-	return gin.Default()
+	return new(App)
 }